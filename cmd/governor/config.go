@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deixis/governor/internal/config"
+)
+
+func configUsage() {
+	fmt.Fprintln(os.Stderr, `Usage: governor config <command> [flags]
+
+Commands:
+  validate    Check the .governor file and report its problems
+  show        Print the effective configuration, defaults included
+  schema      Print the JSON Schema of the .governor file`)
+}
+
+func configMain(args []string) error {
+	if len(args) < 1 {
+		configUsage()
+		os.Exit(2)
+	}
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "validate":
+		return configValidateMain(args)
+	case "show":
+		return configShowMain(args)
+	case "schema":
+		data, err := config.SchemaJSON()
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	case "help", "-h", "--help":
+		configUsage()
+		return nil
+	default:
+		fmt.Fprintf(os.Stderr, "governor config: unknown command %q\n", cmd)
+		configUsage()
+		os.Exit(2)
+	}
+	return nil
+}
+
+// --- validate ---
+
+func configValidateMain(args []string) error {
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "output the problems as JSON")
+	_ = fs.Parse(args)
+
+	workspace, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("determining workspace: %w", err)
+	}
+
+	var problems []config.Problem
+	loaded, err := config.Load(workspace)
+	var invalid *config.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		problems = invalid.Problems
+	case err != nil:
+		return fmt.Errorf("loading config: %w", err)
+	default:
+		for _, p := range engineFor(workspace, loaded, 0).ConfigProblems() {
+			problems = append(problems, loaded.Locate(p))
+		}
+	}
+	for i := range problems {
+		problems[i].File = relPath(workspace, problems[i].File)
+	}
+
+	if *jsonFlag {
+		if problems == nil {
+			problems = []config.Problem{}
+		}
+		if err := writeJSON(problems); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		if len(problems) == 0 {
+			if loaded.File == "" {
+				fmt.Println("No .governor file: using the defaults.")
+			} else {
+				fmt.Printf("%s: ok\n", relPath(workspace, loaded.File))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// --- show ---
+
+func configShowMain(args []string) error {
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	profile := profileFlag(fs)
+	_ = fs.Parse(args)
+
+	workspace, loaded, err := loadWorkspace()
+	if err != nil {
+		return err
+	}
+	if err := applyProfile(loaded, *profile); err != nil {
+		return err
+	}
+	cfg := loaded.Config.Effective()
+	if cfg.History.Dir == "" {
+		if dir, err := cfg.HistoryDir(loaded.RepoRoot); err == nil {
+			cfg.History.Dir = dir
+		}
+	}
+	// Settings come from the profile, else from the files, else from the
+	// defaults.
+	data, err := config.MarshalOrigins(cfg, func(key string) string {
+		file, line := "", 0
+		if *profile != "" {
+			file, line = loaded.Position("profiles." + *profile + "." + key)
+		}
+		if file == "" {
+			file, line = loaded.Position(key)
+		}
+		if file == "" {
+			return "default"
+		}
+		return fmt.Sprintf("%s:%d", relPath(workspace, file), line)
+	})
+	if err != nil {
+		return err
+	}
+
+	sources := "defaults (no .governor file)"
+	if loaded.File != "" {
+		sources = relPath(workspace, loaded.File)
+		for _, file := range loaded.Extended {
+			sources += ", " + relPath(workspace, file)
+		}
+		if *profile != "" {
+			sources += " with profile " + *profile
+		}
+		sources += " and defaults"
+	}
+	fmt.Printf("# Effective configuration: %s\n", sources)
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// relPath returns path relative to dir when it is inside dir.
+func relPath(dir, path string) string {
+	if path == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/workflow"
+)
+
+func doctorMain(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "output the diagnosis as JSON")
+	refreshFlag := fs.Bool("refresh", false, "look tools up again rather than using the cached results")
+	_ = fs.Parse(args)
+
+	workspace, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("determining workspace: %w", err)
+	}
+	// A configuration that does not load is one of the problems to report.
+	loaded, loadErr := config.Load(workspace)
+	if loadErr != nil {
+		loaded = &config.LoadResult{Config: &config.Config{}, RepoRoot: workspace}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	eng := engineFor(workspace, loaded, 0)
+	if *refreshFlag {
+		if err := eng.RefreshTools(); err != nil {
+			return err
+		}
+	}
+	d := eng.Doctor(ctx)
+	var invalid *config.ValidationError
+	switch {
+	case errors.As(loadErr, &invalid):
+		var problems []string
+		for _, p := range invalid.Problems {
+			problems = append(problems, p.String())
+		}
+		d.ConfigProblems = append(problems, d.ConfigProblems...)
+	case loadErr != nil:
+		d.ConfigProblems = append([]string{loadErr.Error()}, d.ConfigProblems...)
+	}
+
+	if *jsonFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(d); err != nil {
+			return err
+		}
+	} else {
+		fmt.Print(workflow.FormatDiagnosis(d))
+	}
+	if !d.OK() {
+		os.Exit(1)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/output"
+	"github.com/deixis/governor/internal/report"
+)
+
+// Output formats accepted by -format.
+const (
+	formatAuto     = "auto" // text, plus CI annotations when running in CI
+	formatText     = "text"
+	formatJSON     = "json"
+	formatSARIF    = "sarif"
+	formatJUnit    = "junit"
+	formatGitHub   = "github"
+	formatGitLab   = "gitlab"
+	formatMarkdown = "markdown"
+
+	// formatHTML is written to files with -html rather than to stdout.
+	formatHTML = "html"
+)
+
+var formats = []string{formatAuto, formatText, formatJSON, formatSARIF, formatJUnit, formatGitHub, formatGitLab, formatMarkdown}
+
+// formatUsage describes the -format flag.
+var formatUsage = "output format: " + strings.Join(formats, ", ")
+
+// resolveFormat validates a -format value. The older -json flag is
+// shorthand for -format json.
+func resolveFormat(format string, jsonFlag bool) (string, error) {
+	if jsonFlag {
+		if format != formatAuto && format != formatText && format != formatJSON {
+			return "", fmt.Errorf("-json conflicts with -format %s", format)
+		}
+		return formatJSON, nil
+	}
+	if !slices.Contains(formats, format) {
+		return "", fmt.Errorf("unknown format %q (want %s)", format, strings.Join(formats, ", "))
+	}
+	return format, nil
+}
+
+// isText reports whether format renders the human-readable summary.
+func isText(format string) bool {
+	return format == formatText || format == formatAuto
+}
+
+// writeRun renders rr to w in a machine-readable format.
+func writeRun(w io.Writer, format string, rr *report.RunResult, opts output.Options) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rr)
+	case formatSARIF:
+		return output.SARIF(w, rr, opts)
+	case formatJUnit:
+		return output.JUnit(w, rr)
+	case formatGitHub:
+		if err := output.GitHub(w, rr, opts); err != nil {
+			return err
+		}
+		return writeStepSummary(rr, opts)
+	case formatGitLab:
+		return output.GitLabCodeQuality(w, rr, opts)
+	case formatMarkdown:
+		return output.Markdown(w, rr, opts)
+	case formatHTML:
+		return output.HTML(w, rr, opts)
+	}
+	return fmt.Errorf("format %s cannot render a stored run", format)
+}
+
+// writeRunFile renders rr to the file at path.
+func writeRunFile(path, format string, rr *report.RunResult, opts output.Options) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeRun(f, format, rr, opts); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// outputOptions returns the rendering options for rr derived from cfg.
+func outputOptions(cfg *config.Config, rr *report.RunResult) output.Options {
+	baseDir := ciBaseDir(rr.Root)
+	repoURL := cfg.Output.RepoURL
+	if repoURL == "" {
+		repoURL = ciRepoURL(baseDir)
+	}
+	return output.Options{
+		ComplexityThreshold: cfg.ComplexityThreshold(),
+		BaseDir:             baseDir,
+		RepoURL:             repoURL,
+		MaxBytes:            cfg.MarkdownLimit(),
+	}
+}
+
+// loadBaseline loads the run that Markdown summaries compare against. ref
+// is either a file holding a run as written by -format json, such as an
+// artifact from the main branch, or the ID of a stored run.
+func loadBaseline(store report.Store, ref string, kind report.Kind) (*report.RunResult, error) {
+	var rr *report.RunResult
+	if data, err := os.ReadFile(ref); err == nil {
+		rr = new(report.RunResult)
+		if err := json.Unmarshal(data, rr); err != nil {
+			return nil, fmt.Errorf("reading baseline %s: %w", ref, err)
+		}
+	} else {
+		if rr, err = loadRun(store, ref); err != nil {
+			return nil, fmt.Errorf("loading baseline: %w", err)
+		}
+	}
+	if err := rr.Expect(kind); err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+	return rr, nil
+}
+
+// --- CI integration ---
+
+// detectCI returns the CI format matching the environment, or "" when not
+// running under a supported CI system.
+func detectCI() string {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return formatGitHub
+	case os.Getenv("GITLAB_CI") == "true":
+		return formatGitLab
+	}
+	return ""
+}
+
+// ciBaseDir returns the path of root relative to the CI checkout, so that
+// annotations resolve when the module is not at the repository root.
+func ciBaseDir(root string) string {
+	checkout := os.Getenv("GITHUB_WORKSPACE")
+	if checkout == "" {
+		checkout = os.Getenv("CI_PROJECT_DIR")
+	}
+	if checkout == "" || root == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(checkout, root)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// ciRepoURL returns the URL of the module's files in the CI system's web
+// interface, or "" when not running in a supported CI system.
+func ciRepoURL(baseDir string) string {
+	var u string
+	switch detectCI() {
+	case formatGitHub:
+		server, repo := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY")
+		if server == "" || repo == "" {
+			return ""
+		}
+		u = server + "/" + repo + "/blob/{commit}"
+	case formatGitLab:
+		project := os.Getenv("CI_PROJECT_URL")
+		if project == "" {
+			return ""
+		}
+		u = project + "/-/blob/{commit}"
+	default:
+		return ""
+	}
+	if baseDir != "" {
+		u += "/" + baseDir
+	}
+	return u
+}
+
+// publishCI complements the text output with the native reporting of the
+// CI system governor runs under. On GitHub Actions, findings are written
+// as annotations and the run is summarised in the job summary. On GitLab,
+// findings are written to a Code Quality report in the working directory,
+// to be collected with artifacts:reports:codequality. Failing to publish
+// does not fail the run.
+func publishCI(rr *report.RunResult, opts output.Options) {
+	switch detectCI() {
+	case formatGitHub:
+		if err := writeRun(os.Stdout, formatGitHub, rr, opts); err != nil {
+			log.Printf("warning: writing GitHub annotations: %v", err)
+		}
+	case formatGitLab:
+		path := codeQualityFile(rr.Kind)
+		if err := writeRunFile(path, formatGitLab, rr, opts); err != nil {
+			log.Printf("warning: writing Code Quality report: %v", err)
+		}
+	}
+}
+
+// codeQualityFile returns the name of the GitLab Code Quality report for
+// runs of kind, so that check and audit reports do not overwrite each other.
+func codeQualityFile(kind report.Kind) string {
+	return "gl-code-quality-" + string(kind) + ".json"
+}
+
+// writeStepSummary appends a Markdown summary of rr to the GitHub Actions
+// job summary, when one is available.
+func writeStepSummary(rr *report.RunResult, opts output.Options) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening step summary: %w", err)
+	}
+	if err := output.Markdown(f, rr, opts); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing step summary: %w", err)
+	}
+	return f.Close()
+}
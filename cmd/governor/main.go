@@ -3,13 +3,13 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"time"
 
 	"github.com/deixis/governor"
@@ -41,6 +41,14 @@ func main() {
 		err = checkMain(args)
 	case "audit":
 		err = auditMain(args)
+	case "runs":
+		err = runsMain(args)
+	case "trend":
+		err = trendMain(args)
+	case "doctor":
+		err = doctorMain(args)
+	case "config":
+		err = configMain(args)
 	case "version":
 		fmt.Println(governor.Version)
 	case "help", "-h", "--help":
@@ -62,6 +70,10 @@ func usage() {
 Commands:
   check       Run the check pipeline (fix, test, lint, staticcheck)
   audit       Run audit checks (coverage, complexity, deadcode, dupl, vulncheck)
+  runs        List, show, inspect and prune stored runs
+  trend       Show code health metrics over the stored run history
+  doctor      Check that the tools the configured steps need are installed
+  config      Validate or show the configuration, or print its JSON Schema
   mcp         Start the MCP server
   version     Print the version
   help        Show this help
@@ -75,6 +87,7 @@ func mcpMain(args []string) error {
 	fs := flag.NewFlagSet("mcp", flag.ExitOnError)
 	instructions := fs.Bool("instructions", false, "print model instructions and exit")
 	httpAddr := fs.String("http", "", "start HTTP server on address (e.g. :9090)")
+	profile := profileFlag(fs)
 	_ = fs.Parse(args)
 
 	if *instructions {
@@ -85,10 +98,10 @@ func mcpMain(args []string) error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
-	return serve(ctx, *httpAddr)
+	return serve(ctx, *httpAddr, *profile)
 }
 
-func serve(ctx context.Context, httpAddr string) error {
+func serve(ctx context.Context, httpAddr, profile string) error {
 	workspace, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("determining workspace: %w", err)
@@ -99,17 +112,34 @@ func serve(ctx context.Context, httpAddr string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 	cfg := loaded.Config
+	// The runner has the command settings of the server's profile; tool
+	// calls naming another profile run with that profile's settings.
+	runCfg, err := cfg.WithProfile(profile)
+	if err != nil {
+		return err
+	}
 
-	disk := report.NewDiskStore()
+	disk, err := openStore(cfg, loaded.RepoRoot)
+	if err != nil {
+		return err
+	}
 	store := report.NewLRUStore(5, disk)
 
 	r := &runner.Runner{
-		Workspace: workspace,
-		Timeout:   cfg.Timeout(),
-		MaxOutput: cfg.MaxOutputBytes(),
+		Workspace:   workspace,
+		Timeout:     runCfg.Timeout(),
+		MaxOutput:   runCfg.MaxOutputBytes(),
+		TailOutput:  runCfg.TailOutputBytes(),
+		GracePeriod: runCfg.GracePeriod(),
+		Queue:       workspaceQueue(runCfg, loaded.RepoRoot),
 	}
 
-	var opts []govmcp.ServerOption
+	opts := []govmcp.ServerOption{
+		govmcp.WithLogDir(report.LogDir(disk.Dir())),
+		govmcp.WithToolCache(workflow.NewToolCache(filepath.Join(disk.Dir(), workflow.ToolCacheFile))),
+		govmcp.WithHistory(openStore),
+		govmcp.WithProfile(profile),
+	}
 	proxy, stopProxy, proxyErr := govmcp.StartGoplsProxy(ctx, workspace)
 	if proxyErr != nil {
 		log.Printf("gopls proxy failed to start: %v", proxyErr)
@@ -155,37 +185,67 @@ func serveHTTP(ctx context.Context, server *mcpsdk.Server, addr string) error {
 func checkMain(args []string) error {
 	fs := flag.NewFlagSet("check", flag.ExitOnError)
 	fixFlag := fs.Bool("fix", false, "run auto-fix phase before checks")
-	jsonFlag := fs.Bool("json", false, "output results as JSON")
+	jsonFlag := fs.Bool("json", false, "output results as JSON (same as -format json)")
+	formatFlag := fs.String("format", formatAuto, formatUsage)
+	junitFile := fs.String("junit-file", "", "also write test results as JUnit XML to this file")
+	baselineFlag := fs.String("baseline", "", "run ID or JSON run file to compare against in Markdown output")
 	verboseFlag := fs.Bool("v", false, "verbose output")
+	progressFlag := fs.Bool("progress", true, "show progress on stderr while running (text output only)")
 	timeoutFlag := fs.Duration("timeout", 0, "override configured timeout (e.g. 5m)")
+	profile := profileFlag(fs)
 	_ = fs.Parse(args)
 
 	packages := fs.Args()
+	format, err := resolveFormat(*formatFlag, *jsonFlag)
+	if err != nil {
+		return err
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
-	eng, err := newEngine(*timeoutFlag)
+	eng, err := newEngine(*timeoutFlag, *profile)
+	if err != nil {
+		return err
+	}
+	baseline, err := engineBaseline(eng, *baselineFlag, report.Check)
 	if err != nil {
 		return err
 	}
 
+	var display *progressDisplay
+	if isText(format) && *progressFlag {
+		display = newProgressDisplay(os.Stderr)
+		ctx = workflow.WithObserver(ctx, display.observe)
+	}
 	result, err := eng.Check(ctx, packages, *fixFlag)
+	display.close()
 	if err != nil {
 		return fmt.Errorf("check: %w", err)
 	}
+	saveRun(eng, result.RunResult)
+
+	opts := outputOptions(eng.Config, result.RunResult)
+	opts.Baseline = baseline
+
+	if *junitFile != "" {
+		if err := writeRunFile(*junitFile, formatJUnit, result.RunResult, opts); err != nil {
+			return err
+		}
+	}
 
 	failed := result.FailedIdx >= 0 || result.FailedIdx == -2
 
-	if *jsonFlag {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		if err := enc.Encode(result.RunResult); err != nil {
+	if !isText(format) {
+		if err := writeRun(os.Stdout, format, result.RunResult, opts); err != nil {
 			return err
 		}
 	} else {
 		fmt.Print(formatCheckCLI(result, *verboseFlag))
 	}
+	if format == formatAuto {
+		publishCI(result.RunResult, opts)
+	}
 
 	if failed {
 		os.Exit(1)
@@ -224,19 +284,10 @@ func formatCheckCLI(result *workflow.CheckResult, verbose bool) string {
 		w("Auto-fixed: %d issues\n\n", rr.AutoFixes)
 	}
 
-	for _, s := range result.Steps {
-		switch s.Status {
-		case "pass":
-			w("  %-15s ok\n", s.Name)
-		case "fail":
-			w("  %-15s FAIL\n", s.Name)
-		case "unavailable":
-			w("  %-15s unavailable\n", s.Name)
-		case "skipped":
-			w("  %-15s -\n", s.Name)
-		}
+	w("%s\n", timingTable(rr))
+	if u := workflow.FormatUsage(rr); verbose && u != "" {
+		w("%s\n", u)
 	}
-	w("\n")
 
 	if !allPassed {
 		failed := result.Steps[result.FailedIdx]
@@ -249,6 +300,10 @@ func formatCheckCLI(result *workflow.CheckResult, verbose bool) string {
 			w("\n")
 		}
 
+		if failed.Detail != "" {
+			w("%s: %s\n\n", failed.Name, failed.Detail)
+		}
+
 		if verbose && failed.Output != "" {
 			w("%s\n", failed.Output)
 		}
@@ -261,33 +316,63 @@ func formatCheckCLI(result *workflow.CheckResult, verbose bool) string {
 
 func auditMain(args []string) error {
 	fs := flag.NewFlagSet("audit", flag.ExitOnError)
-	jsonFlag := fs.Bool("json", false, "output results as JSON")
+	jsonFlag := fs.Bool("json", false, "output results as JSON (same as -format json)")
+	formatFlag := fs.String("format", formatAuto, formatUsage)
+	htmlFile := fs.String("html", "", "also write an HTML report to this file")
+	baselineFlag := fs.String("baseline", "", "run ID or JSON run file to compare against in Markdown output")
 	verboseFlag := fs.Bool("v", false, "verbose output")
+	progressFlag := fs.Bool("progress", true, "show progress on stderr while running (text output only)")
 	timeoutFlag := fs.Duration("timeout", 0, "override configured timeout (e.g. 5m)")
+	profile := profileFlag(fs)
 	_ = fs.Parse(args)
 
 	packages := fs.Args()
+	format, err := resolveFormat(*formatFlag, *jsonFlag)
+	if err != nil {
+		return err
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
-	eng, err := newEngine(*timeoutFlag)
+	eng, err := newEngine(*timeoutFlag, *profile)
+	if err != nil {
+		return err
+	}
+	baseline, err := engineBaseline(eng, *baselineFlag, report.Audit)
 	if err != nil {
 		return err
 	}
 
+	var display *progressDisplay
+	if isText(format) && *progressFlag {
+		display = newProgressDisplay(os.Stderr)
+		ctx = workflow.WithObserver(ctx, display.observe)
+	}
 	result, err := eng.Audit(ctx, packages)
+	display.close()
 	if err != nil {
 		return fmt.Errorf("audit: %w", err)
 	}
+	saveRun(eng, result.RunResult)
+
+	opts := outputOptions(eng.Config, result.RunResult)
+	opts.Baseline = baseline
+
+	if *htmlFile != "" {
+		if err := writeRunFile(*htmlFile, formatHTML, result.RunResult, opts); err != nil {
+			return err
+		}
+	}
 
-	if *jsonFlag {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(result.RunResult)
+	if !isText(format) {
+		return writeRun(os.Stdout, format, result.RunResult, opts)
 	}
 
 	fmt.Print(formatAuditCLI(result, *verboseFlag))
+	if format == formatAuto {
+		publishCI(result.RunResult, opts)
+	}
 	return nil
 }
 
@@ -313,28 +398,68 @@ func formatAuditCLI(result *workflow.AuditResult, verbose bool) string {
 			w("%s\n", r.Output)
 		case "unavailable":
 			w("%s: unavailable (%s)\n\n", r.Name, r.Detail)
-		case "error":
-			w("%s: error (%s)\n\n", r.Name, r.Detail)
+		case "error", "timeout", "cancelled":
+			w("%s: %s (%s)\n\n", r.Name, r.Status, r.Detail)
 		case "skipped":
 			w("%s: skipped\n\n", r.Name)
 		}
 	}
+	w("%s", timingTable(result.RunResult))
+	if u := workflow.FormatUsage(result.RunResult); verbose && u != "" {
+		w("\n%s", u)
+	}
 
 	return string(b)
 }
 
 // --- shared ---
 
-func newEngine(timeoutOverride time.Duration) (*workflow.Engine, error) {
+// loadWorkspace loads the configuration for the current directory.
+func loadWorkspace() (string, *config.LoadResult, error) {
 	workspace, err := os.Getwd()
 	if err != nil {
-		return nil, fmt.Errorf("determining workspace: %w", err)
+		return "", nil, fmt.Errorf("determining workspace: %w", err)
 	}
 
 	loaded, err := config.Load(workspace)
 	if err != nil {
-		return nil, fmt.Errorf("loading config: %w", err)
+		return "", nil, fmt.Errorf("loading config: %w", err)
 	}
+	return workspace, loaded, nil
+}
+
+// profileFlag defines the -profile flag of a command, which defaults to
+// $GOVERNOR_PROFILE.
+func profileFlag(fs *flag.FlagSet) *string {
+	return fs.String("profile", os.Getenv(config.ProfileEnv), "apply this profile of the configuration (default $"+config.ProfileEnv+")")
+}
+
+// applyProfile replaces the configuration of loaded with its profile name
+// applied.
+func applyProfile(loaded *config.LoadResult, name string) error {
+	cfg, err := loaded.Config.WithProfile(name)
+	if err != nil {
+		return err
+	}
+	loaded.Config = cfg
+	return nil
+}
+
+func newEngine(timeoutOverride time.Duration, profile string) (*workflow.Engine, error) {
+	workspace, loaded, err := loadWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	if err := applyProfile(loaded, profile); err != nil {
+		return nil, err
+	}
+	eng := engineFor(workspace, loaded, timeoutOverride)
+	eng.Profile = profile
+	return eng, nil
+}
+
+// engineFor returns an engine running commands in the repository of loaded.
+func engineFor(workspace string, loaded *config.LoadResult, timeoutOverride time.Duration) *workflow.Engine {
 	cfg := loaded.Config
 
 	timeout := cfg.Timeout()
@@ -343,15 +468,91 @@ func newEngine(timeoutOverride time.Duration) (*workflow.Engine, error) {
 	}
 
 	r := &runner.Runner{
-		Workspace: loaded.RepoRoot,
-		Timeout:   timeout,
-		MaxOutput: cfg.MaxOutputBytes(),
+		Workspace:   loaded.RepoRoot,
+		Timeout:     timeout,
+		MaxOutput:   cfg.MaxOutputBytes(),
+		TailOutput:  cfg.TailOutputBytes(),
+		GracePeriod: cfg.GracePeriod(),
+		Queue:       workspaceQueue(cfg, loaded.RepoRoot),
 	}
 
-	return &workflow.Engine{
+	eng := &workflow.Engine{
 		Config:    cfg,
 		Runner:    r,
 		Workspace: workspace,
 		RepoRoot:  loaded.RepoRoot,
-	}, nil
+	}
+	eng.Tools = workflow.NewToolCache("")
+	if dir, err := cfg.HistoryDir(loaded.RepoRoot); err == nil {
+		eng.LogDir = report.LogDir(dir)
+		eng.Tools = workflow.NewToolCache(filepath.Join(dir, workflow.ToolCacheFile))
+	}
+	return eng
+}
+
+// workspaceQueue returns the queue of the commands run in the repository
+// at repoRoot, shared with other governor processes through its lock file.
+// Without a history directory, commands are only queued within the process.
+func workspaceQueue(cfg *config.Config, repoRoot string) *runner.Queue {
+	lock, _ := cfg.WorkspaceLock(repoRoot)
+	return runner.NewQueue(cfg.Concurrency(), lock)
+}
+
+// openStore opens the persistent run history for the repository at repoRoot.
+func openStore(cfg *config.Config, repoRoot string) (*report.DiskStore, error) {
+	dir, err := cfg.HistoryDir(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("resolving run history directory: %w", err)
+	}
+	return report.NewDiskStore(dir, retention(cfg)), nil
+}
+
+// retention returns the configured run history retention policy.
+func retention(cfg *config.Config) report.Retention {
+	return report.Retention{
+		MaxRuns: cfg.HistoryMaxRuns(),
+		MaxAge:  cfg.HistoryMaxAge(),
+		MaxSize: cfg.HistoryMaxSize(),
+	}
+}
+
+// parseInterspersed parses args with fs, allowing flags to appear after
+// positional arguments (e.g. "show <id> -json"). It returns the positional
+// arguments in order.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		_ = fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// engineBaseline loads the baseline named by ref for a run of kind made by
+// eng. It returns nil if ref is empty.
+func engineBaseline(eng *workflow.Engine, ref string, kind report.Kind) (*report.RunResult, error) {
+	if ref == "" {
+		return nil, nil
+	}
+	store, err := openStore(eng.Config, eng.RepoRoot)
+	if err != nil {
+		return nil, err
+	}
+	return loadBaseline(store, ref, kind)
+}
+
+// saveRun records a CLI run in the history so it can be inspected later,
+// from the CLI or over MCP. Failing to save does not fail the run.
+func saveRun(eng *workflow.Engine, rr *report.RunResult) {
+	store, err := openStore(eng.Config, eng.RepoRoot)
+	if err == nil {
+		err = store.Save(rr)
+	}
+	if err != nil {
+		log.Printf("warning: saving run %s: %v", rr.ID, err)
+	}
 }
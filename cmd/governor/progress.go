@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deixis/governor/internal/report"
+	"github.com/deixis/governor/internal/workflow"
+)
+
+// progressDisplay shows the progress of a run as it happens. On a terminal,
+// the running step is shown on a status line that is redrawn in place with
+// its elapsed time; elsewhere, such as in CI logs, each event is written on
+// its own line. Test packages are listed as they complete in both modes.
+type progressDisplay struct {
+	w    io.Writer
+	live bool // redraw the status line in place
+
+	mu           sync.Mutex
+	ev           workflow.Event // latest event of the running step
+	running      bool
+	waiting      string // what the running step waits for, until its next event
+	stepStart    time.Time
+	stepPackages int // packages done when the step started
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newProgressDisplay returns a display writing to f. Call close once the
+// run has finished.
+func newProgressDisplay(f *os.File) *progressDisplay {
+	d := &progressDisplay{w: f, live: isTerminal(f)}
+	if d.live {
+		d.stop = make(chan struct{})
+		d.done = make(chan struct{})
+		go d.tick()
+	}
+	return d
+}
+
+// isTerminal reports whether f is a terminal that understands cursor
+// control.
+func isTerminal(f *os.File) bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// observe is the workflow.Observer of the display.
+func (d *progressDisplay) observe(ev workflow.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.waiting = ""
+	switch ev.Kind {
+	case workflow.EventWaiting:
+		d.waiting = ev.Waiting
+		if ev.Step == "" {
+			// Not in a step yet: show the wait on the status line.
+			d.ev = ev
+			d.running = ev.Waiting != ""
+			d.stepStart = time.Now().Add(-ev.Elapsed)
+		}
+		if !d.live && ev.Waiting != "" {
+			fmt.Fprintf(d.w, "  %-15s waiting (%s)\n", stepOrRun(ev.Step), ev.Waiting)
+		}
+	case workflow.EventStepStarted:
+		d.ev = ev
+		d.running = true
+		d.stepStart = time.Now().Add(-ev.StepElapsed)
+		d.stepPackages = ev.PackagesDone
+		if !d.live {
+			fmt.Fprintf(d.w, "  %-15s running\n", ev.Step)
+		}
+	case workflow.EventPackageDone:
+		d.ev = ev
+		d.clear()
+		fmt.Fprintf(d.w, "    %-4s %s\n", packageLabel(ev.Status), ev.Package)
+	case workflow.EventStepFinished:
+		d.running = false
+		d.clear()
+		fmt.Fprintf(d.w, "  %-15s %-11s %8s\n", ev.Step, stepLabel(ev.Status), formatDuration(ev.StepElapsed))
+	}
+	d.redraw()
+}
+
+// close stops redrawing and removes the status line. A nil display is
+// ignored.
+func (d *progressDisplay) close() {
+	if d == nil || !d.live {
+		return
+	}
+	close(d.stop)
+	<-d.done
+	d.mu.Lock()
+	d.running = false
+	d.clear()
+	d.mu.Unlock()
+}
+
+// tick redraws the status line so that its elapsed time stays current.
+func (d *progressDisplay) tick() {
+	defer close(d.done)
+	t := time.NewTicker(100 * time.Millisecond)
+	defer t.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-t.C:
+			d.mu.Lock()
+			d.redraw()
+			d.mu.Unlock()
+		}
+	}
+}
+
+// redraw writes the status line of the running step. d.mu must be held.
+func (d *progressDisplay) redraw() {
+	if !d.live || !d.running {
+		return
+	}
+	ev := d.ev
+	var b strings.Builder
+	state := "running"
+	if d.waiting != "" {
+		state = "waiting (" + d.waiting + ")"
+	}
+	fmt.Fprintf(&b, "\r\x1b[2K  %-15s %s %s", stepOrRun(ev.Step), state, formatDuration(time.Since(d.stepStart)))
+	if ev.PackagesDone > d.stepPackages {
+		fmt.Fprintf(&b, "  %d", ev.PackagesDone)
+		if ev.PackagesTotal > 0 {
+			fmt.Fprintf(&b, "/%d", ev.PackagesTotal)
+		}
+		fmt.Fprintf(&b, " packages, %d tests passed, %d failed", ev.TestsPassed, ev.TestsFailed)
+	}
+	_, _ = io.WriteString(d.w, b.String())
+}
+
+// clear erases the status line. d.mu must be held.
+func (d *progressDisplay) clear() {
+	if d.live {
+		_, _ = io.WriteString(d.w, "\r\x1b[2K")
+	}
+}
+
+// stepOrRun names the step of an event, or the run while no step has
+// started.
+func stepOrRun(step string) string {
+	if step == "" {
+		return "run"
+	}
+	return step
+}
+
+// stepLabel returns how the CLI shows a step status.
+func stepLabel(status string) string {
+	switch status {
+	case "pass", "done":
+		return "ok"
+	case "fail":
+		return "FAIL"
+	case "error":
+		return "ERROR"
+	case "skipped":
+		return "-"
+	case "timeout":
+		return "TIMEOUT"
+	case "cancelled":
+		return "CANCELLED"
+	}
+	return status
+}
+
+// packageLabel returns how the CLI shows the test result of a package, as
+// go test does.
+func packageLabel(status string) string {
+	switch status {
+	case "pass":
+		return "ok"
+	case "skip":
+		return "?"
+	}
+	return "FAIL"
+}
+
+// formatDuration formats d for display: to the millisecond below a second,
+// to a tenth of a second above.
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// timingTable formats the status and time of each step of rr, followed by
+// the total time of the run.
+func timingTable(rr *report.RunResult) string {
+	var b strings.Builder
+	for _, s := range rr.Steps {
+		elapsed := "-"
+		if s.Elapsed > 0 {
+			elapsed = formatDuration(time.Duration(s.Elapsed * float64(time.Second)))
+		}
+		fmt.Fprintf(&b, "  %-15s %-11s %8s\n", s.Name, stepLabel(s.Status), elapsed)
+	}
+	fmt.Fprintf(&b, "  %-15s %-11s %8s\n", "total", "", formatDuration(rr.Duration()))
+	return b.String()
+}
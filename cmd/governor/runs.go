@@ -0,0 +1,357 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/report"
+	"github.com/deixis/governor/internal/workflow"
+)
+
+func runsUsage() {
+	fmt.Fprintln(os.Stderr, `Usage: governor runs <command> [flags] [args]
+
+Commands:
+  list                  List stored runs
+  show <id>             Show a stored run
+  inspect <id> <symbol> Show diagnostics for a package or symbol in a run
+  diff <old> <new>      Compare the findings and metrics of two runs
+  logs <id> <step>      Show the complete output of a step of a run
+  prune                 Delete runs outside the retention policy
+
+Run IDs may be abbreviated to any unique prefix.`)
+}
+
+func runsMain(args []string) error {
+	if len(args) < 1 {
+		runsUsage()
+		os.Exit(2)
+	}
+
+	_, loaded, err := loadWorkspace()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(loaded.Config, loaded.RepoRoot)
+	if err != nil {
+		return err
+	}
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "list":
+		return runsListMain(store, args)
+	case "show":
+		return runsShowMain(store, loaded.Config, args)
+	case "inspect":
+		return runsInspectMain(store, args)
+	case "diff":
+		return runsDiffMain(store, loaded.Config, args)
+	case "logs":
+		return runsLogsMain(store, args)
+	case "prune":
+		return runsPruneMain(store, loaded.Config, args)
+	case "help", "-h", "--help":
+		runsUsage()
+		return nil
+	default:
+		fmt.Fprintf(os.Stderr, "governor runs: unknown command %q\n", cmd)
+		runsUsage()
+		os.Exit(2)
+	}
+	return nil
+}
+
+// --- list ---
+
+func runsListMain(store report.Store, args []string) error {
+	fs := flag.NewFlagSet("runs list", flag.ExitOnError)
+	kindFlag := fs.String("kind", "", "only list runs of this kind (check or audit)")
+	sinceFlag := fs.String("since", "", "only list runs started after a duration ago (e.g. 24h) or a date (2006-01-02)")
+	jsonFlag := fs.Bool("json", false, "output entries as JSON")
+	_ = fs.Parse(args)
+
+	filter, err := parseFilter(*kindFlag, *sinceFlag)
+	if err != nil {
+		return err
+	}
+	entries, err := store.List(filter)
+	if err != nil {
+		return err
+	}
+
+	if *jsonFlag {
+		return writeJSON(entries)
+	}
+	if len(entries) == 0 {
+		fmt.Println("No runs stored.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tKIND\tSTARTED\tSTATUS\tCOMMIT\tPACKAGES")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.ID, e.Kind, e.Started.Local().Format("2006-01-02 15:04:05"),
+			orDash(e.Status), orDash(shortCommit(e.Commit)), strings.Join(e.Packages, " "))
+	}
+	return tw.Flush()
+}
+
+// parseFilter builds a report.Filter from the -kind and -since flag values.
+func parseFilter(kind, since string) (report.Filter, error) {
+	var f report.Filter
+	switch report.Kind(kind) {
+	case "", report.Check, report.Audit:
+		f.Kind = report.Kind(kind)
+	default:
+		return f, fmt.Errorf("invalid -kind %q: want check or audit", kind)
+	}
+	if since != "" {
+		t, err := parseSince(since, time.Now())
+		if err != nil {
+			return f, err
+		}
+		f.Since = t
+	}
+	return f, nil
+}
+
+// parseSince accepts a duration relative to now (e.g. "24h") or an
+// absolute date or timestamp.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: want a duration (e.g. 24h) or a date (2006-01-02)", s)
+}
+
+// --- show ---
+
+func runsShowMain(store report.Store, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("runs show", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "output the full RunResult as JSON (same as -format json)")
+	formatFlag := fs.String("format", formatText, formatUsage)
+	htmlFile := fs.String("html", "", "write an HTML report to this file instead")
+	baselineFlag := fs.String("baseline", "", "run ID or JSON run file to compare against in Markdown output")
+	pos := parseInterspersed(fs, args)
+	if len(pos) != 1 {
+		return errors.New("usage: governor runs show <id> [-format name | -html file]")
+	}
+	format, err := resolveFormat(*formatFlag, *jsonFlag)
+	if err != nil {
+		return err
+	}
+
+	rr, err := loadRun(store, pos[0])
+	if err != nil {
+		return err
+	}
+
+	opts := outputOptions(cfg, rr)
+	if *baselineFlag != "" {
+		if opts.Baseline, err = loadBaseline(store, *baselineFlag, rr.Kind); err != nil {
+			return err
+		}
+	}
+
+	if *htmlFile != "" {
+		return writeRunFile(*htmlFile, formatHTML, rr, opts)
+	}
+	if !isText(format) {
+		return writeRun(os.Stdout, format, rr, opts)
+	}
+	fmt.Print(workflow.FormatRun(rr))
+	return nil
+}
+
+// --- inspect ---
+
+func runsInspectMain(store report.Store, args []string) error {
+	fs := flag.NewFlagSet("runs inspect", flag.ExitOnError)
+	pos := parseInterspersed(fs, args)
+	if len(pos) != 2 {
+		return errors.New("usage: governor runs inspect <id> <symbol>")
+	}
+
+	rr, err := loadRun(store, pos[0])
+	if err != nil {
+		return err
+	}
+	symbol := pos[1]
+
+	diagnostics := report.BySymbol(rr, symbol)
+	if len(diagnostics) == 0 {
+		fmt.Printf("No diagnostics found for %s in run %s (%s).\n", symbol, rr.ID, rr.Kind)
+		return nil
+	}
+	fmt.Print(workflow.FormatInspect(rr, symbol, diagnostics))
+	return nil
+}
+
+// --- diff ---
+
+func runsDiffMain(store report.Store, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("runs diff", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "output the diff as JSON")
+	pos := parseInterspersed(fs, args)
+	if len(pos) != 2 {
+		return errors.New("usage: governor runs diff <old> <new> [-json]")
+	}
+
+	oldRun, err := loadRun(store, pos[0])
+	if err != nil {
+		return err
+	}
+	newRun, err := loadRun(store, pos[1])
+	if err != nil {
+		return err
+	}
+
+	d, err := report.Compare(oldRun, newRun, cfg.ComplexityThreshold())
+	if err != nil {
+		return err
+	}
+
+	if *jsonFlag {
+		return writeJSON(d)
+	}
+	fmt.Print(workflow.FormatDiff(d))
+	return nil
+}
+
+// --- logs ---
+
+func runsLogsMain(store report.Store, args []string) error {
+	fs := flag.NewFlagSet("runs logs", flag.ExitOnError)
+	fromFlag := fs.Int("from", 1, "first line to show; negative counts from the end (-50 shows the last 50 lines)")
+	linesFlag := fs.Int("lines", workflow.DefaultLogLines, "number of lines to show; 0 shows the rest of the log")
+	pos := parseInterspersed(fs, args)
+	if len(pos) != 2 {
+		return errors.New("usage: governor runs logs <id> <step> [-from N] [-lines N]")
+	}
+
+	rr, err := loadRun(store, pos[0])
+	if err != nil {
+		return err
+	}
+	step := pos[1]
+	path, err := rr.StepLog(step)
+	if err != nil {
+		return err
+	}
+
+	lines := *linesFlag
+	if lines <= 0 {
+		lines = math.MaxInt
+	}
+	page, err := report.ReadLog(path, *fromFlag, lines)
+	if err != nil {
+		return err
+	}
+	fmt.Print(workflow.FormatLogPage(step, page))
+	if page.To() < page.Total {
+		fmt.Printf("\nMore: governor runs logs %s %s -from %d\n", rr.ID, step, page.To()+1)
+	}
+	return nil
+}
+
+// --- prune ---
+
+func runsPruneMain(store report.Store, cfg *config.Config, args []string) error {
+	policy := retention(cfg)
+
+	fs := flag.NewFlagSet("runs prune", flag.ExitOnError)
+	fs.IntVar(&policy.MaxRuns, "keep", policy.MaxRuns, "keep at most this many runs")
+	fs.DurationVar(&policy.MaxAge, "older-than", policy.MaxAge, "delete runs older than this (e.g. 168h)")
+	fs.Int64Var(&policy.MaxSize, "max-size", policy.MaxSize, "keep at most this many bytes of runs")
+	allFlag := fs.Bool("all", false, "delete all stored runs")
+	dryRunFlag := fs.Bool("n", false, "dry run: list the runs that would be deleted")
+	_ = fs.Parse(args)
+
+	entries, err := store.List(report.Filter{})
+	if err != nil {
+		return err
+	}
+
+	expired := entries
+	if !*allFlag {
+		expired = policy.Expired(entries, time.Now())
+	}
+
+	for _, e := range expired {
+		if *dryRunFlag {
+			fmt.Printf("would delete %s (%s, %s)\n", e.ID, e.Kind, e.Started.Local().Format("2006-01-02 15:04:05"))
+			continue
+		}
+		if err := store.Delete(e.ID); err != nil {
+			return err
+		}
+	}
+
+	if !*dryRunFlag {
+		fmt.Printf("Pruned %d of %d runs.\n", len(expired), len(entries))
+	}
+	return nil
+}
+
+// --- shared ---
+
+// loadRun loads a run by ID or unique ID prefix.
+func loadRun(store report.Store, id string) (*report.RunResult, error) {
+	entries, err := store.List(report.Filter{})
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, e := range entries {
+		if e.ID == id {
+			matches = []string{id}
+			break
+		}
+		if strings.HasPrefix(e.ID, id) {
+			matches = append(matches, e.ID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("run %s not found", id)
+	case 1:
+		return store.Load(matches[0])
+	default:
+		return nil, fmt.Errorf("run ID prefix %s is ambiguous (%d matches)", id, len(matches))
+	}
+}
+
+func writeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func shortCommit(c string) string {
+	if len(c) > 12 {
+		return c[:12]
+	}
+	return c
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
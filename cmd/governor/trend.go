@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+func trendMain(args []string) error {
+	fs := flag.NewFlagSet("trend", flag.ExitOnError)
+	metricsFlag := fs.String("metrics", strings.Join(report.DefaultTrendMetrics, ","), "comma-separated metrics to show")
+	pkgFlag := fs.String("pkg", "", "only count findings under this import path or directory prefix")
+	sinceFlag := fs.String("since", "", "only use runs started after a duration ago (e.g. 720h) or a date (2006-01-02)")
+	untilFlag := fs.String("until", "", "only use runs started before a duration ago or a date")
+	formatFlag := fs.String("format", "text", "output format: text, csv or json")
+	_ = fs.Parse(args)
+
+	metrics, err := parseMetrics(*metricsFlag)
+	if err != nil {
+		return err
+	}
+
+	_, loaded, err := loadWorkspace()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(loaded.Config, loaded.RepoRoot)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	filter := report.Filter{}
+	if *sinceFlag != "" {
+		if filter.Since, err = parseSince(*sinceFlag, now); err != nil {
+			return err
+		}
+	}
+	var until time.Time
+	if *untilFlag != "" {
+		if until, err = parseSince(*untilFlag, now); err != nil {
+			return err
+		}
+	}
+
+	entries, err := store.List(filter)
+	if err != nil {
+		return err
+	}
+	var runs []*report.RunResult
+	for _, e := range entries {
+		if !until.IsZero() && e.Started.After(until) {
+			continue
+		}
+		rr, err := store.Load(e.ID)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, rr)
+	}
+
+	series := report.Trend(runs, report.TrendOptions{
+		Metrics:             metrics,
+		Prefix:              *pkgFlag,
+		ComplexityThreshold: loaded.Config.ComplexityThreshold(),
+	})
+
+	switch *formatFlag {
+	case "text":
+		return writeTrendText(series)
+	case "csv":
+		return writeTrendCSV(series)
+	case "json":
+		return writeJSON(series)
+	default:
+		return fmt.Errorf("invalid -format %q: want text, csv or json", *formatFlag)
+	}
+}
+
+// parseMetrics validates a comma-separated list of metric names.
+func parseMetrics(s string) ([]string, error) {
+	known := make(map[string]bool)
+	for _, kind := range []report.Kind{report.Check, report.Audit} {
+		for _, name := range report.MetricNames(kind) {
+			known[name] = true
+		}
+	}
+	var out []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown metric %q", name)
+		}
+		out = append(out, name)
+	}
+	return out, nil
+}
+
+func writeTrendText(series []report.Series) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tTREND\tFIRST\tLAST\tRUNS\tPERIOD")
+	for _, s := range series {
+		if len(s.Points) == 0 {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t0\t-\n", s.Metric)
+			continue
+		}
+		values := make([]float64, len(s.Points))
+		for i, p := range s.Points {
+			values[i] = p.Value
+		}
+		first, last := s.Points[0], s.Points[len(s.Points)-1]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s .. %s\n",
+			s.Metric, sparkline(values),
+			formatTrendValue(s.Metric, first.Value), formatTrendValue(s.Metric, last.Value),
+			len(s.Points),
+			first.Time.Local().Format("2006-01-02"), last.Time.Local().Format("2006-01-02"))
+	}
+	return tw.Flush()
+}
+
+func writeTrendCSV(series []report.Series) error {
+	w := csv.NewWriter(os.Stdout)
+	_ = w.Write([]string{"metric", "time", "run_id", "value"})
+	for _, s := range series {
+		for _, p := range s.Points {
+			_ = w.Write([]string{
+				s.Metric,
+				p.Time.UTC().Format(time.RFC3339),
+				p.RunID,
+				strconv.FormatFloat(p.Value, 'f', -1, 64),
+			})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func formatTrendValue(metric string, v float64) string {
+	if metric == report.MetricCoverage {
+		return fmt.Sprintf("%.1f%%", v)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// sparkBlocks are the glyphs used by sparkline, lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values as a row of block glyphs scaled between the
+// minimum and maximum value.
+func sparkline(values []float64) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		idx := 0
+		if hi > lo {
+			idx = int((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[idx])
+	}
+	return b.String()
+}
@@ -2,31 +2,72 @@
 package config
 
 import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
-
-	"gopkg.in/yaml.v3"
 )
 
 // Default values for runner configuration.
 const (
-	DefaultTimeout   = 5 * time.Minute
-	DefaultMaxOutput = 1 << 20 // 1 MB
+	DefaultTimeout     = 5 * time.Minute
+	DefaultMaxOutput   = 1 << 20 // 1 MB
+	DefaultGracePeriod = 5 * time.Second
+	DefaultConcurrency = 2 // commands run at once by one process
+)
+
+// DefaultMarkdownLimit keeps Markdown summaries within the size limit of a
+// GitHub pull request comment.
+const DefaultMarkdownLimit = 65536
+
+// Default values for run history retention.
+const (
+	DefaultHistoryMaxRuns = 200
+	DefaultHistoryMaxAge  = 90 * 24 * time.Hour
+	DefaultHistoryMaxSize = 256 << 20 // 256 MB
 )
 
 // Config holds the parsed .governor configuration.
 // All fields are optional; zero values represent defaults.
 type Config struct {
-	Version      int               `yaml:"version"`
-	RawTimeout   string            `yaml:"timeout"`    // e.g. "5m", "30s"
-	RawMaxOutput int               `yaml:"max_output"` // bytes
-	Test         TestConfig        `yaml:"test"`
-	Lint         LintConfig        `yaml:"lint"`
-	Staticcheck  StaticcheckConfig `yaml:"staticcheck"`
-	Check        CheckConfig       `yaml:"check"`
-	Audit        AuditConfig       `yaml:"audit"`
+	Version        int               `yaml:"version"`
+	Extends        Extends           `yaml:"extends"`      // files whose settings these build on
+	RawTimeout     string            `yaml:"timeout"`      // e.g. "5m", "30s"
+	RawGracePeriod string            `yaml:"grace_period"` // e.g. "10s"
+	RawMaxOutput   int               `yaml:"max_output"`   // bytes
+	RawTailOutput  int               `yaml:"tail_output"`  // bytes of max_output kept from the end
+	RawConcurrency int               `yaml:"concurrency"`  // commands run at once
+	Test           TestConfig        `yaml:"test"`
+	Lint           LintConfig        `yaml:"lint"`
+	Staticcheck    StaticcheckConfig `yaml:"staticcheck"`
+	Check          CheckConfig       `yaml:"check"`
+	Audit          AuditConfig       `yaml:"audit"`
+	History        HistoryConfig     `yaml:"history"`
+	Output         OutputConfig      `yaml:"output"`
+
+	// Tools pins the versions of the tools Governor runs, by name: an
+	// exact version such as "v0.6.1", or comma-separated comparisons such
+	// as ">=v1.60.0, <v2.0.0".
+	Tools map[string]string `yaml:"tools"`
+
+	// Overrides adjust the settings of some packages. Check and audit
+	// partition their packages by the overrides that match them, and run
+	// each group with its own settings.
+	Overrides []Override `yaml:"overrides"`
+
+	// Profiles are named settings applied over the others on request,
+	// with WithProfile.
+	Profiles map[string]Profile `yaml:"profiles"`
+
+	// Exec applies to every command. Steps can override it in their own
+	// section.
+	Exec ExecConfig `yaml:",inline"`
 }
 
 // Timeout returns the configured timeout or the default.
@@ -40,6 +81,29 @@ func (c *Config) Timeout() time.Duration {
 	return DefaultTimeout
 }
 
+// GracePeriod returns how long a timed-out or cancelled command has to
+// exit after SIGTERM before it is killed, or the default.
+func (c *Config) GracePeriod() time.Duration {
+	if c.RawGracePeriod != "" {
+		d, err := time.ParseDuration(c.RawGracePeriod)
+		if err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultGracePeriod
+}
+
+// Hash returns a short, stable hash of the configuration, so that runs
+// made under different settings can be told apart.
+func (c *Config) Hash() string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
 // MaxOutputBytes returns the configured max output size or the default.
 func (c *Config) MaxOutputBytes() int {
 	if c.RawMaxOutput > 0 {
@@ -48,15 +112,131 @@ func (c *Config) MaxOutputBytes() int {
 	return DefaultMaxOutput
 }
 
+// TailOutputBytes returns how many bytes of the output kept in memory come
+// from its end, or half of MaxOutputBytes by default.
+func (c *Config) TailOutputBytes() int {
+	limit := c.MaxOutputBytes()
+	if c.RawTailOutput > 0 && c.RawTailOutput <= limit {
+		return c.RawTailOutput
+	}
+	return limit / 2
+}
+
+// Concurrency returns how many commands a process runs at once in the
+// workspace, or the default.
+func (c *Config) Concurrency() int {
+	if c.RawConcurrency > 0 {
+		return c.RawConcurrency
+	}
+	return DefaultConcurrency
+}
+
+// HistoryConfig controls where run results are persisted and how long
+// they are kept. Runs are shared by the CLI and the MCP server. A limit
+// set to 0 is lifted; one left unset has its default.
+type HistoryConfig struct {
+	Dir       string `yaml:"dir"`      // storage directory, relative to the repo root (default: user cache dir keyed by repo)
+	MaxRuns   *int   `yaml:"max_runs"` // maximum number of stored runs
+	RawMaxAge string `yaml:"max_age"`  // e.g. "720h"; older runs are pruned
+	MaxSize   *int64 `yaml:"max_size"` // total bytes across stored runs
+}
+
+// HistoryDir returns the directory where runs for the repository at
+// repoRoot are stored. A configured dir is resolved against repoRoot.
+// Otherwise runs live in the user cache directory (e.g. $XDG_CACHE_HOME)
+// under a name derived from the repository root, so that the .governor
+// file itself never has to become a directory.
+func (c *Config) HistoryDir(repoRoot string) (string, error) {
+	if c.History.Dir != "" {
+		if filepath.IsAbs(c.History.Dir) {
+			return filepath.Clean(c.History.Dir), nil
+		}
+		return filepath.Join(repoRoot, c.History.Dir), nil
+	}
+
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locating user cache directory: %w", err)
+	}
+	abs, err := filepath.Abs(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	name := filepath.Base(abs) + "-" + hex.EncodeToString(sum[:])[:12]
+	return filepath.Join(cache, "governor", "runs", name), nil
+}
+
+// WorkspaceLock returns the lock file through which governor processes
+// running commands in the repository at repoRoot exclude each other. It
+// lives in the history directory, which every process using the
+// repository shares, so that it never shows up in the worktree.
+func (c *Config) WorkspaceLock(repoRoot string) (string, error) {
+	dir, err := c.HistoryDir(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "workspace.lock"), nil
+}
+
+// HistoryMaxRuns returns the configured run count limit or the default.
+// 0 is unlimited.
+func (c *Config) HistoryMaxRuns() int {
+	if c.History.MaxRuns != nil && *c.History.MaxRuns >= 0 {
+		return *c.History.MaxRuns
+	}
+	return DefaultHistoryMaxRuns
+}
+
+// HistoryMaxAge returns the configured run age limit or the default.
+// 0 is unlimited.
+func (c *Config) HistoryMaxAge() time.Duration {
+	if c.History.RawMaxAge != "" {
+		d, err := time.ParseDuration(c.History.RawMaxAge)
+		if err == nil && d >= 0 {
+			return d
+		}
+	}
+	return DefaultHistoryMaxAge
+}
+
+// HistoryMaxSize returns the configured total size limit or the default.
+// 0 is unlimited.
+func (c *Config) HistoryMaxSize() int64 {
+	if c.History.MaxSize != nil && *c.History.MaxSize >= 0 {
+		return *c.History.MaxSize
+	}
+	return DefaultHistoryMaxSize
+}
+
+// OutputConfig controls how runs are rendered for other tools.
+type OutputConfig struct {
+	// RepoURL is the URL under which the module's files are browsable,
+	// used to link findings in Markdown summaries. "{commit}" is replaced
+	// with the run's commit, e.g. https://github.com/org/repo/blob/{commit}.
+	RepoURL       string `yaml:"repo_url"`
+	MarkdownLimit int    `yaml:"markdown_limit"` // maximum size of Markdown summaries, in bytes
+}
+
+// MarkdownLimit returns the configured Markdown size limit or the default.
+func (c *Config) MarkdownLimit() int {
+	if c.Output.MarkdownLimit > 0 {
+		return c.Output.MarkdownLimit
+	}
+	return DefaultMarkdownLimit
+}
+
 // TestConfig controls how gov_test is executed.
 type TestConfig struct {
-	Args []string `yaml:"args"` // extra flags appended to go test -json (e.g. -race, -count=1)
+	Args []string   `yaml:"args"` // extra flags appended to go test -json (e.g. -race, -count=1)
+	Exec ExecConfig `yaml:",inline"`
 }
 
 // LintConfig controls how gov_lint is executed.
 type LintConfig struct {
-	Config string   `yaml:"config"` // path to golangci-lint config file
-	Args   []string `yaml:"args"`   // extra flags (e.g. --timeout=5m)
+	Config string     `yaml:"config"` // path to golangci-lint config file
+	Args   []string   `yaml:"args"`   // extra flags (e.g. --timeout=5m)
+	Exec   ExecConfig `yaml:",inline"`
 }
 
 // CheckConfig defines the steps for gov_check.
@@ -66,8 +246,9 @@ type CheckConfig struct {
 
 // StaticcheckConfig controls how staticcheck is executed.
 type StaticcheckConfig struct {
-	Checks []string `yaml:"checks"` // e.g. ["all", "-ST1000"]
-	Args   []string `yaml:"args"`   // extra flags
+	Checks []string   `yaml:"checks"` // e.g. ["all", "-ST1000"]
+	Args   []string   `yaml:"args"`   // extra flags
+	Exec   ExecConfig `yaml:",inline"`
 }
 
 // AuditConfig defines the steps and per-check settings for gov_audit.
@@ -82,28 +263,34 @@ type AuditConfig struct {
 
 // VulncheckConfig controls how govulncheck is executed.
 type VulncheckConfig struct {
-	Args []string `yaml:"args"` // extra flags for govulncheck
+	Args []string   `yaml:"args"` // extra flags for govulncheck
+	Exec ExecConfig `yaml:",inline"`
 }
 
 // CoverageConfig controls how test coverage is collected.
 type CoverageConfig struct {
-	Args []string `yaml:"args"` // extra flags for go test -coverprofile
+	Args []string   `yaml:"args"` // extra flags for go test -coverprofile
+	Exec ExecConfig `yaml:",inline"`
 }
 
 // ComplexityConfig controls how cognitive complexity is measured.
 type ComplexityConfig struct {
-	Args []string `yaml:"args"` // extra flags for gocognit
+	Threshold int        `yaml:"threshold"` // functions above this score are reported as complex (default: 15)
+	Args      []string   `yaml:"args"`      // extra flags for gocognit
+	Exec      ExecConfig `yaml:",inline"`
 }
 
 // DeadcodeConfig controls how dead code detection is run.
 type DeadcodeConfig struct {
-	Args []string `yaml:"args"` // extra flags for deadcode
+	Args []string   `yaml:"args"` // extra flags for deadcode
+	Exec ExecConfig `yaml:",inline"`
 }
 
 // DuplConfig controls how duplicate code detection is run.
 type DuplConfig struct {
-	Threshold int      `yaml:"threshold"` // minimum token length (default: 50)
-	Args      []string `yaml:"args"`      // extra flags for dupl
+	Threshold int        `yaml:"threshold"` // minimum token length (default: 50)
+	Args      []string   `yaml:"args"`      // extra flags for dupl
+	Exec      ExecConfig `yaml:",inline"`
 }
 
 // DefaultCheckSteps are used when no steps are configured.
@@ -136,17 +323,74 @@ func (c *Config) DuplThreshold() int {
 	return 50
 }
 
+// ComplexityThreshold returns the configured cognitive complexity threshold,
+// falling back to 15.
+func (c *Config) ComplexityThreshold() int {
+	if c.Audit.Complexity.Threshold > 0 {
+		return c.Audit.Complexity.Threshold
+	}
+	return 15
+}
+
 // LoadResult holds the parsed config and the discovered repository root.
 type LoadResult struct {
 	Config   *Config
-	RepoRoot string // directory containing go.mod; falls back to workspace
+	RepoRoot string   // directory containing go.mod; falls back to workspace
+	File     string   // the .governor file read, or "" if there is none
+	Extended []string // the files File extends, directly or not, in the order they apply
+
+	sources []source // Extended then File, for locating settings
+}
+
+// Position returns the file and line of the setting at key, such as
+// "audit.steps[1]", or zero values if it is not set in a file. Of the
+// files setting it, Position returns the last to apply, whose value is the
+// one that took effect.
+func (r *LoadResult) Position(key string) (file string, line int) {
+	for i := len(r.sources) - 1; i >= 0; i-- {
+		if k, _ := lookup(r.sources[i].root, key); k != nil {
+			return r.sources[i].file, k.Line
+		}
+	}
+	return "", 0
+}
+
+// Locate fills in the file and line of a problem found in the loaded
+// configuration: those of its setting or, for a setting that is missing,
+// of the closest section that is in the file.
+func (r *LoadResult) Locate(p Problem) Problem {
+	for key := p.Key; p.File == "" && key != ""; key = parentKey(key) {
+		if file, line := r.Position(key); file != "" {
+			p.File, p.Line = file, line
+		}
+	}
+	if p.File == "" && p.Line > 0 {
+		p.File = r.File
+	}
+	return p
+}
+
+// parentKey returns the key of the section containing the setting at key,
+// or "" at the top level.
+func parentKey(key string) string {
+	if i := strings.LastIndexAny(key, ".["); i >= 0 {
+		return key[:i]
+	}
+	return ""
 }
 
 // Load reads the .governor file from the repository root.
 // The repository root is discovered by walking upward from workspace
 // looking for go.mod. If no .governor file exists, a default Config is returned.
+// The settings of the files it extends apply first, each overlaid by the
+// next as overrides are: lists replace lists, mappings merge, and other
+// settings replace those they follow unless they are zero.
+// The files are decoded strictly: unknown settings, values of the wrong
+// type, extended files that cannot be read or extend themselves, and any
+// of the Config's Problems make Load fail with a ValidationError giving
+// their files and lines.
 func Load(workspace string) (*LoadResult, error) {
-	root, err := findRepoRoot(workspace)
+	root, err := FindRepoRoot(workspace)
 	if err != nil {
 		// No go.mod found; use workspace as root.
 		root = workspace
@@ -161,15 +405,42 @@ func Load(workspace string) (*LoadResult, error) {
 		return nil, fmt.Errorf("reading .governor: %w", err)
 	}
 
-	cfg := &Config{}
-	if err := yaml.Unmarshal(data, cfg); err != nil {
+	cfg, sources, problems, err := loadFile(path, data, []string{path}, map[string]bool{})
+	if err != nil {
 		return nil, fmt.Errorf("parsing .governor: %w", err)
 	}
-	return &LoadResult{Config: cfg, RepoRoot: root}, nil
+	res := &LoadResult{Config: cfg, RepoRoot: root, File: path, sources: sources}
+	for _, s := range sources[:len(sources)-1] {
+		res.Extended = append(res.Extended, s.file)
+	}
+	problems = append(problems, cfg.Problems()...)
+	if len(problems) > 0 {
+		for i, p := range problems {
+			problems[i] = res.Locate(p)
+		}
+		// Those of the .governor file first, then by file and line.
+		slices.SortStableFunc(problems, func(a, b Problem) int {
+			return cmp.Or(
+				cmp.Compare(boolInt(a.File != path), boolInt(b.File != path)),
+				strings.Compare(a.File, b.File),
+				cmp.Compare(a.Line, b.Line),
+			)
+		})
+		return nil, &ValidationError{Problems: problems}
+	}
+	return res, nil
+}
+
+// boolInt returns 1 for true and 0 for false, for ordering by b.
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
 }
 
-// findRepoRoot walks upward from dir looking for a directory containing go.mod.
-func findRepoRoot(dir string) (string, error) {
+// FindRepoRoot walks upward from dir looking for a directory containing go.mod.
+func FindRepoRoot(dir string) (string, error) {
 	dir, err := filepath.Abs(dir)
 	if err != nil {
 		return "", err
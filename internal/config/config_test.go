@@ -1,9 +1,14 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoad_FromRepoRoot(t *testing.T) {
@@ -90,3 +95,700 @@ func TestLoad_NoGovernorFile(t *testing.T) {
 		t.Errorf("expected default config, got Version = %d", res.Config.Version)
 	}
 }
+
+func TestHistoryDir(t *testing.T) {
+	root := t.TempDir()
+
+	cfg := &Config{History: HistoryConfig{Dir: ".cache/governor"}}
+	got, err := cfg.HistoryDir(root)
+	if err != nil {
+		t.Fatalf("HistoryDir: %v", err)
+	}
+	if want := filepath.Join(root, ".cache/governor"); got != want {
+		t.Errorf("HistoryDir = %q, want %q", got, want)
+	}
+
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(root, "xdg"))
+	def := &Config{}
+	a, err := def.HistoryDir(filepath.Join(root, "repo-a"))
+	if err != nil {
+		t.Fatalf("HistoryDir: %v", err)
+	}
+	b, _ := def.HistoryDir(filepath.Join(root, "repo-b"))
+	if a == b {
+		t.Errorf("default HistoryDir should be keyed by repo, got %q for both", a)
+	}
+}
+
+func TestHash(t *testing.T) {
+	a := &Config{Check: CheckConfig{Steps: []string{"test"}}}
+	b := &Config{Check: CheckConfig{Steps: []string{"test"}}}
+	c := &Config{Check: CheckConfig{Steps: []string{"test", "lint"}}}
+	if a.Hash() != b.Hash() {
+		t.Error("equal configs should hash equally")
+	}
+	if a.Hash() == c.Hash() {
+		t.Error("different configs should hash differently")
+	}
+}
+
+func TestStepExec(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/test\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	yaml := `
+env:
+  allow: [PATH, HOME, "GO*"]
+  unset: [GOPROXY]
+  set: {A: "1", B: "2"}
+  cgo_enabled: false
+limits:
+  cpu: 10m
+  open_files: 1024
+test:
+  args: [-race]
+  env:
+    unset: [GOFLAGS]
+    set: {B: "3"}
+    gotmpdir: .cache/tmp
+  limits:
+    memory: 4294967296
+`
+	if err := os.WriteFile(filepath.Join(dir, ".governor"), []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	cfg := res.Config
+	if len(cfg.Test.Args) != 1 {
+		t.Errorf("Test.Args = %v, want [-race]", cfg.Test.Args)
+	}
+
+	test := cfg.StepExec("test")
+	if !reflect.DeepEqual(test.Env.Allow, []string{"PATH", "HOME", "GO*"}) {
+		t.Errorf("test Allow = %v", test.Env.Allow)
+	}
+	if !reflect.DeepEqual(test.Env.Unset, []string{"GOPROXY", "GOFLAGS"}) {
+		t.Errorf("test Unset = %v, want both levels", test.Env.Unset)
+	}
+	if !reflect.DeepEqual(test.Env.Set, map[string]string{"A": "1", "B": "3"}) {
+		t.Errorf("test Set = %v, want step to override B", test.Env.Set)
+	}
+	if test.Env.CgoEnabled == nil || *test.Env.CgoEnabled || test.Env.GOTMPDIR != ".cache/tmp" {
+		t.Errorf("test CgoEnabled = %v, GOTMPDIR = %q", test.Env.CgoEnabled, test.Env.GOTMPDIR)
+	}
+	if l := test.Limits; l.CPU() != 10*time.Minute || l.Memory != 4<<30 || l.OpenFiles != 1024 {
+		t.Errorf("test Limits = %+v", l)
+	}
+
+	// Steps without overrides, and the top-level settings, are unaffected.
+	lint := cfg.StepExec("lint")
+	if lint.Env.GOTMPDIR != "" || lint.Limits.Memory != 0 || lint.Env.Set["B"] != "2" {
+		t.Errorf("lint = %+v, want top-level settings", lint)
+	}
+	if cfg.Exec.Env.Set["B"] != "2" {
+		t.Error("StepExec modified the top-level settings")
+	}
+}
+
+func TestProblems(t *testing.T) {
+	maxRuns := -1
+	cfg := &Config{
+		RawTimeout:    "5 minutes",
+		RawMaxOutput:  1000,
+		RawTailOutput: 2000,
+		Check:         CheckConfig{Steps: []string{"test", "lnt"}},
+		Audit:         AuditConfig{Steps: []string{"coverage", "deadcode"}, Deadcode: DeadcodeConfig{Exec: ExecConfig{Limits: LimitsConfig{RawCPU: "-1m"}}}},
+		History:       HistoryConfig{RawMaxAge: "720h", MaxRuns: &maxRuns},
+	}
+	want := []string{
+		`check.steps[1]: unknown step "lnt" (known: test, lint, staticcheck)`,
+		`timeout: "5 minutes" is not a positive duration (e.g. 30s, 5m)`,
+		`audit.deadcode.limits.cpu: "-1m" is not a positive duration (e.g. 30s, 5m)`,
+		`history.max_runs: -1 is negative`,
+		`tail_output: 2000 is more than max_output (1000)`,
+	}
+	var got []string
+	for _, p := range cfg.Problems() {
+		got = append(got, p.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Problems() =\n%q\nwant\n%q", got, want)
+	}
+	if got := (&Config{}).Problems(); len(got) != 0 {
+		t.Errorf("default config problems = %q", got)
+	}
+}
+
+func TestLoad_Strict(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/test\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, ".governor")
+	data := `version: 1
+timout: 10m
+max_output: lots
+test:
+  args: [-race]
+  env:
+    sett: {A: "1"}
+audit:
+  steps: [coverage, dupl, complexty]
+  dupl:
+    threshold: -5
+`
+	if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(dir)
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Load error = %v, want a ValidationError", err)
+	}
+	want := []Problem{
+		{file, 2, "timout", `unknown setting, did you mean "timeout"?`},
+		{file, 3, "max_output", `"lots" is not an integer`},
+		{file, 7, "test.env.sett", `unknown setting, did you mean "set"?`},
+		{file, 9, "audit.steps[2]", `unknown step "complexty" (known: coverage, complexity, deadcode, dupl, vulncheck)`},
+		{file, 11, "audit.dupl.threshold", `-5 is negative`},
+	}
+	if !reflect.DeepEqual(invalid.Problems, want) {
+		t.Errorf("problems =\n%v\nwant\n%v", invalid.Problems, want)
+	}
+}
+
+func TestLoad_HistoryLimits(t *testing.T) {
+	tests := []struct {
+		name, data     string
+		runs, size     int64
+		age            time.Duration
+		wantProblemKey string
+	}{
+		{"default", "version: 1\n", DefaultHistoryMaxRuns, DefaultHistoryMaxSize, DefaultHistoryMaxAge, ""},
+		{"set", "history: {max_runs: 10, max_age: 24h, max_size: 1024}\n", 10, 1024, 24 * time.Hour, ""},
+		{"unlimited", "history: {max_runs: 0, max_age: 0, max_size: 0}\n", 0, 0, 0, ""},
+		{"bad age", "history: {max_age: 30d}\n", 0, 0, 0, "history.max_age"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/test\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, ".governor"), []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := Load(dir)
+			if tt.wantProblemKey != "" {
+				var invalid *ValidationError
+				if !errors.As(err, &invalid) || len(invalid.Problems) != 1 || invalid.Problems[0].Key != tt.wantProblemKey {
+					t.Fatalf("Load error = %v, want a problem with %s", err, tt.wantProblemKey)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := res.Config
+			if got := cfg.HistoryMaxRuns(); int64(got) != tt.runs {
+				t.Errorf("HistoryMaxRuns() = %d, want %d", got, tt.runs)
+			}
+			if got := cfg.HistoryMaxAge(); got != tt.age {
+				t.Errorf("HistoryMaxAge() = %v, want %v", got, tt.age)
+			}
+			if got := cfg.HistoryMaxSize(); got != tt.size {
+				t.Errorf("HistoryMaxSize() = %d, want %d", got, tt.size)
+			}
+		})
+	}
+}
+
+func TestLoad_SyntaxError(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".governor"), []byte("timeout: [5m\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Load(dir)
+	if err == nil || !strings.Contains(err.Error(), "line") {
+		t.Errorf("Load error = %v, want a syntax error with its line", err)
+	}
+}
+
+func TestLoadResult_Position(t *testing.T) {
+	dir := t.TempDir()
+	data := "check:\n  steps:\n    - test\n    - lint\ntools:\n  staticcheck: v0.6.1\n"
+	if err := os.WriteFile(filepath.Join(dir, ".governor"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]int{"check.steps[1]": 4, "tools.staticcheck": 6, "check": 1, "audit.steps": 0, "check.steps[2]": 0} {
+		if _, line := res.Position(key); line != want {
+			t.Errorf("Position(%q) line = %d, want %d", key, line, want)
+		}
+	}
+}
+
+func TestMarshal_Effective(t *testing.T) {
+	cfg := &Config{
+		RawTimeout: "10m",
+		Test:       TestConfig{Args: []string{"-race"}, Exec: ExecConfig{Env: EnvConfig{CgoEnabled: new(bool)}}},
+		Tools:      map[string]string{"staticcheck": "v0.6.1"},
+	}
+	data, err := Marshal(cfg.Effective())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"timeout: 10m0s", "cgo_enabled: false", "staticcheck: v0.6.1", "threshold: 50", "- vulncheck"} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("Marshal output lacks %q:\n%s", s, data)
+		}
+	}
+	if strings.Contains(string(data), "goflags") {
+		t.Errorf("Marshal output has unset settings:\n%s", data)
+	}
+
+	// The effective configuration is itself a valid configuration.
+	got, _, problems, err := decode(data)
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("decode = %v, %v", problems, err)
+	}
+	if !reflect.DeepEqual(got.Effective(), cfg.Effective()) {
+		t.Errorf("round trip =\n%+v\nwant\n%+v", got.Effective(), cfg.Effective())
+	}
+}
+
+func TestSchema(t *testing.T) {
+	s := Schema()
+	timeout := s.Properties["timeout"]
+	if timeout == nil || timeout.Pattern == "" || timeout.Description == "" {
+		t.Errorf("timeout = %+v, want a described duration", timeout)
+	}
+	if got := s.Properties["test"].Properties["limits"].Properties["memory"]; got == nil || got.Type != "integer" {
+		t.Errorf("test.limits.memory = %+v, want an inlined integer setting", got)
+	}
+	if got := s.Properties["check"].Properties["steps"].Items.Enum; len(got) != len(DefaultCheckSteps) {
+		t.Errorf("check.steps enum = %v", got)
+	}
+
+	// The published file must be regenerated when the settings change.
+	want, err := SchemaJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	published, err := os.ReadFile(filepath.Join("..", "..", "governor.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(published) != string(want) {
+		t.Error("governor.schema.json is out of date: run governor config schema > governor.schema.json")
+	}
+}
+
+func TestMatchPackage(t *testing.T) {
+	tests := []struct {
+		pattern, dir string
+		want         bool
+	}{
+		{"internal/pb/...", "internal/pb", true},
+		{"internal/pb/...", "internal/pb/a/b", true},
+		{"internal/pb/...", "internal/pbx", false},
+		{"./old/...", "old/x", true},
+		{"old", "old", true},
+		{"old", "old/x", false},
+		{"*/gen", "api/gen", true},
+		{"*/gen", "api/v1/gen", false},
+		{".../mocks", "a/b/mocks", true},
+		{".", ".", true},
+		{".", "a", false},
+	}
+	for _, tt := range tests {
+		if got := MatchPackage(tt.pattern, tt.dir); got != tt.want {
+			t.Errorf("MatchPackage(%q, %q) = %v, want %v", tt.pattern, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestForPackage(t *testing.T) {
+	cfg := &Config{
+		Test:  TestConfig{Args: []string{"-race"}, Exec: ExecConfig{Env: EnvConfig{Set: map[string]string{"A": "1"}}}},
+		Check: CheckConfig{Steps: []string{"test", "lint", "staticcheck"}},
+		Audit: AuditConfig{Complexity: ComplexityConfig{Threshold: 10}},
+		Overrides: []Override{
+			{Packages: []string{"old/..."}, Check: CheckConfig{Steps: []string{"test"}}, Audit: AuditConfig{Complexity: ComplexityConfig{Threshold: 30}}},
+			{Packages: []string{"old/strict"}, Audit: AuditConfig{Complexity: ComplexityConfig{Threshold: 20}}, Test: TestConfig{Exec: ExecConfig{Env: EnvConfig{Set: map[string]string{"B": "2"}}}}},
+		},
+	}
+
+	if got, matched := cfg.ForPackage("core"); got != cfg || matched != nil {
+		t.Errorf("ForPackage(core) = %p, %v; want the config itself", got, matched)
+	}
+
+	got, matched := cfg.ForPackage("old/strict")
+	if !reflect.DeepEqual(matched, []int{0, 1}) {
+		t.Errorf("matched = %v, want [0 1]", matched)
+	}
+	if !reflect.DeepEqual(got.CheckSteps(), []string{"test"}) {
+		t.Errorf("steps = %v, want [test]", got.CheckSteps())
+	}
+	if got.ComplexityThreshold() != 20 {
+		t.Errorf("threshold = %d, want the later override's 20", got.ComplexityThreshold())
+	}
+	if !reflect.DeepEqual(got.Test.Args, []string{"-race"}) {
+		t.Errorf("test args = %v, want those of the top level", got.Test.Args)
+	}
+	if want := map[string]string{"A": "1", "B": "2"}; !reflect.DeepEqual(got.Test.Exec.Env.Set, want) {
+		t.Errorf("env = %v, want %v", got.Test.Exec.Env.Set, want)
+	}
+	if len(cfg.Test.Exec.Env.Set) != 1 || cfg.ComplexityThreshold() != 10 {
+		t.Error("ForPackage modified the top-level settings")
+	}
+}
+
+func TestLoad_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	data := `overrides:
+  - packages: [internal/pb/...]
+    check:
+      steps: [test, lnt]
+  - lint:
+      confg: legacy.yml
+`
+	if err := os.WriteFile(filepath.Join(dir, ".governor"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Load(dir)
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Load error = %v, want a ValidationError", err)
+	}
+	var got []string
+	for _, p := range invalid.Problems {
+		got = append(got, fmt.Sprintf("%d: %s: %s", p.Line, p.Key, p.Message))
+	}
+	want := []string{
+		`4: overrides[0].check.steps[1]: unknown step "lnt" (known: test, lint, staticcheck)`,
+		`5: overrides[1].packages: no package patterns`,
+		`6: overrides[1].lint.confg: unknown setting, did you mean "config"?`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problems =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestWithProfile(t *testing.T) {
+	cfg := &Config{
+		RawTimeout: "5m",
+		Test:       TestConfig{Args: []string{"-short"}, Exec: ExecConfig{Env: EnvConfig{Set: map[string]string{"A": "1"}}}},
+		Audit:      AuditConfig{Steps: []string{"coverage"}},
+		Profiles: map[string]Profile{
+			"ci":    {RawTimeout: "20m", Test: TestConfig{Args: []string{"-race", "-count=1"}, Exec: ExecConfig{Env: EnvConfig{Set: map[string]string{"B": "2"}}}}},
+			"quick": {Check: CheckConfig{Steps: []string{"test"}}},
+		},
+	}
+
+	if got, err := cfg.WithProfile(""); got != cfg || err != nil {
+		t.Errorf("WithProfile(\"\") = %p, %v; want the config itself", got, err)
+	}
+
+	got, err := cfg.WithProfile("ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Timeout() != 20*time.Minute {
+		t.Errorf("timeout = %s, want the profile's 20m", got.Timeout())
+	}
+	if !reflect.DeepEqual(got.Test.Args, []string{"-race", "-count=1"}) {
+		t.Errorf("test args = %v, want those of the profile", got.Test.Args)
+	}
+	if want := map[string]string{"A": "1", "B": "2"}; !reflect.DeepEqual(got.Test.Exec.Env.Set, want) {
+		t.Errorf("env = %v, want %v", got.Test.Exec.Env.Set, want)
+	}
+	if !reflect.DeepEqual(got.AuditSteps(), []string{"coverage"}) {
+		t.Errorf("audit steps = %v, want those of the top level", got.AuditSteps())
+	}
+	if got.Profiles != nil {
+		t.Errorf("profiles = %v, want none", got.Profiles)
+	}
+	if len(cfg.Test.Exec.Env.Set) != 1 || cfg.Timeout() != 5*time.Minute {
+		t.Error("WithProfile modified the top-level settings")
+	}
+
+	if _, err := cfg.WithProfile("nightly"); err == nil || err.Error() != `unknown profile "nightly" (profiles: ci, quick)` {
+		t.Errorf("WithProfile(nightly) error = %v", err)
+	}
+}
+
+func TestLoad_Profiles(t *testing.T) {
+	dir := t.TempDir()
+	data := `profiles:
+  ci:
+    test:
+      args: [-race]
+    history:
+      max_runs: 10
+  quick:
+    check:
+      steps: [tset]
+  "ci/full":
+    timeout: 1h
+`
+	if err := os.WriteFile(filepath.Join(dir, ".governor"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Load(dir)
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Load error = %v, want a ValidationError", err)
+	}
+	var got []string
+	for _, p := range invalid.Problems {
+		got = append(got, fmt.Sprintf("%d: %s: %s", p.Line, p.Key, p.Message))
+	}
+	want := []string{
+		`5: profiles.ci.history: unknown setting`,
+		`9: profiles.quick.check.steps[0]: unknown step "tset" (known: test, lint, staticcheck)`,
+		`10: profiles.ci/full: invalid profile name: use letters, digits, - and _`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problems =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestLoad_EmptySettingsReplace(t *testing.T) {
+	root := t.TempDir()
+	for name, data := range map[string]string{
+		"base.yml": `staticcheck:
+  checks: [all]
+audit:
+  complexity:
+    threshold: 30
+`,
+		"repo/.governor": `extends: ../base.yml
+staticcheck:
+  checks: []
+test:
+  args: [-race]
+overrides:
+  - packages: [gen/...]
+    test:
+      args: []
+profiles:
+  quick:
+    test:
+      args: []
+    audit:
+      complexity:
+        threshold: 0
+`,
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := Load(filepath.Join(root, "repo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := res.Config
+	if len(cfg.Staticcheck.Checks) != 0 {
+		t.Errorf("checks = %v, want the empty list of .governor", cfg.Staticcheck.Checks)
+	}
+	if cfg.Audit.Complexity.Threshold != 30 || !reflect.DeepEqual(cfg.Test.Args, []string{"-race"}) {
+		t.Errorf("threshold, args = %d, %v; want 30, [-race]", cfg.Audit.Complexity.Threshold, cfg.Test.Args)
+	}
+
+	quick, err := cfg.WithProfile("quick")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quick.Test.Args) != 0 {
+		t.Errorf("quick test args = %v, want -race removed", quick.Test.Args)
+	}
+	if quick.Audit.Complexity.Threshold != 0 {
+		t.Errorf("quick threshold = %d, want it reset to 0", quick.Audit.Complexity.Threshold)
+	}
+
+	if gen, _ := cfg.ForPackage("gen/pb"); len(gen.Test.Args) != 0 {
+		t.Errorf("gen/pb test args = %v, want -race removed", gen.Test.Args)
+	}
+	if core, _ := cfg.ForPackage("core"); !reflect.DeepEqual(core.Test.Args, []string{"-race"}) {
+		t.Errorf("core test args = %v, want [-race]", core.Test.Args)
+	}
+}
+
+func TestLoad_Extends(t *testing.T) {
+	root := t.TempDir()
+	writeFile := func(name, data string) {
+		t.Helper()
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("policy/base.yml", `timeout: 10m
+concurrency: 4
+test:
+  args: [-race]
+  env:
+    set: {TZ: UTC}
+profiles:
+  ci:
+    test:
+      args: [-count=1]
+`)
+	writeFile("policy/strict.yml", `extends: base.yml
+staticcheck:
+  checks: [all]
+`)
+	writeFile("repo/go.mod", "module example.com/repo\n")
+	writeFile("repo/.governor", `extends:
+  - ../policy/strict.yml
+test:
+  args: [-short]
+  env:
+    set: {LANG: C}
+profiles:
+  ci:
+    timeout: 30m
+`)
+
+	res, err := Load(filepath.Join(root, "repo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := res.Config
+	if cfg.Timeout() != 10*time.Minute || cfg.Concurrency() != 4 {
+		t.Errorf("timeout, concurrency = %s, %d; want those of base.yml", cfg.Timeout(), cfg.Concurrency())
+	}
+	if !reflect.DeepEqual(cfg.Test.Args, []string{"-short"}) {
+		t.Errorf("test args = %v, want the list of .governor", cfg.Test.Args)
+	}
+	if want := map[string]string{"TZ": "UTC", "LANG": "C"}; !reflect.DeepEqual(cfg.Test.Exec.Env.Set, want) {
+		t.Errorf("env = %v, want %v", cfg.Test.Exec.Env.Set, want)
+	}
+	if !reflect.DeepEqual(cfg.Staticcheck.Checks, []string{"all"}) {
+		t.Errorf("checks = %v, want those of strict.yml", cfg.Staticcheck.Checks)
+	}
+	ci, err := cfg.WithProfile("ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ci.Timeout() != 30*time.Minute || !reflect.DeepEqual(ci.Test.Args, []string{"-count=1"}) {
+		t.Errorf("ci profile: timeout %s, args %v; want both files' settings", ci.Timeout(), ci.Test.Args)
+	}
+
+	base, strict := filepath.Join(root, "policy/base.yml"), filepath.Join(root, "policy/strict.yml")
+	if !reflect.DeepEqual(res.Extended, []string{base, strict}) {
+		t.Errorf("Extended = %q, want base.yml then strict.yml", res.Extended)
+	}
+	for key, want := range map[string]string{
+		"timeout":             base + ":1",
+		"test.args":           res.File + ":4",
+		"test.env.set.TZ":     base + ":6",
+		"test.env.set.LANG":   res.File + ":6",
+		"staticcheck.checks":  strict + ":3",
+		"profiles.ci.timeout": res.File + ":9",
+		"history.dir":         ":0",
+	} {
+		file, line := res.Position(key)
+		if got := fmt.Sprintf("%s:%d", file, line); got != want {
+			t.Errorf("Position(%s) = %s, want %s", key, got, want)
+		}
+	}
+
+	data, err := MarshalOrigins(cfg, func(key string) string { return "from " + key })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"timeout: 10m # from timeout", "args: # from test.args", "TZ: UTC # from test.env.set.TZ"} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("MarshalOrigins output lacks %q:\n%s", s, data)
+		}
+	}
+}
+
+func TestLoad_ExtendsDiamond(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":    "module example.com/repo\n",
+		".governor": "extends: [b.yml, c.yml]\n",
+		"b.yml":     "extends: d.yml\ntimeout: 10m\n",
+		"c.yml":     "extends: d.yml\nconcurrency: 2\n",
+		"d.yml":     "timeout: 1m\nconcurrency: 8\ngrace_period: 5s\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// d.yml applies once, before b.yml, which extends it first.
+	cfg := res.Config
+	if cfg.Timeout() != 10*time.Minute || cfg.Concurrency() != 2 || cfg.GracePeriod() != 5*time.Second {
+		t.Errorf("timeout, concurrency, grace period = %s, %d, %s; want 10m from b.yml, 2 from c.yml, 5s from d.yml",
+			cfg.Timeout(), cfg.Concurrency(), cfg.GracePeriod())
+	}
+	var extended []string
+	for _, file := range res.Extended {
+		extended = append(extended, filepath.Base(file))
+	}
+	if want := []string{"d.yml", "b.yml", "c.yml"}; !reflect.DeepEqual(extended, want) {
+		t.Errorf("Extended = %q, want %q", extended, want)
+	}
+	if file, line := res.Position("timeout"); filepath.Base(file) != "b.yml" || line != 2 {
+		t.Errorf("Position(timeout) = %s:%d, want b.yml:2", file, line)
+	}
+}
+
+func TestLoad_ExtendsProblems(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".governor": "extends: [a.yml, missing.yml, \"https://example.com/governor.yml\"]\n",
+		"a.yml":     "extends: b.yml\n",
+		"b.yml":     "test:\n  argz: [-race]\nextends: [a.yml]\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := Load(dir)
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Load error = %v, want a ValidationError", err)
+	}
+	var got []string
+	for _, p := range invalid.Problems {
+		got = append(got, fmt.Sprintf("%s:%d: %s: %s", filepath.Base(p.File), p.Line, p.Key, p.Message))
+	}
+	want := []string{
+		`.governor:1: extends[1]: missing.yml: no such file or directory`,
+		`.governor:1: extends[2]: only local files can be extended`,
+		`b.yml:2: test.argz: unknown setting, did you mean "args"?`,
+		`b.yml:3: extends[0]: extends cycle: .governor -> a.yml -> b.yml -> a.yml`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problems =\n%q\nwant\n%q", got, want)
+	}
+}
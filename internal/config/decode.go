@@ -0,0 +1,263 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decode parses a configuration file strictly. Besides syntax errors,
+// which are returned as an error, it reports settings Governor does not
+// know and values of the wrong type as problems, with their lines. root is
+// the parsed document, for locating settings later; it is nil for an empty
+// file.
+func decode(data []byte) (cfg *Config, root *yaml.Node, problems []Problem, err error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, nil, err
+	}
+	cfg = &Config{}
+	if len(doc.Content) == 0 {
+		return cfg, nil, nil, nil
+	}
+	root = doc.Content[0]
+
+	unknownKeys(root, reflect.TypeFor[Config](), "", &problems)
+	if err := root.Decode(cfg); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, nil, nil, err
+		}
+		for _, msg := range typeErr.Errors {
+			problems = append(problems, typeProblem(root, msg))
+		}
+	}
+	return cfg, root, problems, nil
+}
+
+// unknownKeys reports the keys of n, a value of type t at path, that do
+// not name a setting.
+func unknownKeys(n *yaml.Node, t reflect.Type, path string, problems *[]Problem) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Struct && n.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Tag == "!!merge" {
+				unknownKeys(v, t, path, problems)
+				continue
+			}
+			key := joinKey(path, k.Value)
+			f, ok := fields[k.Value]
+			if !ok {
+				msg := "unknown setting"
+				if s := suggest(k.Value, slices.Collect(maps.Keys(fields))); s != "" {
+					msg += fmt.Sprintf(", did you mean %q?", s)
+				}
+				*problems = append(*problems, Problem{Key: key, Line: k.Line, Message: msg})
+				continue
+			}
+			unknownKeys(v, f.Type, key, problems)
+		}
+	case t.Kind() == reflect.Map && n.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			unknownKeys(n.Content[i+1], t.Elem(), joinKey(path, n.Content[i].Value), problems)
+		}
+	case t.Kind() == reflect.Slice && n.Kind == yaml.SequenceNode:
+		for i, item := range n.Content {
+			unknownKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	}
+}
+
+// yamlFields returns the fields of struct type t by their YAML key,
+// including those of inlined structs.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		switch key, inline := yamlKey(f); {
+		case key == "":
+		case inline:
+			maps.Copy(fields, yamlFields(f.Type))
+		default:
+			fields[key] = f
+		}
+	}
+	return fields
+}
+
+// yamlKey returns the key of struct field f in YAML, or "" if it has none,
+// and whether its fields are inlined into those of the struct.
+func yamlKey(f reflect.StructField) (key string, inline bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	key, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	switch {
+	case key == "-":
+		return "", false
+	case strings.Contains(opts, "inline"):
+		return f.Name, true
+	case key == "":
+		key = strings.ToLower(f.Name)
+	}
+	return key, false
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// suggest returns the name in known closest to name, if it is close
+// enough to be a likely typo.
+func suggest(name string, known []string) string {
+	slices.Sort(known)
+	best, bestDist := "", min(3, len(name)/2+1)
+	for _, k := range known {
+		if d := editDistance(name, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+var (
+	typeErrorLine = regexp.MustCompile(`^line ([0-9]+): (.*)$`)
+	unmarshalErr  = regexp.MustCompile("^cannot unmarshal !!\\w+ `(.*)` into (.+)$")
+)
+
+// typeNames describes the Go types of settings in YAML terms.
+var typeNames = map[string]string{
+	"int":               "an integer",
+	"int64":             "an integer",
+	"string":            "a string",
+	"bool":              "a boolean",
+	"[]string":          "a list of strings",
+	"map[string]string": "a mapping of strings",
+}
+
+// typeProblem turns an error message of yaml.TypeError into a Problem for
+// the setting on its line.
+func typeProblem(root *yaml.Node, msg string) Problem {
+	var p Problem
+	if m := typeErrorLine.FindStringSubmatch(msg); m != nil {
+		p.Line, _ = strconv.Atoi(m[1])
+		msg = m[2]
+	}
+	p.Key = keyAt(root, "", p.Line)
+	p.Message = msg
+	if m := unmarshalErr.FindStringSubmatch(msg); m != nil {
+		want, ok := typeNames[m[2]]
+		if !ok {
+			want = "a mapping" // of a section, as in config.TestConfig
+		}
+		p.Message = fmt.Sprintf("%q is not %s", m[1], want)
+	}
+	return p
+}
+
+// keyAt returns the path of the setting whose value starts at line, or ""
+// if there is none.
+func keyAt(n *yaml.Node, path string, line int) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			key := joinKey(path, k.Value)
+			if found := keyAt(v, key, line); found != "" {
+				return found
+			}
+			if v.Line == line {
+				return key
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			key := fmt.Sprintf("%s[%d]", path, i)
+			if found := keyAt(item, key, line); found != "" {
+				return found
+			}
+			if item.Line == line {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+// lookup returns the node of the setting at path in n, such as
+// "audit.steps[1]", and the node of its key, or nil if it is absent.
+func lookup(n *yaml.Node, path string) (key, value *yaml.Node) {
+	if n == nil {
+		return nil, nil
+	}
+	for _, part := range strings.Split(path, ".") {
+		name, index, _ := strings.Cut(part, "[")
+		if n.Kind == yaml.AliasNode {
+			n = n.Alias
+		}
+		if n.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		var found bool
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == name {
+				key, n, found = n.Content[i], n.Content[i+1], true
+				break
+			}
+		}
+		if !found {
+			return nil, nil
+		}
+		for index != "" {
+			var rest string
+			index, rest, _ = strings.Cut(index, "]")
+			i, err := strconv.Atoi(index)
+			if err != nil || n.Kind != yaml.SequenceNode || i < 0 || i >= len(n.Content) {
+				return nil, nil
+			}
+			n = n.Content[i]
+			key = n
+			index = strings.TrimPrefix(rest, "[")
+		}
+	}
+	return key, n
+}
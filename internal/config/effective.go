@@ -0,0 +1,128 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Effective returns a copy of c with every setting that has a default set
+// to the value Governor uses, so that it shows the configuration runs
+// actually get. Step sections keep only what they override.
+func (c *Config) Effective() *Config {
+	e := *c
+	e.RawTimeout = c.Timeout().String()
+	e.RawGracePeriod = c.GracePeriod().String()
+	e.RawMaxOutput = c.MaxOutputBytes()
+	e.RawTailOutput = c.TailOutputBytes()
+	e.RawConcurrency = c.Concurrency()
+	e.Check.Steps = c.CheckSteps()
+	e.Audit.Steps = c.AuditSteps()
+	e.Audit.Complexity.Threshold = c.ComplexityThreshold()
+	e.Audit.Dupl.Threshold = c.DuplThreshold()
+	maxRuns, maxSize := c.HistoryMaxRuns(), c.HistoryMaxSize()
+	e.History.MaxRuns = &maxRuns
+	e.History.RawMaxAge = c.HistoryMaxAge().String()
+	e.History.MaxSize = &maxSize
+	e.Output.MarkdownLimit = c.MarkdownLimit()
+	return &e
+}
+
+// Marshal renders c as a .governor file, leaving out the settings that are
+// not set.
+func Marshal(c *Config) ([]byte, error) {
+	return MarshalOrigins(c, nil)
+}
+
+// MarshalOrigins renders c like Marshal, with a comment after each setting
+// saying where its value comes from, as origin returns for its key, such
+// as "test.args". Lists get one comment, after their key.
+func MarshalOrigins(c *Config, origin func(key string) string) ([]byte, error) {
+	var doc yaml.Node
+	if err := doc.Encode(c); err != nil {
+		return nil, err
+	}
+	prune(&doc)
+	if origin != nil {
+		annotate(&doc, "", origin)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// prune removes the settings of a mapping whose values are null, zero or
+// empty, and reports whether n itself is then empty.
+func prune(n *yaml.Node) (empty bool) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			prune(c)
+		}
+		return false
+	case yaml.MappingNode:
+		var kept []*yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if !prune(n.Content[i+1]) {
+				kept = append(kept, n.Content[i], n.Content[i+1])
+			}
+		}
+		n.Content = kept
+		return len(kept) == 0
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			prune(c)
+		}
+		return len(n.Content) == 0
+	case yaml.ScalarNode:
+		switch n.Tag {
+		case "!!null":
+			return true
+		case "!!str":
+			return n.Value == ""
+		case "!!int":
+			v, err := strconv.ParseInt(n.Value, 0, 64)
+			return err == nil && v == 0
+		}
+	}
+	return false
+}
+
+// annotate sets the comment of each setting in n, at path, to its origin.
+func annotate(n *yaml.Node, path string, origin func(key string) string) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			annotate(c, path, origin)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			key := joinKey(path, k.Value)
+			switch {
+			case v.Kind == yaml.MappingNode:
+				annotate(v, key, origin)
+			case v.Kind == yaml.SequenceNode && v.Content[0].Kind == yaml.MappingNode:
+				annotate(v, key, origin) // as in overrides
+			case v.Kind == yaml.SequenceNode:
+				k.LineComment = origin(key)
+			default:
+				v.LineComment = origin(key)
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			annotate(item, fmt.Sprintf("%s[%d]", path, i), origin)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"maps"
+	"time"
+)
+
+// ExecConfig controls the environment and resource limits of the commands
+// Governor runs. It appears at the top level of the configuration, applying
+// to every command, and in the section of each step, overriding the top
+// level for that step's commands.
+type ExecConfig struct {
+	Env    EnvConfig    `yaml:"env"`
+	Limits LimitsConfig `yaml:"limits"`
+}
+
+// EnvConfig controls the environment of executed commands. By default they
+// inherit the environment of the governor process.
+type EnvConfig struct {
+	// Allow, if set, restricts the inherited environment to these
+	// variables. A trailing "*" matches a prefix, as in "GO*".
+	Allow []string          `yaml:"allow"`
+	Unset []string          `yaml:"unset"` // variables removed from the inherited environment
+	Set   map[string]string `yaml:"set"`   // variables set for every command
+
+	// Shorthands for common Go settings.
+	GOFLAGS    string `yaml:"goflags"`
+	CgoEnabled *bool  `yaml:"cgo_enabled"`
+	GOTMPDIR   string `yaml:"gotmpdir"` // relative to the repository root; created if missing
+}
+
+// IsZero reports whether c leaves the environment unchanged.
+func (c EnvConfig) IsZero() bool {
+	return len(c.Allow) == 0 && len(c.Unset) == 0 && len(c.Set) == 0 &&
+		c.GOFLAGS == "" && c.CgoEnabled == nil && c.GOTMPDIR == ""
+}
+
+// LimitsConfig sets resource limits on each process started by a command,
+// including the test binaries started by go test. Limits are only
+// supported on Linux. Zero values are unlimited.
+type LimitsConfig struct {
+	RawCPU    string `yaml:"cpu"`        // CPU time, e.g. "10m"
+	Memory    int64  `yaml:"memory"`     // address space, in bytes
+	OpenFiles int    `yaml:"open_files"` // open file descriptors
+}
+
+// CPU returns the configured CPU time limit, or 0 if unlimited.
+func (c LimitsConfig) CPU() time.Duration {
+	d, err := time.ParseDuration(c.RawCPU)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// IsZero reports whether c sets no limits.
+func (c LimitsConfig) IsZero() bool {
+	return c.CPU() == 0 && c.Memory <= 0 && c.OpenFiles <= 0
+}
+
+// StepExec returns the environment and limits of the commands run by step:
+// the top-level settings, overridden by those of the step. Steps without a
+// section of their own, such as fix, use the top-level settings.
+func (c *Config) StepExec(step string) ExecConfig {
+	return c.Exec.merge(c.stepSection(step))
+}
+
+// stepSection returns the settings in the section of step alone.
+func (c *Config) stepSection(step string) ExecConfig {
+	switch step {
+	case "test":
+		return c.Test.Exec
+	case "lint":
+		return c.Lint.Exec
+	case "staticcheck":
+		return c.Staticcheck.Exec
+	case "coverage":
+		return c.Audit.Coverage.Exec
+	case "complexity":
+		return c.Audit.Complexity.Exec
+	case "deadcode":
+		return c.Audit.Deadcode.Exec
+	case "dupl":
+		return c.Audit.Dupl.Exec
+	case "vulncheck":
+		return c.Audit.Vulncheck.Exec
+	}
+	return ExecConfig{}
+}
+
+// merge returns c overridden by o. Variables set or unset by either apply;
+// other settings of o replace those of c when present.
+func (c ExecConfig) merge(o ExecConfig) ExecConfig {
+	r := c
+	if len(o.Env.Allow) > 0 {
+		r.Env.Allow = o.Env.Allow
+	}
+	r.Env.Unset = append(append([]string(nil), c.Env.Unset...), o.Env.Unset...)
+	if len(o.Env.Set) > 0 {
+		r.Env.Set = maps.Clone(c.Env.Set)
+		if r.Env.Set == nil {
+			r.Env.Set = make(map[string]string)
+		}
+		maps.Copy(r.Env.Set, o.Env.Set)
+	}
+	if o.Env.GOFLAGS != "" {
+		r.Env.GOFLAGS = o.Env.GOFLAGS
+	}
+	if o.Env.CgoEnabled != nil {
+		r.Env.CgoEnabled = o.Env.CgoEnabled
+	}
+	if o.Env.GOTMPDIR != "" {
+		r.Env.GOTMPDIR = o.Env.GOTMPDIR
+	}
+	if o.Limits.RawCPU != "" {
+		r.Limits.RawCPU = o.Limits.RawCPU
+	}
+	if o.Limits.Memory > 0 {
+		r.Limits.Memory = o.Limits.Memory
+	}
+	if o.Limits.OpenFiles > 0 {
+		r.Limits.OpenFiles = o.Limits.OpenFiles
+	}
+	return r
+}
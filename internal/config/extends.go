@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Extends lists the configuration files a .governor file builds on, such
+// as an organisation-wide policy. In YAML it is a path or a list of paths,
+// relative to the file naming them.
+type Extends []string
+
+// UnmarshalYAML accepts a single path as well as a list.
+func (e *Extends) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		var path string
+		if err := n.Decode(&path); err != nil {
+			return err
+		}
+		*e = Extends{path}
+		return nil
+	}
+	return n.Decode((*[]string)(e))
+}
+
+// source is a configuration file that was read, for merging and locating
+// its settings.
+type source struct {
+	file string
+	cfg  *Config    // its own settings
+	root *yaml.Node // nil for an empty file
+}
+
+// loadFile decodes the configuration file at path and the files it
+// extends, and returns their settings merged: those of the extended files
+// in order, then those of path, each overlaid on the previous ones as
+// described by overlay. The sources are returned in the same order.
+// chain lists the files extending path, path last, for detecting cycles.
+// loaded records the files read so far, so that a file extended more than
+// once, such as a policy two extended files build on, applies once, where
+// it is first extended. Problems with an extended file, such as one that
+// cannot be read, are reported at the extends entry naming it; only a
+// syntax error in path itself is returned as an error.
+func loadFile(path string, data []byte, chain []string, loaded map[string]bool) (*Config, []source, []Problem, error) {
+	loaded[path] = true
+
+	own, root, problems, err := decode(data)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for i := range problems {
+		problems[i].File = path
+	}
+
+	var sources []source
+	for i, ext := range own.Extends {
+		key := fmt.Sprintf("extends[%d]", i)
+		k, _ := lookup(root, key)
+		if k == nil { // a single path
+			key = "extends"
+			k, _ = lookup(root, key)
+		}
+		add := func(format string, args ...any) {
+			p := Problem{File: path, Key: key, Message: fmt.Sprintf(format, args...)}
+			if k != nil {
+				p.Line = k.Line
+			}
+			problems = append(problems, p)
+		}
+
+		if strings.Contains(ext, "://") {
+			add("only local files can be extended")
+			continue
+		}
+		base := ext
+		if !filepath.IsAbs(base) {
+			base = filepath.Join(filepath.Dir(path), base)
+		}
+		base = filepath.Clean(base)
+		if slices.Contains(chain, base) {
+			add("extends cycle: %s", cyclePath(append(slices.Clip(chain), base)))
+			continue
+		}
+		if loaded[base] {
+			continue
+		}
+
+		data, err := os.ReadFile(base)
+		if err != nil {
+			var pathErr *fs.PathError
+			if errors.As(err, &pathErr) {
+				err = pathErr.Err
+			}
+			add("%s: %v", ext, err)
+			continue
+		}
+		_, baseSources, baseProblems, err := loadFile(base, data, append(slices.Clip(chain), base), loaded)
+		if err != nil {
+			add("%s: %v", ext, err)
+			continue
+		}
+		sources = append(sources, baseSources...)
+		problems = append(problems, baseProblems...)
+	}
+	sources = append(sources, source{file: path, cfg: own, root: root})
+
+	cfg := &Config{}
+	for _, s := range sources {
+		if s.root != nil {
+			overlay(reflect.ValueOf(cfg).Elem(), reflect.ValueOf(s.cfg).Elem(), s.root)
+		}
+	}
+	cfg.Extends = own.Extends
+	return cfg, sources, problems, nil
+}
+
+// cyclePath describes a cycle of extended files, relative to the
+// directory of the first.
+func cyclePath(chain []string) string {
+	dir := filepath.Dir(chain[0])
+	names := make([]string, len(chain))
+	for i, file := range chain {
+		names[i] = file
+		if rel, err := filepath.Rel(dir, file); err == nil {
+			names[i] = rel
+		}
+	}
+	return strings.Join(names, " -> ")
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Override adjusts the settings of the packages matching one of its
+// patterns, such as generated or legacy code. Its sections have the same
+// settings as those of the top level, and replace them as described by
+// overlay.
+type Override struct {
+	// Packages are patterns of the package directories the override
+	// applies to, relative to the module root, as in "internal/pb/...".
+	// "*" matches within a path element and "..." any string, so that
+	// "old/..." matches old and every package below it.
+	Packages []string `yaml:"packages"`
+
+	Test        TestConfig        `yaml:"test"`
+	Lint        LintConfig        `yaml:"lint"`
+	Staticcheck StaticcheckConfig `yaml:"staticcheck"`
+	Check       CheckConfig       `yaml:"check"`
+	Audit       AuditConfig       `yaml:"audit"`
+
+	node *yaml.Node // as read, for telling settings given empty from missing ones
+}
+
+// UnmarshalYAML decodes o and keeps the node it was read from.
+func (o *Override) UnmarshalYAML(n *yaml.Node) error {
+	type plain Override
+	err := n.Decode((*plain)(o))
+	o.node = n
+	return err
+}
+
+// config returns the settings of o as a Config.
+func (o Override) config() *Config {
+	return &Config{Test: o.Test, Lint: o.Lint, Staticcheck: o.Staticcheck, Check: o.Check, Audit: o.Audit}
+}
+
+// Matches reports whether o applies to the package in directory dir,
+// relative to the module root ("." for the root package).
+func (o Override) Matches(dir string) bool {
+	for _, p := range o.Packages {
+		if MatchPackage(p, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPackage reports whether the package directory dir, relative to the
+// module root, matches pattern, as described by Override.Packages.
+func MatchPackage(pattern, dir string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	dir = strings.TrimPrefix(dir, "./")
+	if pattern == "" || pattern == "." {
+		return dir == "." || dir == ""
+	}
+	re := regexp.QuoteMeta(pattern)
+	re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+	re = strings.ReplaceAll(re, `\*`, `[^/]*`)
+	re = strings.ReplaceAll(re, `\?`, `[^/]`)
+	if rest, ok := strings.CutSuffix(re, `/.*`); ok {
+		re = rest + `(/.*)?` // as in go list, x/... matches x too
+	}
+	ok, _ := regexp.MatchString("^"+re+"$", dir)
+	return ok
+}
+
+// ForPackage returns the settings of the package in directory dir,
+// relative to the module root: c with the overrides matching dir applied
+// in order, so that later ones win. It also returns the indexes of those
+// overrides, and c itself when there are none.
+func (c *Config) ForPackage(dir string) (*Config, []int) {
+	var matched []int
+	for i, o := range c.Overrides {
+		if o.Matches(dir) {
+			matched = append(matched, i)
+		}
+	}
+	return c.withOverrides(matched), matched
+}
+
+// Variants returns c and c with each of its overrides applied alone, then
+// the Variants of each of its profiles, for finding every setting that
+// some package may run with.
+func (c *Config) Variants() []*Config {
+	variants := []*Config{c}
+	for i := range c.Overrides {
+		variants = append(variants, c.withOverrides([]int{i}))
+	}
+	for _, name := range c.ProfileNames() {
+		p, _ := c.WithProfile(name)
+		variants = append(variants, p.Variants()...)
+	}
+	return variants
+}
+
+func (c *Config) withOverrides(indexes []int) *Config {
+	if len(indexes) == 0 {
+		return c
+	}
+	r := *c
+	for _, i := range indexes {
+		o := c.Overrides[i]
+		overlay(reflect.ValueOf(&r).Elem(), reflect.ValueOf(o.config()).Elem(), o.node)
+	}
+	return &r
+}
+
+// overlay sets the settings of dst that are set in src, which must have
+// the same type, as read from n. A setting is set if n gives it, even as
+// an empty list, zero or null, so that "args: []" removes the arguments of
+// dst and "threshold: 0" resets a threshold. Lists and other values
+// replace those of dst; mappings add their entries to those of dst,
+// replacing the entries with the same key, or overlaying them if they are
+// sections such as profiles. The lists and mappings of dst are replaced
+// rather than modified.
+//
+// If n is nil, as for settings built in Go rather than read from a file,
+// the lists and mappings of src that are not empty and its other settings
+// that are not zero are set.
+func overlay(dst, src reflect.Value, n *yaml.Node) {
+	if n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			key, inline := yamlKey(src.Type().Field(i))
+			switch {
+			case key == "":
+			case n == nil || inline:
+				overlay(dst.Field(i), src.Field(i), n)
+			default:
+				if v := mappingValue(n, key); v != nil {
+					overlay(dst.Field(i), src.Field(i), v)
+				}
+			}
+		}
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), dst.Len()+src.Len())
+		iter := dst.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), iter.Value())
+		}
+		iter = src.MapRange()
+		for iter.Next() {
+			v := iter.Value()
+			if old := m.MapIndex(iter.Key()); old.IsValid() && v.Kind() == reflect.Struct {
+				merged := reflect.New(v.Type()).Elem()
+				merged.Set(old)
+				var entry *yaml.Node
+				if n != nil {
+					entry = mappingValue(n, iter.Key().String())
+				}
+				overlay(merged, v, entry)
+				if k, ok := merged.Addr().Interface().(nodeKeeper); ok {
+					k.keepNode(mergeNodes(k.keptNode(), entry))
+				}
+				v = merged
+			}
+			m.SetMapIndex(iter.Key(), v)
+		}
+		dst.Set(m)
+	case reflect.Slice:
+		switch {
+		case src.IsNil() && n != nil:
+			dst.SetZero()
+		case src.Len() > 0 || n != nil:
+			dst.Set(reflect.AppendSlice(reflect.MakeSlice(src.Type(), 0, src.Len()), src))
+		}
+	default:
+		if n != nil || !src.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
+
+// nodeKeeper is implemented by the sections that keep the node they were
+// read from, such as profiles, so that a section merged from several files
+// gives the settings of each.
+type nodeKeeper interface {
+	keptNode() *yaml.Node
+	keepNode(n *yaml.Node)
+}
+
+// mergeNodes returns a node giving the settings of both a and b, those of
+// b winning. Either may be nil.
+func mergeNodes(a, b *yaml.Node) *yaml.Node {
+	if a != nil && a.Kind == yaml.AliasNode {
+		a = a.Alias
+	}
+	if b != nil && b.Kind == yaml.AliasNode {
+		b = b.Alias
+	}
+	if a == nil || b == nil || a.Kind != yaml.MappingNode || b.Kind != yaml.MappingNode {
+		if b == nil {
+			return a
+		}
+		return b
+	}
+	m := &yaml.Node{Kind: yaml.MappingNode, Tag: b.Tag, Line: b.Line, Column: b.Column}
+	for i := 0; i+1 < len(a.Content); i += 2 {
+		if mappingValue(b, a.Content[i].Value) == nil {
+			m.Content = append(m.Content, a.Content[i], a.Content[i+1])
+		}
+	}
+	for i := 0; i+1 < len(b.Content); i += 2 {
+		k, v := b.Content[i], b.Content[i+1]
+		if old := mappingValue(a, k.Value); old != nil {
+			v = mergeNodes(old, v)
+		}
+		m.Content = append(m.Content, k, v)
+	}
+	return m
+}
+
+// mappingValue returns the value of key in the mapping n, or nil if n is
+// not a mapping or has no such key. Keys merged in with "<<" count too.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	var merged *yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		switch {
+		case k.Tag == "!!merge":
+			if m := mappingValue(v, key); m != nil && merged == nil {
+				merged = m
+			}
+		case k.Value == key:
+			return v
+		}
+	}
+	return merged
+}
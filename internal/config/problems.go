@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Problem is a setting that cannot take effect.
+type Problem struct {
+	File    string `json:"file,omitempty"` // configuration file, when known
+	Line    int    `json:"line,omitempty"` // line of the setting in File
+	Key     string `json:"key,omitempty"`  // path of the setting, as in "audit.steps[1]"
+	Message string `json:"message"`
+}
+
+// String formats p as "file:line: key: message", leaving out what is
+// unknown.
+func (p Problem) String() string {
+	s := p.Message
+	if p.Key != "" {
+		s = p.Key + ": " + s
+	}
+	switch {
+	case p.File != "" && p.Line > 0:
+		s = fmt.Sprintf("%s:%d: %s", p.File, p.Line, s)
+	case p.File != "":
+		s = p.File + ": " + s
+	case p.Line > 0:
+		s = fmt.Sprintf("line %d: %s", p.Line, s)
+	}
+	return s
+}
+
+// ValidationError is returned by Load for a configuration file with
+// problems.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	if len(lines) == 1 {
+		return "invalid configuration: " + lines[0]
+	}
+	return "invalid configuration:\n" + strings.Join(lines, "\n")
+}
+
+// Problems returns the settings that cannot take effect: unknown step
+// names, durations that do not parse, negative sizes or thresholds,
+// overrides without packages and profiles with invalid names, in c and
+// in each of its profiles. Load refuses a file with problems; a Config
+// built otherwise runs with such settings replaced by their defaults, or
+// fails the step they name.
+func (c *Config) Problems() []Problem {
+	problems := c.problems("")
+	for _, name := range c.ProfileNames() {
+		prefix := "profiles." + name + "."
+		if !profileName.MatchString(name) {
+			problems = append(problems, Problem{Key: "profiles." + name, Message: "invalid profile name: use letters, digits, - and _"})
+			continue
+		}
+		problems = append(problems, c.Profiles[name].Config().problems(prefix)...)
+	}
+	return problems
+}
+
+// problems returns the Problems of c and its overrides, with the keys of
+// their settings prefixed by prefix.
+func (c *Config) problems(prefix string) []Problem {
+	var problems []Problem
+	add := func(key, format string, args ...any) {
+		problems = append(problems, Problem{Key: prefix + key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for i, o := range c.Overrides {
+		key := fmt.Sprintf("overrides[%d].", i)
+		if len(o.Packages) == 0 {
+			add(key+"packages", "no package patterns")
+		}
+		problems = append(problems, o.config().problems(prefix+key)...)
+	}
+
+	for i, step := range c.Check.Steps {
+		if !slices.Contains(DefaultCheckSteps, step) {
+			add(fmt.Sprintf("check.steps[%d]", i), "unknown step %q (known: %s)", step, strings.Join(DefaultCheckSteps, ", "))
+		}
+	}
+	for i, step := range c.Audit.Steps {
+		if !slices.Contains(DefaultAuditSteps, step) {
+			add(fmt.Sprintf("audit.steps[%d]", i), "unknown step %q (known: %s)", step, strings.Join(DefaultAuditSteps, ", "))
+		}
+	}
+
+	durations := []stringSetting{
+		{"timeout", c.RawTimeout},
+		{"grace_period", c.RawGracePeriod},
+		{"limits.cpu", c.Exec.Limits.RawCPU},
+	}
+	numbers := []intSetting{
+		{"max_output", int64(c.RawMaxOutput)},
+		{"tail_output", int64(c.RawTailOutput)},
+		{"concurrency", int64(c.RawConcurrency)},
+		{"limits.memory", c.Exec.Limits.Memory},
+		{"limits.open_files", int64(c.Exec.Limits.OpenFiles)},
+		{"audit.complexity.threshold", int64(c.Audit.Complexity.Threshold)},
+		{"audit.dupl.threshold", int64(c.Audit.Dupl.Threshold)},
+		{"history.max_runs", int64(valueOr(c.History.MaxRuns, 0))},
+		{"history.max_size", valueOr(c.History.MaxSize, 0)},
+		{"output.markdown_limit", int64(c.Output.MarkdownLimit)},
+	}
+	for _, step := range append(slices.Clone(DefaultCheckSteps), DefaultAuditSteps...) {
+		limits := c.stepSection(step).Limits
+		durations = append(durations, stringSetting{stepKey(step) + ".limits.cpu", limits.RawCPU})
+		numbers = append(numbers,
+			intSetting{stepKey(step) + ".limits.memory", limits.Memory},
+			intSetting{stepKey(step) + ".limits.open_files", int64(limits.OpenFiles)},
+		)
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		if v, err := time.ParseDuration(d.value); err != nil || v <= 0 {
+			add(d.key, "%q is not a positive duration (e.g. 30s, 5m)", d.value)
+		}
+	}
+	if age := c.History.RawMaxAge; age != "" {
+		// 0 lifts the limit, so unlike the other durations it is valid.
+		if v, err := time.ParseDuration(age); err != nil || v < 0 {
+			add("history.max_age", "%q is not a duration (e.g. 720h) or 0", age)
+		}
+	}
+	for _, n := range numbers {
+		if n.value < 0 {
+			add(n.key, "%d is negative", n.value)
+		}
+	}
+
+	if c.RawTailOutput > c.MaxOutputBytes() {
+		add("tail_output", "%d is more than max_output (%d)", c.RawTailOutput, c.MaxOutputBytes())
+	}
+	return problems
+}
+
+// stringSetting and intSetting pair a value with its key, for checking
+// settings alike.
+type (
+	stringSetting struct{ key, value string }
+	intSetting    struct {
+		key   string
+		value int64
+	}
+)
+
+// valueOr returns the value p points to, or def if p is nil.
+func valueOr[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
+// stepKey returns the key of the section of step in the configuration.
+func stepKey(step string) string {
+	if slices.Contains(DefaultAuditSteps, step) {
+		return "audit." + step
+	}
+	return step
+}
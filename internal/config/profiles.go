@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileEnv is the environment variable naming the profile to apply when
+// none is given on the command line.
+const ProfileEnv = "GOVERNOR_PROFILE"
+
+// profileName matches the names a profile can have, so that its settings
+// have keys such as "profiles.ci.test.args".
+var profileName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Profile is a named set of settings applied over the top level on
+// request, such as a quick local run or a thorough CI run. Its settings
+// replace those of the top level as described by overlay. Where runs are
+// stored cannot change by profile, so that every run shares the history.
+type Profile struct {
+	RawTimeout     string            `yaml:"timeout"`
+	RawGracePeriod string            `yaml:"grace_period"`
+	RawMaxOutput   int               `yaml:"max_output"`
+	RawTailOutput  int               `yaml:"tail_output"`
+	RawConcurrency int               `yaml:"concurrency"`
+	Test           TestConfig        `yaml:"test"`
+	Lint           LintConfig        `yaml:"lint"`
+	Staticcheck    StaticcheckConfig `yaml:"staticcheck"`
+	Check          CheckConfig       `yaml:"check"`
+	Audit          AuditConfig       `yaml:"audit"`
+	Output         OutputConfig      `yaml:"output"`
+	Tools          map[string]string `yaml:"tools"`
+	Overrides      []Override        `yaml:"overrides"`
+	Exec           ExecConfig        `yaml:",inline"`
+
+	node *yaml.Node // as read, for telling settings given empty from missing ones
+}
+
+// UnmarshalYAML decodes p and keeps the node it was read from.
+func (p *Profile) UnmarshalYAML(n *yaml.Node) error {
+	type plain Profile
+	err := n.Decode((*plain)(p))
+	p.node = n
+	return err
+}
+
+func (p *Profile) keptNode() *yaml.Node  { return p.node }
+func (p *Profile) keepNode(n *yaml.Node) { p.node = n }
+
+// Config returns the settings of p as a Config, for checking them alone.
+func (p Profile) Config() *Config {
+	return &Config{
+		RawTimeout:     p.RawTimeout,
+		RawGracePeriod: p.RawGracePeriod,
+		RawMaxOutput:   p.RawMaxOutput,
+		RawTailOutput:  p.RawTailOutput,
+		RawConcurrency: p.RawConcurrency,
+		Test:           p.Test,
+		Lint:           p.Lint,
+		Staticcheck:    p.Staticcheck,
+		Check:          p.Check,
+		Audit:          p.Audit,
+		Output:         p.Output,
+		Tools:          p.Tools,
+		Overrides:      p.Overrides,
+		Exec:           p.Exec,
+	}
+}
+
+// ProfileNames returns the names of the profiles of c, sorted.
+func (c *Config) ProfileNames() []string {
+	return slices.Sorted(maps.Keys(c.Profiles))
+}
+
+// WithProfile returns the settings of c with its profile name applied, and
+// without profiles of their own. It returns c itself if name is empty, and
+// an error if c has no such profile.
+func (c *Config) WithProfile(name string) (*Config, error) {
+	if name == "" {
+		return c, nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile %q: the configuration has no profiles", name)
+		}
+		return nil, fmt.Errorf("unknown profile %q (profiles: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	r := *c
+	overlay(reflect.ValueOf(&r).Elem(), reflect.ValueOf(p.Config()).Elem(), p.node)
+	r.Profiles = nil
+	return &r, nil
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// SchemaID is where the JSON Schema of the configuration is published,
+// for editors to complete and check .governor files.
+const SchemaID = "https://raw.githubusercontent.com/deixis/governor/main/governor.schema.json"
+
+// durationPattern matches the durations time.ParseDuration accepts.
+const durationPattern = `^([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$`
+
+// descriptions documents the settings in the schema, by the Go type of
+// their section and their key.
+var descriptions = map[string]string{
+	"Config.version":      "Version of the configuration format.",
+	"Config.extends":      "Configuration files whose settings this file builds on, relative to it: a path or a list of paths. Their settings apply first, in order, then those of this file.",
+	"Config.timeout":      "Time limit of each command, e.g. 5m. Default: 5m.",
+	"Config.grace_period": "Time a timed-out or cancelled command has to exit after SIGTERM before it is killed. Default: 5s.",
+	"Config.max_output":   "Bytes of each output stream kept in memory. Default: 1048576.",
+	"Config.tail_output":  "Bytes of max_output kept from the end of the output. Default: half of max_output.",
+	"Config.concurrency":  "Commands one governor process runs at once. Default: 2.",
+	"Config.test":         "How gov_test runs go test.",
+	"Config.lint":         "How golangci-lint runs.",
+	"Config.staticcheck":  "How staticcheck runs.",
+	"Config.check":        "The steps of gov_check.",
+	"Config.audit":        "The steps of gov_audit and their settings.",
+	"Config.history":      "Where runs are stored and how long they are kept.",
+	"Config.output":       "How runs are rendered for other tools.",
+	"Config.tools":        "Required tool versions by name: an exact version such as v0.6.1, or comma-separated comparisons such as \">=v1.60.0, <v2.0.0\".",
+	"Config.overrides":    "Settings of the packages matching some patterns, replacing those of the top level. Later overrides win over earlier ones.",
+	"Config.profiles":     "Named settings applied over the others with -profile, GOVERNOR_PROFILE or the profile parameter of gov_check and gov_audit.",
+
+	"Profile.timeout":      "Time limit of each command, e.g. 5m.",
+	"Profile.grace_period": "Time a timed-out or cancelled command has to exit after SIGTERM before it is killed.",
+	"Profile.max_output":   "Bytes of each output stream kept in memory.",
+	"Profile.tail_output":  "Bytes of max_output kept from the end of the output.",
+	"Profile.concurrency":  "Commands one governor process runs at once.",
+	"Profile.test":         "How gov_test runs go test with this profile.",
+	"Profile.lint":         "How golangci-lint runs with this profile.",
+	"Profile.staticcheck":  "How staticcheck runs with this profile.",
+	"Profile.check":        "The steps of gov_check with this profile.",
+	"Profile.audit":        "The steps of gov_audit and their settings with this profile.",
+	"Profile.output":       "How runs are rendered for other tools with this profile.",
+	"Profile.tools":        "Required tool versions by name, added to those of the top level.",
+	"Profile.overrides":    "Settings of the packages matching some patterns with this profile, replacing the overrides of the top level.",
+
+	"Override.packages":    "Patterns of package directories relative to the module root, e.g. internal/pb/...; * matches within a path element and ... any string.",
+	"Override.test":        "How go test runs for these packages.",
+	"Override.lint":        "How golangci-lint runs for these packages.",
+	"Override.staticcheck": "How staticcheck runs for these packages.",
+	"Override.check":       "The steps of gov_check for these packages.",
+	"Override.audit":       "The steps of gov_audit and their settings for these packages.",
+
+	"ExecConfig.env":    "Environment of the commands. Default: the environment of the governor process.",
+	"ExecConfig.limits": "Resource limits of each process a command starts (Linux only).",
+
+	"EnvConfig.allow":       "Inherited variables to keep; a trailing * matches a prefix. Default: all of them.",
+	"EnvConfig.unset":       "Variables removed from the inherited environment.",
+	"EnvConfig.set":         "Variables set for every command.",
+	"EnvConfig.goflags":     "GOFLAGS of every command.",
+	"EnvConfig.cgo_enabled": "CGO_ENABLED of every command.",
+	"EnvConfig.gotmpdir":    "GOTMPDIR of every command, relative to the repository root; created if missing.",
+
+	"LimitsConfig.cpu":        "CPU time, e.g. 10m.",
+	"LimitsConfig.memory":     "Address space, in bytes.",
+	"LimitsConfig.open_files": "Open file descriptors.",
+
+	"TestConfig.args":          "Extra flags of go test, e.g. -race.",
+	"LintConfig.config":        "Path of the golangci-lint configuration file.",
+	"LintConfig.args":          "Extra flags of golangci-lint.",
+	"StaticcheckConfig.args":   "Extra flags of staticcheck.",
+	"StaticcheckConfig.checks": "Checks to enable or, with a leading -, disable, e.g. [all, -ST1000].",
+
+	"AuditConfig.coverage":   "How go test -coverprofile runs.",
+	"AuditConfig.complexity": "How gocognit runs.",
+	"AuditConfig.deadcode":   "How deadcode runs.",
+	"AuditConfig.dupl":       "How dupl runs.",
+	"AuditConfig.vulncheck":  "How govulncheck runs.",
+
+	"CheckConfig.steps": "Steps of gov_check, in order. Default: [test, lint, staticcheck].",
+	"AuditConfig.steps": "Steps of gov_audit, in order. Default: [coverage, complexity, deadcode, dupl, vulncheck].",
+
+	"CoverageConfig.args":        "Extra flags of go test -coverprofile.",
+	"ComplexityConfig.threshold": "Cognitive complexity above which functions are reported. Default: 15.",
+	"ComplexityConfig.args":      "Extra flags of gocognit.",
+	"DeadcodeConfig.args":        "Extra flags of deadcode.",
+	"DuplConfig.threshold":       "Minimum token length of duplicates. Default: 50.",
+	"DuplConfig.args":            "Extra flags of dupl.",
+	"VulncheckConfig.args":       "Extra flags of govulncheck.",
+
+	"HistoryConfig.dir":      "Directory of stored runs, relative to the repository root. Default: the user cache directory, keyed by repository.",
+	"HistoryConfig.max_runs": "Maximum number of stored runs, or 0 for no limit. Default: 200.",
+	"HistoryConfig.max_age":  "Age after which runs are pruned, e.g. 720h, or 0 for no limit. Default: 2160h.",
+	"HistoryConfig.max_size": "Total bytes of stored runs, or 0 for no limit. Default: 268435456.",
+
+	"OutputConfig.repo_url":       "URL under which the module's files are browsable, with {commit} replaced by the run's commit; links findings in Markdown summaries.",
+	"OutputConfig.markdown_limit": "Maximum size of Markdown summaries, in bytes. Default: 65536.",
+}
+
+// stepEnums restricts the items of step lists, by section and key.
+var stepEnums = map[string][]string{
+	"CheckConfig.steps": DefaultCheckSteps,
+	"AuditConfig.steps": DefaultAuditSteps,
+}
+
+// Schema returns the JSON Schema of the configuration file. It is derived
+// from Config, so that it cannot fall behind the settings Load accepts.
+func Schema() *jsonschema.Schema {
+	s := schemaFor(reflect.TypeFor[Config]())
+	s.Schema = "https://json-schema.org/draft/2020-12/schema"
+	s.ID = SchemaID
+	s.Title = "Governor configuration"
+	s.Description = "The .governor file at the root of a Go module."
+	return s
+}
+
+// SchemaJSON returns Schema as the indented JSON of the published file.
+func SchemaJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(Schema()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// schemaFor returns the schema of a setting of type t.
+func schemaFor(t reflect.Type) *jsonschema.Schema {
+	s := &jsonschema.Schema{}
+	if t == reflect.TypeFor[Extends]() {
+		s.AnyOf = []*jsonschema.Schema{{Type: "string"}, {Type: "array", Items: &jsonschema.Schema{Type: "string"}}}
+		return s
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.Struct:
+		s.Type = "object"
+		s.Properties = make(map[string]*jsonschema.Schema)
+		s.AdditionalProperties = &jsonschema.Schema{Not: &jsonschema.Schema{}} // false
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			key, inline := yamlKey(f)
+			switch {
+			case key == "":
+				continue
+			case inline:
+				maps.Copy(s.Properties, schemaFor(f.Type).Properties)
+				continue
+			}
+			fs := schemaFor(f.Type)
+			fs.Description = descriptions[t.Name()+"."+key]
+			for _, step := range stepEnums[t.Name()+"."+key] {
+				fs.Items.Enum = append(fs.Items.Enum, step)
+			}
+			if strings.HasPrefix(f.Name, "Raw") && f.Type.Kind() == reflect.String {
+				fs.Pattern = durationPattern
+			}
+			if fs.Type == "integer" {
+				fs.Minimum = new(float64)
+			}
+			s.Properties[key] = fs
+		}
+	case reflect.Map:
+		s.Type = "object"
+		s.AdditionalProperties = schemaFor(t.Elem())
+	case reflect.Slice:
+		s.Type = "array"
+		s.Items = schemaFor(t.Elem())
+	case reflect.String:
+		s.Type = "string"
+	case reflect.Bool:
+		s.Type = "boolean"
+	case reflect.Int, reflect.Int64:
+		s.Type = "integer"
+	}
+	return s
+}
@@ -0,0 +1,24 @@
+//go:build !unix
+
+// Package flock takes advisory locks on open files, through which
+// governor processes sharing a workspace or run history exclude each
+// other.
+package flock
+
+import "os"
+
+// Lock does not lock: advisory file locks are only supported on Unix.
+// Callers still exclude each other within a process.
+func Lock(*os.File, bool) error {
+	return nil
+}
+
+// TryLock does not lock, and always succeeds.
+func TryLock(*os.File, bool) (bool, error) {
+	return true, nil
+}
+
+// Unlock does nothing.
+func Unlock(*os.File) error {
+	return nil
+}
@@ -0,0 +1,40 @@
+//go:build unix
+
+// Package flock takes advisory locks on open files, through which
+// governor processes sharing a workspace or run history exclude each
+// other.
+package flock
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Lock takes a shared or exclusive lock on f, blocking until it is
+// available.
+func Lock(f *os.File, exclusive bool) error {
+	return syscall.Flock(int(f.Fd()), how(exclusive))
+}
+
+// TryLock takes a shared or exclusive lock on f, and reports false if
+// another open file holds a conflicting one.
+func TryLock(f *os.File, exclusive bool) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), how(exclusive)|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Unlock releases the lock held on f.
+func Unlock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
+
+func how(exclusive bool) int {
+	if exclusive {
+		return syscall.LOCK_EX
+	}
+	return syscall.LOCK_SH
+}
@@ -0,0 +1,43 @@
+//go:build unix
+
+package flock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTryLock_Conflicts(t *testing.T) {
+	// Two opens of the same file stand for two processes.
+	path := filepath.Join(t.TempDir(), "lock")
+	open := func() *os.File {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = f.Close() })
+		return f
+	}
+	f1, f2 := open(), open()
+
+	if err := Lock(f1, false); err != nil {
+		t.Fatalf("Lock shared: %v", err)
+	}
+	if ok, err := TryLock(f2, false); !ok || err != nil {
+		t.Errorf("TryLock shared = %v, %v; want true, nil", ok, err)
+	}
+	if err := Unlock(f2); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if ok, err := TryLock(f2, true); ok || err != nil {
+		t.Errorf("TryLock exclusive while shared = %v, %v; want false, nil", ok, err)
+	}
+
+	if err := Unlock(f1); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if ok, err := TryLock(f2, true); !ok || err != nil {
+		t.Errorf("TryLock exclusive after unlock = %v, %v; want true, nil", ok, err)
+	}
+}
@@ -11,16 +11,21 @@ import (
 
 type auditParams struct {
 	Packages []string `json:"packages,omitempty" jsonschema:"Go import paths of packages to analyse (e.g. example.com/foo/bar/...) or absolute directory paths. Defaults to all packages in the workspace."`
+	Profile  string   `json:"profile,omitempty" jsonschema:"Configuration profile to apply, from the profiles of the .governor file (e.g. quick, ci). Default: the server's profile, if any."`
 }
 
 func (h *handler) auditHandler(ctx context.Context, req *mcp.CallToolRequest, params auditParams) (*mcp.CallToolResult, any, error) {
-	result, err := h.engine.Audit(ctx, params.Packages)
+	eng, store, err := h.engineFor(params.Profile)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	result, err := eng.Audit(withProgress(ctx, req), params.Packages)
 	if err != nil {
 		return errorResult(fmt.Sprintf("audit failed: %v", err))
 	}
 
 	// Save results for gov_inspect.
-	_ = h.store.Save(result.RunResult)
+	_ = store.Save(result.RunResult)
 
 	return textResult(formatAudit(result.RunResult.ID, result.Steps))
 }
@@ -47,8 +52,8 @@ func formatAudit(runID string, results []workflow.AuditStepResult) string {
 			fmt.Fprintln(&b)
 		case "unavailable":
 			fmt.Fprintf(&b, "%s: unavailable (%s)\n\n", r.Name, r.Detail)
-		case "error":
-			fmt.Fprintf(&b, "%s: error (%s)\n\n", r.Name, r.Detail)
+		case "error", "timeout", "cancelled":
+			fmt.Fprintf(&b, "%s: %s (%s)\n\n", r.Name, r.Status, r.Detail)
 		case "skipped":
 			fmt.Fprintf(&b, "%s: skipped\n\n", r.Name)
 		}
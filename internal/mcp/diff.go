@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deixis/governor/internal/report"
+	"github.com/deixis/governor/internal/workflow"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type diffParams struct {
+	OldRunID string `json:"old_run_id" jsonschema:"the run ID of the earlier gov_check or gov_audit run"`
+	NewRunID string `json:"new_run_id" jsonschema:"the run ID of the later run of the same kind"`
+}
+
+func (h *handler) diffHandler(ctx context.Context, req *mcp.CallToolRequest, params diffParams) (*mcp.CallToolResult, any, error) {
+	if params.OldRunID == "" || params.NewRunID == "" {
+		return errorResult("old_run_id and new_run_id are required")
+	}
+
+	eng, store := h.current()
+	oldRun, err := store.Load(params.OldRunID)
+	if err != nil {
+		return errorResult(fmt.Sprintf("Failed to load run %s: %v", params.OldRunID, err))
+	}
+	newRun, err := store.Load(params.NewRunID)
+	if err != nil {
+		return errorResult(fmt.Sprintf("Failed to load run %s: %v", params.NewRunID, err))
+	}
+
+	d, err := report.Compare(oldRun, newRun, eng.Config.ComplexityThreshold())
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	return textResult(workflow.FormatDiff(d))
+}
@@ -0,0 +1,22 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/deixis/governor/internal/workflow"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type doctorParams struct {
+	Refresh bool `json:"refresh,omitempty" jsonschema:"look tools up again rather than using the cached results. Default: false."`
+}
+
+func (h *handler) doctorHandler(ctx context.Context, req *mcp.CallToolRequest, params doctorParams) (*mcp.CallToolResult, any, error) {
+	eng, _ := h.current()
+	if params.Refresh {
+		if err := eng.RefreshTools(); err != nil {
+			return errorResult(err.Error())
+		}
+	}
+	return textResult(workflow.FormatDiagnosis(eng.Doctor(ctx)))
+}
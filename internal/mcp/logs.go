@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/deixis/governor/internal/report"
+	"github.com/deixis/governor/internal/workflow"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type logsParams struct {
+	RunID string `json:"run_id" jsonschema:"the run ID from gov_check or gov_audit output"`
+	Step  string `json:"step" jsonschema:"the step whose log to read (e.g. test, lint, coverage)"`
+	From  int    `json:"from,omitempty" jsonschema:"first line to return, from 1; negative counts from the end (-50 returns the last 50 lines). Default: 1."`
+	Lines int    `json:"lines,omitempty" jsonschema:"maximum number of lines to return. Default: 200."`
+}
+
+func (h *handler) logsHandler(ctx context.Context, req *mcp.CallToolRequest, params logsParams) (*mcp.CallToolResult, any, error) {
+	if params.RunID == "" || params.Step == "" {
+		return errorResult("run_id and step are required")
+	}
+
+	_, store := h.current()
+	rr, err := store.Load(params.RunID)
+	if err != nil {
+		return errorResult(fmt.Sprintf("Failed to load run %s: %v", params.RunID, err))
+	}
+	path, err := rr.StepLog(params.Step)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	lines := params.Lines
+	if lines <= 0 {
+		lines = workflow.DefaultLogLines
+	}
+	page, err := report.ReadLog(path, params.From, lines)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	var b strings.Builder
+	b.WriteString(workflow.FormatLogPage(params.Step, page))
+	if page.To() < page.Total {
+		fmt.Fprintf(&b, "\nMore: gov_logs(run_id=%q, step=%q, from=%d)\n", rr.ID, params.Step, page.To()+1)
+	}
+	return textResult(b.String())
+}
+
+// stepHasLog reports whether step of rr kept its complete output.
+func stepHasLog(rr *report.RunResult, step string) bool {
+	_, err := rr.StepLog(step)
+	return err == nil
+}
@@ -6,6 +6,8 @@ import (
 	"context"
 	_ "embed"
 	"net/url"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/deixis/governor"
@@ -19,12 +21,21 @@ import (
 //go:embed instructions.md
 var Instructions string
 
+// storeCacheSize is the number of runs kept in memory in front of the
+// run history.
+const storeCacheSize = 5
+
 // handler holds shared dependencies for all tool handlers.
 type handler struct {
+	mu     sync.Mutex // guards engine, runner, store and queues, replaced by updateWorkspaceFromRoots
 	engine *workflow.Engine
-	runner *runner.Runner // retained for workspace handler and updateWorkspaceFromRoots
+	runner *runner.Runner // set up for the server's profile
 	store  report.Store
-	gopls  *goplsProxy // nil if gopls is not available
+	queues map[string]*runner.Queue // by profile, for calls naming another than the server's
+
+	gopls       *goplsProxy // nil if gopls is not available
+	openHistory HistoryOpener
+	profile     string // applied to check and audit runs that name no profile
 }
 
 // NewServer creates an MCP server with all Governor tools registered.
@@ -46,6 +57,13 @@ func NewServer(cfg *config.Config, r *runner.Runner, store report.Store, workspa
 		o(&so)
 	}
 	h.gopls = so.gopls
+	h.engine.LogDir = so.logDir
+	h.engine.Tools = so.tools
+	h.profile = so.profile
+	h.openHistory = so.openHistory
+	if so.runner != nil {
+		h.engine.Runner = so.runner
+	}
 
 	mcpOpts := &mcp.ServerOptions{
 		Instructions: Instructions,
@@ -88,6 +106,32 @@ Symbol can be an import path (e.g. example.com/foo) for all diagnostics in a pac
 or importpath.Symbol (e.g. example.com/foo.TestAdd) for a specific function.`,
 	}, h.inspectHandler)
 
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "gov_diff",
+		Description: `Compare two gov_check or gov_audit runs of the same kind.
+
+Use this after iterating on a change to see what was fixed and what broke.
+Reports new, resolved and unchanged findings per source, plus metric deltas
+(coverage, complexity, vulnerabilities, issue counts).`,
+	}, h.diffHandler)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "gov_logs",
+		Description: `Read the complete output of a step of a gov_check or gov_audit run, a page of lines at a time.
+
+Step output in tool results is cut to its beginning and end when it is long; use this when the
+part you need was left out. Pass a negative from to read from the end (from=-50 for the last 50 lines).`,
+	}, h.logsHandler)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "gov_doctor",
+		Description: `Check that the tools the configured check and audit steps need are installed.
+
+Reports each tool's source (go.mod tool directive or PATH), version and whether it is recent enough,
+the Go toolchain, and configuration problems. Use this when a step is unavailable or the setup changed;
+pass refresh=true to look tools up again after installing or moving one.`,
+	}, h.doctorHandler)
+
 	// Register static gopls proxy tools. Each tool returns an actionable
 	// error when gopls is not installed, rather than silently disappearing.
 	registerGoplsTools(s, h)
@@ -99,9 +143,18 @@ or importpath.Symbol (e.g. example.com/foo.TestAdd) for a specific function.`,
 type ServerOption func(*serverOptions)
 
 type serverOptions struct {
-	gopls *goplsProxy
+	gopls       *goplsProxy
+	logDir      string
+	runner      workflow.CommandRunner
+	tools       *workflow.ToolCache
+	openHistory HistoryOpener
+	profile     string
 }
 
+// HistoryOpener opens the run history of the repository at repoRoot,
+// configured by cfg.
+type HistoryOpener func(cfg *config.Config, repoRoot string) (*report.DiskStore, error)
+
 // WithGoplsProxy attaches a gopls proxy to the server.
 func WithGoplsProxy(p *goplsProxy) ServerOption {
 	return func(o *serverOptions) {
@@ -109,9 +162,53 @@ func WithGoplsProxy(p *goplsProxy) ServerOption {
 	}
 }
 
+// WithLogDir makes runs keep the complete output of their steps in dir,
+// for gov_logs.
+func WithLogDir(dir string) ServerOption {
+	return func(o *serverOptions) {
+		o.logDir = dir
+	}
+}
+
+// WithToolCache makes the server resolve tools with c, so that they are
+// looked up once rather than on every call.
+func WithToolCache(c *workflow.ToolCache) ServerOption {
+	return func(o *serverOptions) {
+		o.tools = c
+	}
+}
+
+// WithHistory makes the server open the run history of the workspace the
+// client names in its roots with open, along with the step logs and tool
+// cache kept next to it. Without it, the server keeps the store it was
+// created with whatever the workspace.
+func WithHistory(open HistoryOpener) ServerOption {
+	return func(o *serverOptions) {
+		o.openHistory = open
+	}
+}
+
+// WithProfile makes check and audit runs apply the profile name of the
+// configuration unless the call names another.
+func WithProfile(name string) ServerOption {
+	return func(o *serverOptions) {
+		o.profile = name
+	}
+}
+
+// WithCommandRunner makes check and audit runs execute their commands
+// with r rather than the server's runner.Runner, e.g. to replay recorded
+// commands in tests.
+func WithCommandRunner(r workflow.CommandRunner) ServerOption {
+	return func(o *serverOptions) {
+		o.runner = r
+	}
+}
+
 // updateWorkspaceFromRoots queries the client for MCP roots and updates the
-// handler's engine, runner, and config if a valid root is returned.
-// This is called during session initialization, before any tool calls.
+// handler's engine, runner, config and run history if a valid root is
+// returned. This is called during session initialization, before any tool
+// calls.
 func (h *handler) updateWorkspaceFromRoots(ctx context.Context, session *mcp.ServerSession) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -134,16 +231,89 @@ func (h *handler) updateWorkspaceFromRoots(ctx context.Context, session *mcp.Ser
 	if err != nil {
 		return
 	}
+	runCfg, err := loaded.Config.WithProfile(h.profile)
+	if err != nil {
+		return
+	}
+
+	lock, _ := runCfg.WorkspaceLock(loaded.RepoRoot)
+	r := &runner.Runner{
+		Workspace:   workspace,
+		Timeout:     runCfg.Timeout(),
+		MaxOutput:   runCfg.MaxOutputBytes(),
+		TailOutput:  runCfg.TailOutputBytes(),
+		GracePeriod: runCfg.GracePeriod(),
+		Queue:       runner.NewQueue(runCfg.Concurrency(), lock),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	eng := *h.engine
+	if eng.Runner == workflow.CommandRunner(h.runner) {
+		eng.Runner = r
+	}
+	eng.Config = loaded.Config
+	eng.Workspace = workspace
+	eng.RepoRoot = loaded.RepoRoot
+	store := h.store
+	if h.openHistory != nil {
+		disk, err := h.openHistory(loaded.Config, loaded.RepoRoot)
+		if err != nil {
+			return
+		}
+		store = report.NewLRUStore(storeCacheSize, disk)
+		eng.LogDir = report.LogDir(disk.Dir())
+		eng.Tools = workflow.NewToolCache(filepath.Join(disk.Dir(), workflow.ToolCacheFile))
+	}
+
+	h.engine, h.runner, h.store = &eng, r, store
+	h.queues = nil
+}
+
+// current returns the engine and run store of the workspace.
+func (h *handler) current() (*workflow.Engine, report.Store) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.engine, h.store
+}
 
-	// Update runner.
-	h.runner.Workspace = workspace
-	h.runner.Timeout = loaded.Config.Timeout()
-	h.runner.MaxOutput = loaded.Config.MaxOutputBytes()
+// engineFor returns the engine for a check or audit run applying profile,
+// or the server's profile if it is empty, and the store to save it in. The
+// commands of a run applying another profile than the server's run with
+// the timeout, output limits and concurrency of that profile, in a queue
+// of their own that shares the workspace lock.
+func (h *handler) engineFor(profile string) (*workflow.Engine, report.Store, error) {
+	if profile == "" {
+		profile = h.profile
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	// Update engine.
-	h.engine.Config = loaded.Config
-	h.engine.Workspace = workspace
-	h.engine.RepoRoot = loaded.RepoRoot
+	eng, err := h.engine.WithProfile(profile)
+	if err != nil || profile == h.profile || eng.Runner != workflow.CommandRunner(h.runner) {
+		return eng, h.store, err
+	}
+	cfg := eng.Config
+	r := *h.runner
+	r.Timeout = cfg.Timeout()
+	r.MaxOutput = cfg.MaxOutputBytes()
+	r.TailOutput = cfg.TailOutputBytes()
+	r.GracePeriod = cfg.GracePeriod()
+	if r.Queue != nil {
+		q, ok := h.queues[profile]
+		if !ok {
+			lock, _ := cfg.WorkspaceLock(eng.RepoRoot)
+			q = runner.NewQueue(cfg.Concurrency(), lock)
+			if h.queues == nil {
+				h.queues = make(map[string]*runner.Queue)
+			}
+			h.queues[profile] = q
+		}
+		r.Queue = q
+	}
+	eng.Runner = &r
+	return eng, h.store, nil
 }
 
 // textResult is a helper to build a text-only tool result.
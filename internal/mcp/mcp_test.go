@@ -5,10 +5,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/replay"
 	"github.com/deixis/governor/internal/report"
 	"github.com/deixis/governor/internal/runner"
 	"github.com/deixis/governor/internal/workflow"
@@ -19,7 +21,12 @@ import (
 // workspaceDir should be a prepared fixture directory.
 func setup(t *testing.T, workspaceDir string, cfgOverride *config.Config) *mcp.ClientSession {
 	t.Helper()
-	ctx := context.Background()
+	return setupClient(t, workspaceDir, cfgOverride, nil)
+}
+
+// setupClient is like setup, with client and server options.
+func setupClient(t *testing.T, workspaceDir string, cfgOverride *config.Config, opts *mcp.ClientOptions, serverOpts ...ServerOption) *mcp.ClientSession {
+	t.Helper()
 
 	var cfg *config.Config
 	if cfgOverride != nil {
@@ -34,14 +41,23 @@ func setup(t *testing.T, workspaceDir string, cfgOverride *config.Config) *mcp.C
 		}
 	}
 
-	store := report.NewLRUStore(5, report.NewDiskStore())
+	disk := report.NewDiskStore(t.TempDir(), report.Retention{})
+	store := report.NewLRUStore(5, disk)
 	r := &runner.Runner{
 		Workspace: workspaceDir,
 		Timeout:   30 * time.Second,
 		MaxOutput: cfg.MaxOutputBytes(),
 	}
 
-	server := NewServer(cfg, r, store, workspaceDir)
+	serverOpts = append([]ServerOption{WithLogDir(report.LogDir(disk.Dir()))}, serverOpts...)
+	server := NewServer(cfg, r, store, workspaceDir, serverOpts...)
+	return connect(t, server, mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, opts))
+}
+
+// connect connects client to server over in-memory transports.
+func connect(t *testing.T, server *mcp.Server, client *mcp.Client) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
 
 	ct, st := mcp.NewInMemoryTransports()
 	ss, err := server.Connect(ctx, st, nil)
@@ -49,7 +65,6 @@ func setup(t *testing.T, workspaceDir string, cfgOverride *config.Config) *mcp.C
 		t.Fatalf("server.Connect: %v", err)
 	}
 
-	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
 	cs, err := client.Connect(ctx, ct, nil)
 	if err != nil {
 		t.Fatalf("client.Connect: %v", err)
@@ -153,6 +168,101 @@ func TestGovCheck_Passing(t *testing.T) {
 	}
 }
 
+func TestGovCheck_Replay(t *testing.T) {
+	dir := copyFixture(t, "passing")
+	cfg := &config.Config{
+		Check: config.CheckConfig{Steps: []string{"test", "lint"}},
+	}
+	r := &runner.Runner{Workspace: dir, Timeout: 30 * time.Second, MaxOutput: cfg.MaxOutputBytes()}
+	replayed := replay.ForTest(t, filepath.Join("testdata", "replay", "check.json"), dir, r, workflow.ResolveTool)
+	cs := setupClient(t, dir, cfg, nil, WithCommandRunner(replayed))
+
+	text := resultText(callTool(t, cs, "gov_check", map[string]any{"fix": false}))
+	for _, want := range []string{"test: pass", "lint: fail", "1 lint issues", "Status: FAIL"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestGovCheck_Progress(t *testing.T) {
+	dir := copyFixture(t, "failing")
+	cfg := &config.Config{
+		Check: config.CheckConfig{Steps: []string{"test"}},
+	}
+	var (
+		mu       sync.Mutex
+		progress []*mcp.ProgressNotificationParams
+	)
+	cs := setupClient(t, dir, cfg, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			progress = append(progress, req.Params)
+			mu.Unlock()
+		},
+	})
+
+	params := &mcp.CallToolParams{
+		// SetProgressToken drops the token when Meta is nil.
+		Meta:      mcp.Meta{"progressToken": "check-1"},
+		Name:      "gov_check",
+		Arguments: map[string]any{"fix": false},
+	}
+	if _, err := cs.CallTool(context.Background(), params); err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+
+	// Notifications are delivered asynchronously: wait for the last one.
+	// format and test each start and finish, and one package completes.
+	const want = 5
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(progress)
+		mu.Unlock()
+		if n >= want || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(progress) != want {
+		t.Fatalf("got %d progress notifications, want %d", len(progress), want)
+	}
+
+	var last float64
+	for _, p := range progress {
+		if p.ProgressToken != "check-1" {
+			t.Errorf("progress token = %v, want check-1", p.ProgressToken)
+		}
+		if p.Total != 2 {
+			t.Errorf("total = %v, want 2", p.Total)
+		}
+		if p.Progress < last {
+			t.Errorf("progress went from %v to %v", last, p.Progress)
+		}
+		last = p.Progress
+	}
+	if last != 2 {
+		t.Errorf("final progress = %v, want 2", last)
+	}
+	for i, want := range []string{
+		"format: started",
+		"format: pass in ",
+		"test: started",
+		"test: fail testfailing (1/1 packages, 1 tests passed, 1 failed)",
+		"test: fail in ",
+	} {
+		if !strings.HasPrefix(progress[i].Message, want) {
+			t.Errorf("message %d = %q, want prefix %q", i, progress[i].Message, want)
+		}
+	}
+	if msg := progress[4].Message; !strings.Contains(msg, "[2/2 steps, 1 passed, 1 failed, ") {
+		t.Errorf("final message %q lacks step counts", msg)
+	}
+}
+
 func TestGovCheck_TestFailure(t *testing.T) {
 	dir := copyFixture(t, "failing")
 	cfg := &config.Config{
@@ -196,6 +306,46 @@ func TestGovCheck_UnknownStep(t *testing.T) {
 	}
 }
 
+func TestGovCheck_Profile(t *testing.T) {
+	dir := copyFixture(t, "passing")
+	cfg := &config.Config{
+		Check: config.CheckConfig{Steps: []string{"test", "build"}},
+		Profiles: map[string]config.Profile{
+			"quick": {Check: config.CheckConfig{Steps: []string{"test"}}},
+		},
+	}
+
+	cs := setup(t, dir, cfg)
+	text := resultText(callTool(t, cs, "gov_check", map[string]any{"fix": false, "profile": "quick"}))
+	if !strings.Contains(text, "Status: PASS") {
+		t.Errorf("expected the quick profile's steps to pass, got:\n%s", text)
+	}
+	res := callTool(t, cs, "gov_check", map[string]any{"profile": "slow"})
+	if text := resultText(res); !res.IsError || !strings.Contains(text, `unknown profile "slow" (profiles: quick)`) {
+		t.Errorf("expected an unknown profile error, got:\n%s", text)
+	}
+
+	// A profile's command settings apply to the calls naming it.
+	cfg.Profiles["hasty"] = config.Profile{RawTimeout: "1ms", Check: config.CheckConfig{Steps: []string{"test"}}}
+	r := &runner.Runner{Workspace: dir, Timeout: 30 * time.Second, Queue: runner.NewQueue(2, "")}
+	server := NewServer(cfg, r, report.NewDiskStore(t.TempDir(), report.Retention{}), dir)
+	hasty := connect(t, server, mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil))
+	text = resultText(callTool(t, hasty, "gov_check", map[string]any{"fix": false, "profile": "hasty"}))
+	if !strings.Contains(text, "timed out") {
+		t.Errorf("expected the hasty profile's timeout to apply, got:\n%s", text)
+	}
+	text = resultText(callTool(t, hasty, "gov_check", map[string]any{"fix": false, "profile": "quick"}))
+	if !strings.Contains(text, "Status: PASS") {
+		t.Errorf("expected the server's timeout with the quick profile, got:\n%s", text)
+	}
+
+	cs = setupClient(t, dir, cfg, nil, WithProfile("quick"))
+	text = resultText(callTool(t, cs, "gov_check", map[string]any{"fix": false}))
+	if !strings.Contains(text, "Status: PASS") {
+		t.Errorf("expected the server's profile to apply, got:\n%s", text)
+	}
+}
+
 func TestGovCheck_BuildError(t *testing.T) {
 	dir := copyFixture(t, "builderror")
 	cfg := &config.Config{
@@ -306,3 +456,142 @@ func TestGovCheck_WithLint(t *testing.T) {
 		t.Errorf("expected test step to pass, got:\n%s", text)
 	}
 }
+
+// --- gov_diff ---
+
+func TestGovDiff_SameFailureUnchanged(t *testing.T) {
+	dir := copyFixture(t, "failing")
+	cfg := &config.Config{
+		Check: config.CheckConfig{Steps: []string{"test"}},
+	}
+	cs := setup(t, dir, cfg)
+
+	first := runIDFromText(t, resultText(callTool(t, cs, "gov_check", nil)))
+	second := runIDFromText(t, resultText(callTool(t, cs, "gov_check", nil)))
+
+	res := callTool(t, cs, "gov_diff", map[string]any{
+		"old_run_id": first,
+		"new_run_id": second,
+	})
+	text := resultText(res)
+	if res.IsError {
+		t.Fatalf("unexpected error from gov_diff: %s", text)
+	}
+	if !strings.Contains(text, "0 new, 0 resolved") {
+		t.Errorf("expected no new or resolved findings, got:\n%s", text)
+	}
+	if !strings.Contains(text, "test_failures") {
+		t.Errorf("expected metric deltas, got:\n%s", text)
+	}
+}
+
+func TestGovLogs_AfterFailingCheck(t *testing.T) {
+	dir := copyFixture(t, "failing")
+	cfg := &config.Config{
+		Check: config.CheckConfig{Steps: []string{"test"}},
+	}
+	cs := setup(t, dir, cfg)
+
+	text := resultText(callTool(t, cs, "gov_check", nil))
+	runID := runIDFromText(t, text)
+	if !strings.Contains(text, "gov_logs(") {
+		t.Errorf("expected gov_logs hint, got:\n%s", text)
+	}
+
+	res := callTool(t, cs, "gov_logs", map[string]any{"run_id": runID, "step": "test", "lines": 1})
+	text = resultText(res)
+	if res.IsError {
+		t.Fatalf("unexpected error from gov_logs: %s", text)
+	}
+	if !strings.Contains(text, "lines 1-1 of") || !strings.Contains(text, "$ go test -json") {
+		t.Errorf("expected the first line of the log, got:\n%s", text)
+	}
+	if !strings.Contains(text, "from=2") {
+		t.Errorf("expected a next page hint, got:\n%s", text)
+	}
+
+	res = callTool(t, cs, "gov_logs", map[string]any{"run_id": runID, "step": "test", "from": -5})
+	if text := resultText(res); res.IsError || !strings.Contains(text, `"Action":"fail"`) {
+		t.Errorf("expected the end of the test output, got:\n%s", text)
+	}
+
+	res = callTool(t, cs, "gov_logs", map[string]any{"run_id": runID, "step": "lint"})
+	if !res.IsError {
+		t.Errorf("expected an error for a step that did not run, got:\n%s", resultText(res))
+	}
+}
+
+func runIDFromText(t *testing.T, text string) string {
+	t.Helper()
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "Run: ") {
+			return strings.TrimPrefix(line, "Run: ")
+		}
+	}
+	t.Fatalf("no Run ID found in output:\n%s", text)
+	return ""
+}
+
+func TestGovDoctor(t *testing.T) {
+	dir := copyFixture(t, "passing")
+	cfg := &config.Config{
+		Check: config.CheckConfig{Steps: []string{"test", "tests"}},
+		Audit: config.AuditConfig{Steps: []string{"coverage"}},
+	}
+	cs := setup(t, dir, cfg)
+	text := resultText(callTool(t, cs, "gov_doctor", map[string]any{"refresh": true}))
+	for _, want := range []string{"module:    testpassing", "gofumpt", `unknown step "tests"`, "Status: FAIL, 1 config problem(s)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, text)
+		}
+	}
+}
+
+// --- roots ---
+
+func TestRoots_SwitchHistory(t *testing.T) {
+	launch := t.TempDir()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".governor"), []byte("history:\n  dir: runs\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A run stored earlier in the root's history, e.g. by the CLI.
+	history := report.NewDiskStore(filepath.Join(root, "runs"), report.Retention{})
+	run := &report.RunResult{
+		ID:         "root-run",
+		Kind:       report.Check,
+		LintIssues: []report.LintIssue{{Package: "example.com/root", File: "a.go", Line: 1, Linter: "errcheck", Message: "unchecked error"}},
+	}
+	if err := history.Save(run); err != nil {
+		t.Fatal(err)
+	}
+
+	open := func(cfg *config.Config, repoRoot string) (*report.DiskStore, error) {
+		dir, err := cfg.HistoryDir(repoRoot)
+		if err != nil {
+			return nil, err
+		}
+		return report.NewDiskStore(dir, report.Retention{}), nil
+	}
+	launchStore := report.NewDiskStore(t.TempDir(), report.Retention{})
+	server := NewServer(&config.Config{}, &runner.Runner{Workspace: launch}, launchStore, launch, WithHistory(open))
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	client.AddRoots(&mcp.Root{URI: "file://" + root})
+	cs := connect(t, server, client)
+
+	// The roots are fetched once the session is initialised.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		res := callTool(t, cs, "gov_inspect", map[string]any{"run_id": "root-run", "symbol": "example.com/root"})
+		if !res.IsError {
+			if text := resultText(res); !strings.Contains(text, "unchecked error") {
+				t.Errorf("inspect = %q, want the run from the root's history", text)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("run from the root's history not found: %s", resultText(res))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deixis/governor/internal/workflow"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// withProgress returns a context that reports the progress of the run
+// started by req to the client, when the client asked for progress by
+// sending a progress token. Progress counts finished steps, with the test
+// step advancing as packages complete, out of the number of steps.
+func withProgress(ctx context.Context, req *mcp.CallToolRequest) context.Context {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return ctx
+	}
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return ctx
+	}
+
+	var (
+		mu   sync.Mutex
+		last float64
+	)
+	return workflow.WithObserver(ctx, func(ev workflow.Event) {
+		// Events of steps running at once arrive concurrently; sending
+		// under the lock keeps notifications in the order of their values.
+		mu.Lock()
+		defer mu.Unlock()
+		// Progress must never decrease, even if packages outnumber the
+		// packages the run resolved to.
+		last = max(last, progressValue(ev))
+		p := &mcp.ProgressNotificationParams{
+			ProgressToken: token,
+			Message:       progressMessage(ev),
+			Progress:      last,
+			Total:         float64(ev.Steps),
+		}
+		if err := req.Session.NotifyProgress(ctx, p); err != nil {
+			log.Printf("progress notification: %v", err)
+		}
+	})
+}
+
+// progressValue returns the progress of the run at ev, in steps.
+func progressValue(ev workflow.Event) float64 {
+	switch ev.Kind {
+	case workflow.EventStepFinished:
+		return float64(ev.Index + 1)
+	case workflow.EventPackageDone:
+		if ev.PackagesTotal > 0 {
+			// Stay short of the step's end until it has finished.
+			return float64(ev.Index) + min(float64(ev.PackagesDone)/float64(ev.PackagesTotal), 0.99)
+		}
+	}
+	return float64(ev.Index)
+}
+
+// progressMessage describes ev for the client, e.g.
+// "test: ok example.com/foo (2/5 packages, 12 tests passed, 1 failed) [3/6 steps, 2 passed, 0 failed, 4.1s]".
+func progressMessage(ev workflow.Event) string {
+	var b strings.Builder
+	switch ev.Kind {
+	case workflow.EventStepStarted:
+		fmt.Fprintf(&b, "%s: started", ev.Step)
+	case workflow.EventWaiting:
+		step := ev.Step
+		if step == "" {
+			step = "run"
+		}
+		if ev.Waiting != "" {
+			fmt.Fprintf(&b, "%s: waiting, %s", step, ev.Waiting)
+		} else {
+			fmt.Fprintf(&b, "%s: resumed", step)
+		}
+	case workflow.EventStepFinished:
+		fmt.Fprintf(&b, "%s: %s in %s", ev.Step, ev.Status, ev.StepElapsed.Round(time.Millisecond))
+	case workflow.EventPackageDone:
+		fmt.Fprintf(&b, "%s: %s %s (%d", ev.Step, ev.Status, ev.Package, ev.PackagesDone)
+		if ev.PackagesTotal > 0 {
+			fmt.Fprintf(&b, "/%d", ev.PackagesTotal)
+		}
+		fmt.Fprintf(&b, " packages, %d tests passed, %d failed)", ev.TestsPassed, ev.TestsFailed)
+	}
+	fmt.Fprintf(&b, " [%d/%d steps, %d passed, %d failed, %s]",
+		ev.StepsPassed+ev.StepsFailed, ev.Steps, ev.StepsPassed, ev.StepsFailed, ev.Elapsed.Round(100*time.Millisecond))
+	return b.String()
+}
@@ -13,6 +13,7 @@ import (
 type checkParams struct {
 	Packages []string `json:"packages,omitempty" jsonschema:"Go import paths of packages to check (e.g. example.com/foo/bar/...) or absolute directory paths. Defaults to all packages in the workspace."`
 	Fix      *bool    `json:"fix,omitempty" jsonschema:"Run auto-fix phase (gofumpt, golangci-lint --fix) before checks. Default: true."`
+	Profile  string   `json:"profile,omitempty" jsonschema:"Configuration profile to apply, from the profiles of the .governor file (e.g. quick, ci). Default: the server's profile, if any."`
 }
 
 func (h *handler) checkHandler(ctx context.Context, req *mcp.CallToolRequest, params checkParams) (*mcp.CallToolResult, any, error) {
@@ -22,13 +23,17 @@ func (h *handler) checkHandler(ctx context.Context, req *mcp.CallToolRequest, pa
 		fix = *params.Fix
 	}
 
-	result, err := h.engine.Check(ctx, params.Packages, fix)
+	eng, store, err := h.engineFor(params.Profile)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	result, err := eng.Check(withProgress(ctx, req), params.Packages, fix)
 	if err != nil {
 		return errorResult(fmt.Sprintf("check failed: %v", err))
 	}
 
 	// Save results for gov_inspect.
-	_ = h.store.Save(result.RunResult)
+	_ = store.Save(result.RunResult)
 
 	// Format failure before steps ran (format issues with fix=false).
 	if result.FailedIdx == -2 {
@@ -57,8 +62,8 @@ func formatCheck(runID string, rr *report.RunResult, results []workflow.StepResu
 
 	fmt.Fprintln(&b, "Steps:")
 	for _, r := range results {
-		if r.Status == "unavailable" {
-			fmt.Fprintf(&b, "  %s: unavailable (%s)\n", r.Name, r.Detail)
+		if r.Detail != "" {
+			fmt.Fprintf(&b, "  %s: %s (%s)\n", r.Name, r.Status, r.Detail)
 		} else {
 			fmt.Fprintf(&b, "  %s: %s\n", r.Name, r.Status)
 		}
@@ -82,11 +87,19 @@ func formatCheck(runID string, rr *report.RunResult, results []workflow.StepResu
 			fmt.Fprintln(&b)
 		}
 
-		if failed.Status == "unavailable" {
+		switch failed.Status {
+		case "unavailable":
 			fmt.Fprintf(&b, "Action: %s is required but not installed. Install it and re-run gov_check.\n", failed.Name)
-		} else {
+		case "timeout":
+			fmt.Fprintf(&b, "Action: %s timed out. Scope gov_check to the packages you changed, or ask the user to raise the timeout.\n", failed.Name)
+		case "cancelled":
+			fmt.Fprintln(&b, "Action: the run was cancelled before it finished. Re-run gov_check if results are still needed.")
+		default:
 			fmt.Fprintf(&b, "Inspect with gov_inspect(run_id=%q, symbol=\"<package or package.Symbol>\").\n", runID)
 		}
+		if stepHasLog(rr, failed.Name) {
+			fmt.Fprintf(&b, "Full log: gov_logs(run_id=%q, step=%q, from=-%d).\n", runID, failed.Name, workflow.DefaultLogLines)
+		}
 	} else {
 		fmt.Fprintln(&b, "All check steps passed.")
 	}
@@ -13,6 +13,7 @@ type workspaceParams struct{}
 
 func (h *handler) workspaceHandler(ctx context.Context, req *sdkmcp.CallToolRequest, _ workspaceParams) (*sdkmcp.CallToolResult, any, error) {
 	var b strings.Builder
+	eng, _ := h.current()
 
 	// If gopls is available, merge its go_workspace output first.
 	// This provides richer information (view type, diagnostics status, etc.).
@@ -27,7 +28,7 @@ func (h *handler) workspaceHandler(ctx context.Context, req *sdkmcp.CallToolRequ
 	}
 
 	// Module info via `go list -m -json`.
-	modResult, err := h.engine.Runner.Run(ctx, []string{"go", "list", "-m", "-json"}, "")
+	modResult, err := eng.Runner.Run(ctx, []string{"go", "list", "-m", "-json"}, "")
 	if err != nil {
 		return errorResult(fmt.Sprintf("Failed to query module info: %v", err))
 	}
@@ -48,7 +49,7 @@ func (h *handler) workspaceHandler(ctx context.Context, req *sdkmcp.CallToolRequ
 	fmt.Fprintln(&b)
 
 	// Package list via `go list ./...`.
-	pkgResult, err := h.engine.Runner.Run(ctx, []string{"go", "list", "./..."}, "")
+	pkgResult, err := eng.Runner.Run(ctx, []string{"go", "list", "./..."}, "")
 	if err != nil {
 		// Non-fatal: we still have module info.
 		fmt.Fprintln(&b, "Packages: (failed to list)")
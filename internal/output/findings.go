@@ -0,0 +1,175 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+// Finding levels, from most to least severe. They follow SARIF's levels.
+const (
+	levelError   = "error"
+	levelWarning = "warning"
+	levelNote    = "note"
+)
+
+// rule describes the check behind a finding.
+type rule struct {
+	ID      string
+	Desc    string
+	HelpURI string
+}
+
+// span is a file region. A zero Line covers the whole file.
+type span struct {
+	File    string // relative to the module root, slash-separated
+	Line    int
+	Col     int
+	EndLine int
+	EndCol  int
+}
+
+// finding is a diagnostic enriched with what reporting formats need: a
+// rule, a severity level, a precise location and a stable fingerprint.
+type finding struct {
+	Source      string
+	Package     string
+	Rule        rule
+	Level       string
+	Message     string
+	Loc         span
+	Logical     string // Go-qualified symbol or package, if known
+	Related     *span  // second block of a duplicate pair
+	Properties  map[string]any
+	Fingerprint string // report.Fingerprint of the underlying diagnostic
+}
+
+// findings converts the diagnostics of r to findings, in the order of
+// report.Diagnostics. Coverage entries become findings only for functions
+// without any coverage, and complexity entries only above the threshold.
+//
+// It walks report.Diagnostics so that fingerprints match run diffs,
+// pairing each diagnostic with the entry it was built from: Diagnostics
+// emits exactly one diagnostic per entry, in entry order.
+func findings(r *report.RunResult, opts Options) []finding {
+	var out []finding
+	next := make(map[string]int)
+
+	for _, d := range report.Diagnostics(r) {
+		i := next[d.Source]
+		next[d.Source]++
+
+		f := finding{
+			Source:      d.Source,
+			Package:     d.Package,
+			Message:     d.Message,
+			Fingerprint: report.Fingerprint(d),
+		}
+		switch d.Source {
+		case "format":
+			f.Rule = rule{ID: "unformatted-file", Desc: "File is not formatted with gofumpt"}
+			f.Level = levelWarning
+			f.Loc = span{File: relPath(r, d.File)}
+		case "build":
+			f.Rule = rule{ID: "build-error", Desc: "Package does not compile"}
+			f.Level = levelError
+			f.Loc = span{File: relPath(r, d.File), Line: d.Line, Col: d.Col}
+			f.Logical = d.Package
+		case "test":
+			f.Rule = rule{ID: "test-failure", Desc: "Test fails"}
+			f.Level = levelError
+			f.Loc = span{File: relPath(r, d.File), Line: d.Line}
+			f.Logical = qualify(d.Package, d.Symbol)
+			f.Message = d.Symbol + ": " + d.Message
+		case "lint":
+			linter := d.Detail
+			if linter == "" {
+				linter = "golangci-lint"
+			}
+			f.Rule = rule{ID: linter, Desc: "golangci-lint linter " + linter, HelpURI: "https://golangci-lint.run/usage/linters/#" + linter}
+			f.Level = levelWarning
+			f.Loc = span{File: relPath(r, d.File), Line: d.Line, Col: d.Col}
+		case "staticcheck":
+			s := r.StaticIssues[i]
+			f.Rule = rule{ID: s.Code, Desc: "staticcheck check " + s.Code, HelpURI: "https://staticcheck.dev/docs/checks/#" + s.Code}
+			f.Level = staticcheckLevel(s.Severity)
+			f.Loc = span{File: relPath(r, s.File), Line: s.Line, Col: s.Col, EndLine: s.EndLine, EndCol: s.EndCol}
+		case "coverage":
+			c := r.Coverage[i]
+			if c.Coverage > 0 {
+				continue
+			}
+			f.Rule = rule{ID: "uncovered-function", Desc: "Function is not covered by tests"}
+			f.Level = levelNote
+			f.Loc = span{File: relPath(r, c.File)}
+			f.Logical = qualify(c.Package, c.Function)
+			f.Properties = map[string]any{"coverage": c.Coverage}
+			f.Message = c.Function + " is not covered by tests"
+		case "complexity":
+			c := r.Complexity[i]
+			if !c.Over(opts.ComplexityThreshold) {
+				continue
+			}
+			threshold := opts.ComplexityThreshold
+			if c.Threshold > 0 {
+				threshold = c.Threshold
+			}
+			f.Rule = rule{ID: "cognitive-complexity", Desc: "Function is too complex", HelpURI: "https://github.com/uudashr/gocognit"}
+			f.Level = levelWarning
+			f.Loc = span{File: relPath(r, c.File), Line: c.Line}
+			f.Logical = qualify(c.Package, c.Function)
+			f.Properties = map[string]any{"complexity": c.Complexity}
+			f.Message = fmt.Sprintf("%s has cognitive complexity %d (threshold %d)", c.Function, c.Complexity, threshold)
+		case "deadcode":
+			f.Rule = rule{ID: "unreachable-function", Desc: "Function is unreachable"}
+			f.Level = levelWarning
+			f.Loc = span{File: relPath(r, d.File), Line: d.Line}
+			f.Logical = qualify(d.Package, d.Symbol)
+			f.Message = d.Symbol + " is unreachable"
+		case "dupl":
+			dup := r.Duplicates[i]
+			f.Rule = rule{ID: "duplicate-code", Desc: "Code block is duplicated"}
+			f.Level = levelNote
+			f.Loc = span{File: relPath(r, dup.File1), Line: dup.StartLine1, EndLine: dup.EndLine1}
+			f.Related = &span{File: relPath(r, dup.File2), Line: dup.StartLine2, EndLine: dup.EndLine2}
+			f.Properties = map[string]any{"tokens": dup.Tokens}
+			f.Message = fmt.Sprintf("Duplicate of %s:%d-%d (%d tokens)", f.Related.File, dup.StartLine2, dup.EndLine2, dup.Tokens)
+		case "vulncheck":
+			v := r.Vulns[i]
+			f.Rule = rule{ID: v.ID, Desc: v.Summary, HelpURI: "https://pkg.go.dev/vuln/" + v.ID}
+			f.Level = levelError
+			f.Loc = span{File: "go.mod"}
+			f.Logical = v.AffectedPackage
+			if len(v.Symbols) > 0 {
+				f.Message += "; called: " + strings.Join(v.Symbols, ", ")
+			}
+		default:
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// qualify returns the Go-qualified name of a symbol in pkg.
+func qualify(pkg, symbol string) string {
+	if symbol == "" {
+		return pkg
+	}
+	if pkg == "" {
+		return symbol
+	}
+	return pkg + "." + symbol
+}
+
+// staticcheckLevel maps a staticcheck severity to a finding level.
+func staticcheckLevel(severity string) string {
+	switch severity {
+	case "error":
+		return levelError
+	case "warning":
+		return levelWarning
+	}
+	return levelNote
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+// levelRank orders finding levels from most to least severe.
+var levelRank = map[string]int{levelError: 0, levelWarning: 1, levelNote: 2}
+
+// githubCommands maps finding levels to workflow command names.
+var githubCommands = map[string]string{
+	levelError:   "error",
+	levelWarning: "warning",
+	levelNote:    "notice",
+}
+
+// GitHub writes the findings of r as GitHub Actions workflow commands,
+// which the runner turns into annotations on the affected lines. Errors
+// come first, since GitHub only displays a limited number of annotations
+// per step.
+func GitHub(w io.Writer, r *report.RunResult, opts Options) error {
+	fs := findings(r, opts)
+	sort.SliceStable(fs, func(i, j int) bool {
+		return levelRank[fs[i].Level] < levelRank[fs[j].Level]
+	})
+
+	for _, f := range fs {
+		var props []string
+		if f.Loc.File != "" {
+			props = append(props, "file="+escapeProperty(path.Join(opts.BaseDir, f.Loc.File)))
+			if f.Loc.Line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", f.Loc.Line))
+				if f.Loc.Col > 0 {
+					props = append(props, fmt.Sprintf("col=%d", f.Loc.Col))
+				}
+				if f.Loc.EndLine >= f.Loc.Line {
+					props = append(props, fmt.Sprintf("endLine=%d", f.Loc.EndLine))
+					if f.Loc.EndCol > 0 {
+						props = append(props, fmt.Sprintf("endColumn=%d", f.Loc.EndCol))
+					}
+				}
+			}
+		}
+		props = append(props, "title="+escapeProperty(f.Source+": "+f.Rule.ID))
+
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", githubCommands[f.Level], strings.Join(props, ","), escapeData(f.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeProperty escapes a property value of a workflow command.
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
@@ -0,0 +1,37 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGitHub(t *testing.T) {
+	var buf bytes.Buffer
+	if err := GitHub(&buf, sampleCheck(), Options{BaseDir: "svc"}); err != nil {
+		t.Fatalf("GitHub: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d annotations, want 5:\n%s", len(lines), buf.String())
+	}
+
+	want := "::error file=svc/broken/b.go,line=3,col=2,title=build%3A build-error::undefined: x"
+	if lines[0] != want {
+		t.Errorf("first annotation =\n  %s\nwant\n  %s", lines[0], want)
+	}
+	if !strings.HasPrefix(lines[len(lines)-1], "::warning file=svc/calc/calc.go,line=8,col=1,") {
+		t.Errorf("warnings should follow errors, last annotation = %s", lines[len(lines)-1])
+	}
+	for _, l := range lines {
+		if strings.Contains(l, "\n") {
+			t.Errorf("annotation spans lines: %q", l)
+		}
+	}
+}
+
+func TestEscapeProperty(t *testing.T) {
+	if got := escapeProperty("a:b,c%d\ne"); got != "a%3Ab%2Cc%25d%0Ae" {
+		t.Errorf("escapeProperty = %q", got)
+	}
+}
@@ -0,0 +1,106 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+// GitLab Code Quality severities.
+const (
+	severityInfo     = "info"
+	severityMinor    = "minor"
+	severityMajor    = "major"
+	severityCritical = "critical"
+	severityBlocker  = "blocker"
+)
+
+// GitLabCodeQuality writes the findings of r as a GitLab Code Quality
+// report. GitLab requires fingerprints to be unique within a report, so
+// repeated findings with the same diagnostic fingerprint are told apart
+// by their occurrence.
+func GitLabCodeQuality(w io.Writer, r *report.RunResult, opts Options) error {
+	issues := []codeQualityIssue{}
+	seen := make(map[string]int)
+
+	for _, f := range findings(r, opts) {
+		fp := f.Fingerprint
+		if n := seen[f.Fingerprint]; n > 0 {
+			sum := sha256.Sum256(fmt.Appendf(nil, "%s#%d", f.Fingerprint, n))
+			fp = hex.EncodeToString(sum[:])[:16]
+		}
+		seen[f.Fingerprint]++
+
+		begin := max(f.Loc.Line, 1)
+		issue := codeQualityIssue{
+			Type:        "issue",
+			CheckName:   f.Source + "/" + f.Rule.ID,
+			Description: f.Message,
+			Severity:    codeQualitySeverity(f),
+			Fingerprint: fp,
+		}
+		issue.Location.Path = path.Join(opts.BaseDir, codeQualityPath(r, f))
+		issue.Location.Lines.Begin = begin
+		if f.Loc.EndLine > begin {
+			issue.Location.Lines.End = f.Loc.EndLine
+		}
+		issues = append(issues, issue)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
+
+// codeQualityPath returns the file a finding is reported against. Code
+// Quality requires a path, so findings without a file, such as test
+// failures from a panicking test binary, point at their package directory.
+func codeQualityPath(r *report.RunResult, f finding) string {
+	if f.Loc.File != "" {
+		return f.Loc.File
+	}
+	if f.Package != "" && (r.Module == "" || f.Package == r.Module || strings.HasPrefix(f.Package, r.Module+"/")) {
+		return relPath(r, f.Package+"/")
+	}
+	return "go.mod"
+}
+
+// codeQualitySeverity maps a finding to a Code Quality severity. Build
+// failures block everything else and vulnerabilities are critical.
+func codeQualitySeverity(f finding) string {
+	switch f.Source {
+	case "build":
+		return severityBlocker
+	case "vulncheck":
+		return severityCritical
+	}
+	switch f.Level {
+	case levelError:
+		return severityMajor
+	case levelWarning:
+		return severityMinor
+	}
+	return severityInfo
+}
+
+// codeQualityIssue is an entry of a GitLab Code Quality report.
+type codeQualityIssue struct {
+	Type        string `json:"type"`
+	CheckName   string `json:"check_name"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+	Fingerprint string `json:"fingerprint"`
+	Location    struct {
+		Path  string `json:"path"`
+		Lines struct {
+			Begin int `json:"begin"`
+			End   int `json:"end,omitempty"`
+		} `json:"lines"`
+	} `json:"location"`
+}
@@ -0,0 +1,70 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+func TestGitLabCodeQuality(t *testing.T) {
+	r := sampleAudit()
+	r.Steps[4].Status = "done"
+	// A second identical finding must still get a unique fingerprint.
+	r.DeadFuncs = append(r.DeadFuncs, r.DeadFuncs[0])
+
+	var buf bytes.Buffer
+	if err := GitLabCodeQuality(&buf, r, Options{ComplexityThreshold: 15}); err != nil {
+		t.Fatalf("GitLabCodeQuality: %v", err)
+	}
+	var issues []codeQualityIssue
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 6 {
+		t.Fatalf("got %d issues, want 6", len(issues))
+	}
+
+	seen := make(map[string]bool)
+	for _, is := range issues {
+		if seen[is.Fingerprint] {
+			t.Errorf("duplicate fingerprint %s", is.Fingerprint)
+		}
+		seen[is.Fingerprint] = true
+		if is.Location.Path == "" || is.Location.Lines.Begin < 1 {
+			t.Errorf("issue %s has no usable location: %+v", is.CheckName, is.Location)
+		}
+	}
+
+	bySeverity := make(map[string]string)
+	for _, is := range issues {
+		bySeverity[is.CheckName] = is.Severity
+	}
+	if s := bySeverity["vulncheck/GO-2024-0001"]; s != severityCritical {
+		t.Errorf("vulnerability severity = %q, want critical", s)
+	}
+	if s := bySeverity["complexity/cognitive-complexity"]; s != severityMinor {
+		t.Errorf("complexity severity = %q, want minor", s)
+	}
+	if s := bySeverity["coverage/uncovered-function"]; s != severityInfo {
+		t.Errorf("coverage severity = %q, want info", s)
+	}
+}
+
+func TestGitLabCodeQuality_PathFallback(t *testing.T) {
+	r := &report.RunResult{Module: "example.com/proj", TestFailures: []report.TestFailure{
+		{Package: "example.com/proj/calc", Test: "TestAdd", Message: "panic"},
+	}}
+	var buf bytes.Buffer
+	if err := GitLabCodeQuality(&buf, r, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	var issues []codeQualityIssue
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Location.Path != "calc" {
+		t.Errorf("issues = %+v, want one issue at the package directory", issues)
+	}
+}
@@ -0,0 +1,371 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+//go:embed html.tmpl
+var htmlSource string
+
+var htmlTemplate = template.Must(template.New("report").
+	Funcs(template.FuncMap{"base": path.Base}).
+	Parse(htmlSource))
+
+// HTML writes r as a single self-contained HTML page, with styles and
+// scripts inlined so the file can be archived or attached as a CI artifact.
+//
+// Audit runs are shown with per-package coverage heatmaps and annotated
+// source of uncovered lines, a complexity table that sorts by column,
+// duplicate blocks side by side, unreachable functions and vulnerability
+// call traces. Other runs list their findings. Source code is read from
+// the run's root when the page is rendered, so it reflects the working tree
+// at that time rather than at the time of the run.
+func HTML(w io.Writer, r *report.RunResult, opts Options) error {
+	v := newHTMLView(r, opts)
+	return htmlTemplate.Execute(w, v)
+}
+
+// htmlView is the data rendered by html.tmpl.
+type htmlView struct {
+	Run         *report.RunResult
+	Started     string
+	Duration    string
+	Commit      string
+	Steps       []htmlStep
+	Findings    []htmlFinding
+	Coverage    *htmlCoverage
+	Complexity  []htmlComplexity
+	Threshold   int
+	Duplicates  []htmlDuplicate
+	DeadFuncs   []htmlDeadFunc
+	Vulns       []htmlVuln
+	HasVulnStep bool
+}
+
+type htmlStep struct {
+	Name, Status, Detail, Elapsed string
+}
+
+type htmlFinding struct {
+	Level, Source, Location, Message string
+}
+
+type htmlCoverage struct {
+	Percent    float64
+	Heat       template.CSS
+	Statements int
+	Packages   []htmlCoveragePackage
+	Functions  bool // percentages are function averages: no cover profile was recorded
+}
+
+type htmlCoveragePackage struct {
+	Name       string
+	Percent    float64
+	Heat       template.CSS
+	Statements int
+	Covered    int
+	Files      []htmlCoverageFile
+}
+
+type htmlCoverageFile struct {
+	Name       string
+	Percent    float64
+	Heat       template.CSS
+	Statements int
+	Covered    int
+	Uncovered  int // uncovered lines
+	Source     []htmlLine
+	SourceNote string
+}
+
+type htmlLine struct {
+	Number int
+	Text   string
+	Class  string
+}
+
+type htmlComplexity struct {
+	Function, Package, Location string
+	Score                       int
+	Over                        bool
+}
+
+type htmlDuplicate struct {
+	Tokens      int
+	Left, Right htmlBlock
+}
+
+type htmlBlock struct {
+	Location string
+	Lines    []htmlLine
+	Note     string
+}
+
+type htmlDeadFunc struct {
+	Function, Package, Location string
+}
+
+type htmlVuln struct {
+	ID, URL, Summary, Package, FixedVersion string
+	Symbols                                 []string
+	Traces                                  [][]htmlFrame
+}
+
+type htmlFrame struct {
+	Function, Location string
+}
+
+func newHTMLView(r *report.RunResult, opts Options) *htmlView {
+	src := &sourceCache{root: r.Root, files: make(map[string][]string)}
+	v := &htmlView{
+		Run:       r,
+		Duration:  formatElapsed(r.Duration().Seconds()),
+		Commit:    shortCommit(r.Commit),
+		Threshold: opts.ComplexityThreshold,
+	}
+	if !r.Started.IsZero() {
+		v.Started = r.Started.Format("2006-01-02 15:04:05 MST")
+	}
+	for _, s := range r.Steps {
+		v.Steps = append(v.Steps, htmlStep{Name: s.Name, Status: s.Status, Detail: s.Detail, Elapsed: formatElapsed(s.Elapsed)})
+		if s.Name == "vulncheck" && s.Status == "done" {
+			v.HasVulnStep = true
+		}
+	}
+
+	if r.Kind != report.Audit {
+		for _, f := range findings(r, opts) {
+			v.Findings = append(v.Findings, htmlFinding{
+				Level:    f.Level,
+				Source:   f.Source,
+				Location: location(f.Loc.File, f.Loc.Line),
+				Message:  f.Message,
+			})
+		}
+		return v
+	}
+
+	v.Coverage = htmlCoverageOf(r, src)
+
+	for _, c := range r.Complexity {
+		v.Complexity = append(v.Complexity, htmlComplexity{
+			Function: c.Function,
+			Package:  c.Package,
+			Location: location(relPath(r, c.File), c.Line),
+			Score:    c.Complexity,
+			Over:     c.Over(opts.ComplexityThreshold),
+		})
+	}
+	sort.SliceStable(v.Complexity, func(i, j int) bool { return v.Complexity[i].Score > v.Complexity[j].Score })
+
+	for _, d := range r.Duplicates {
+		v.Duplicates = append(v.Duplicates, htmlDuplicate{
+			Tokens: d.Tokens,
+			Left:   src.block(relPath(r, d.File1), d.StartLine1, d.EndLine1),
+			Right:  src.block(relPath(r, d.File2), d.StartLine2, d.EndLine2),
+		})
+	}
+
+	for _, d := range r.DeadFuncs {
+		v.DeadFuncs = append(v.DeadFuncs, htmlDeadFunc{
+			Function: d.Function,
+			Package:  d.Package,
+			Location: location(relPath(r, d.File), d.Line),
+		})
+	}
+
+	for _, vuln := range r.Vulns {
+		hv := htmlVuln{
+			ID:           vuln.ID,
+			URL:          "https://pkg.go.dev/vuln/" + vuln.ID,
+			Summary:      vuln.Summary,
+			Package:      vuln.AffectedPackage,
+			FixedVersion: vuln.FixedVersion,
+			Symbols:      vuln.Symbols,
+		}
+		for _, trace := range vuln.Traces {
+			// Traces run from the vulnerable symbol to the caller in the
+			// main module; show them in call order instead.
+			frames := make([]htmlFrame, 0, len(trace))
+			for i := len(trace) - 1; i >= 0; i-- {
+				f := trace[i]
+				frames = append(frames, htmlFrame{
+					Function: qualify(f.Package, f.Function),
+					Location: location(relPath(r, f.File), f.Line),
+				})
+			}
+			hv.Traces = append(hv.Traces, frames)
+		}
+		v.Vulns = append(v.Vulns, hv)
+	}
+	return v
+}
+
+// htmlCoverageOf builds the coverage heatmap of r. Statement coverage
+// from the cover profile is preferred; runs recorded without it fall back
+// to the average function coverage of each package.
+func htmlCoverageOf(r *report.RunResult, src *sourceCache) *htmlCoverage {
+	if len(r.CoverageFiles) == 0 && len(r.Coverage) == 0 {
+		return nil
+	}
+	cov := &htmlCoverage{}
+	index := make(map[string]int)
+	pkg := func(name string) *htmlCoveragePackage {
+		i, ok := index[name]
+		if !ok {
+			i = len(cov.Packages)
+			index[name] = i
+			cov.Packages = append(cov.Packages, htmlCoveragePackage{Name: name})
+		}
+		return &cov.Packages[i]
+	}
+
+	if len(r.CoverageFiles) == 0 {
+		cov.Functions = true
+		count := make(map[string]int)
+		var sum float64
+		for _, c := range r.Coverage {
+			p := pkg(c.Package)
+			p.Percent += c.Coverage
+			count[c.Package]++
+			sum += c.Coverage
+		}
+		for i := range cov.Packages {
+			p := &cov.Packages[i]
+			p.Percent /= float64(count[p.Name])
+			p.Heat = heat(p.Percent)
+		}
+		cov.Percent = sum / float64(len(r.Coverage))
+	} else {
+		var covered int
+		for _, cf := range r.CoverageFiles {
+			p := pkg(path.Dir(cf.File))
+			f := htmlCoverageFile{
+				Name:       relPath(r, cf.File),
+				Percent:    percent(cf.Covered, cf.Statements),
+				Statements: cf.Statements,
+				Covered:    cf.Covered,
+			}
+			f.Heat = heat(f.Percent)
+			if len(cf.Uncovered) > 0 {
+				for _, lr := range cf.Uncovered {
+					f.Uncovered += lr.End - lr.Start + 1
+				}
+				f.Source, f.SourceNote = src.annotated(f.Name, cf.Uncovered)
+			}
+			p.Files = append(p.Files, f)
+			p.Statements += cf.Statements
+			p.Covered += cf.Covered
+			cov.Statements += cf.Statements
+			covered += cf.Covered
+		}
+		for i := range cov.Packages {
+			p := &cov.Packages[i]
+			p.Percent = percent(p.Covered, p.Statements)
+			p.Heat = heat(p.Percent)
+		}
+		cov.Percent = percent(covered, cov.Statements)
+	}
+	cov.Heat = heat(cov.Percent)
+
+	// Least covered packages first.
+	sort.SliceStable(cov.Packages, func(i, j int) bool { return cov.Packages[i].Percent < cov.Packages[j].Percent })
+	return cov
+}
+
+// percent returns n as a percentage of total. An empty total counts as
+// fully covered, as there is nothing left to test.
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return 100 * float64(n) / float64(total)
+}
+
+// heat returns the background colour of a coverage percentage, from red
+// at 0% to green at 100%.
+func heat(pct float64) template.CSS {
+	return template.CSS(fmt.Sprintf("background-color: hsl(%d, 70%%, 78%%)", int(pct*1.2)))
+}
+
+// location formats a file position for display.
+func location(file string, line int) string {
+	if file == "" {
+		return ""
+	}
+	if line == 0 {
+		return file
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// sourceCache reads source files relative to a run's root.
+type sourceCache struct {
+	root  string
+	files map[string][]string
+}
+
+func (c *sourceCache) lines(file string) ([]string, error) {
+	if lines, ok := c.files[file]; ok {
+		return lines, nil
+	}
+	if c.root == "" {
+		return nil, fmt.Errorf("run has no recorded root")
+	}
+	data, err := os.ReadFile(filepath.Join(c.root, filepath.FromSlash(file)))
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	c.files[file] = lines
+	return lines, nil
+}
+
+// annotated returns the source of file with the lines in uncovered
+// marked, or a note explaining why the source is unavailable.
+func (c *sourceCache) annotated(file string, uncovered []report.LineRange) ([]htmlLine, string) {
+	lines, err := c.lines(file)
+	if err != nil {
+		return nil, "Source not available: " + err.Error()
+	}
+	out := make([]htmlLine, len(lines))
+	for i, text := range lines {
+		out[i] = htmlLine{Number: i + 1, Text: text}
+	}
+	for _, lr := range uncovered {
+		if lr.End > len(lines) {
+			return out, "Source has changed since the run: uncovered lines may be misplaced."
+		}
+		for n := lr.Start; n <= lr.End; n++ {
+			out[n-1].Class = "miss"
+		}
+	}
+	return out, ""
+}
+
+// block returns lines start to end of file.
+func (c *sourceCache) block(file string, start, end int) htmlBlock {
+	b := htmlBlock{Location: fmt.Sprintf("%s:%d-%d", file, start, end)}
+	lines, err := c.lines(file)
+	switch {
+	case err != nil:
+		b.Note = "Source not available: " + err.Error()
+	case start < 1 || end > len(lines) || start > end:
+		b.Note = "Source has changed since the run."
+	default:
+		for n := start; n <= end; n++ {
+			b.Lines = append(b.Lines, htmlLine{Number: n, Text: lines[n-1]})
+		}
+	}
+	return b
+}
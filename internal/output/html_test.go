@@ -0,0 +1,130 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+// htmlAudit returns sampleAudit rooted in a temporary module holding the
+// sources the report annotates.
+func htmlAudit(t *testing.T) *report.RunResult {
+	t.Helper()
+	root := t.TempDir()
+	var calc strings.Builder
+	for i := 1; i <= 40; i++ {
+		switch i {
+		case 7:
+			calc.WriteString("\treturn a < b && \"<script>\" != \"\"\n")
+		default:
+			calc.WriteString("// line\n")
+		}
+	}
+	files := map[string]string{
+		"calc/calc.go": calc.String(),
+		"calc/a.go":    strings.Repeat("// a\n", 9) + strings.Repeat("leftBlock()\n", 21),
+		"calc/b.go":    strings.Repeat("// b\n", 4) + strings.Repeat("rightBlock()\n", 21),
+	}
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := sampleAudit()
+	r.Root = root
+	r.CoverageFiles = []report.CoverageFile{
+		{File: "example.com/proj/calc/calc.go", Statements: 10, Covered: 6, Uncovered: []report.LineRange{{Start: 6, End: 8}}},
+		{File: "example.com/proj/calc/gone.go", Statements: 4, Covered: 0, Uncovered: []report.LineRange{{Start: 1, End: 4}}},
+		{File: "example.com/proj/util/util.go", Statements: 5, Covered: 5},
+	}
+	r.DeadFuncs[0].File = filepath.Join(root, "calc/calc.go")
+	r.Vulns[0].Traces = [][]report.TraceFrame{{
+		{Module: "example.com/dep", Package: "example.com/dep", Function: "Parse", File: "/mod/dep/parse.go", Line: 40},
+		{Module: "example.com/proj", Package: "example.com/proj/calc", Function: "Load", File: filepath.Join(root, "calc/calc.go"), Line: 12},
+	}}
+	return r
+}
+
+func renderHTML(t *testing.T, r *report.RunResult, opts Options) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := HTML(&buf, r, opts); err != nil {
+		t.Fatalf("HTML: %v", err)
+	}
+	return buf.String()
+}
+
+func TestHTML_Audit(t *testing.T) {
+	out := renderHTML(t, htmlAudit(t), Options{ComplexityThreshold: 15})
+
+	for _, want := range []string{
+		"<title>governor audit 66666666-7777-8888-9999-000000000000</title>",
+		// Statement coverage: 11 of 19 statements, least covered package first.
+		"57.9%</span>\nof 19 statements across 2 packages",
+		"<code>example.com/proj/calc</code> <span class=\"meta\">6/14 statements</span>",
+		`title="calc/calc.go: 6/10 statements">calc.go 60%</span>`,
+		// Annotated source, escaped, with uncovered lines marked.
+		`<tr class="miss"><td class="ln">7</td><td>	return a &lt; b &amp;&amp; &#34;&lt;script&gt;&#34; != &#34;&#34;</td></tr>`,
+		`<tr><td class="ln">9</td><td>// line</td></tr>`,
+		"<code>calc/gone.go</code> <span class=\"meta\">4 uncovered lines</span>",
+		"Source not available:",
+		// Complexity above the threshold is highlighted.
+		`<tr class="over"><td class="num">31</td><td><code>Mess</code>`,
+		// Duplicate blocks side by side.
+		"<code>calc/a.go:10-30</code> and <code>calc/b.go:5-25</code>",
+		`<td class="ln">10</td><td>leftBlock()</td>`,
+		`<td class="ln">5</td><td>rightBlock()</td>`,
+		// Dead code.
+		`<code>unused</code></td><td>example.com/proj/calc</td><td class="mono">calc/calc.go:40</td>`,
+		// Vulnerability traces in call order.
+		`<a href="https://pkg.go.dev/vuln/GO-2024-0001">GO-2024-0001</a>: Bad parsing`,
+		"<li><code>example.com/proj/calc.Load</code> <span class=\"mono\">calc/calc.go:12</span></li>\n<li><code>example.com/dep.Parse</code>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<script>\"") {
+		t.Error("source is not escaped")
+	}
+	if strings.Index(out, "<code>Mess</code>") > strings.Index(out, "<code>Add</code>") {
+		t.Error("complexity table is not sorted by score")
+	}
+}
+
+func TestHTML_LegacyCoverage(t *testing.T) {
+	out := renderHTML(t, sampleAudit(), Options{ComplexityThreshold: 15})
+	for _, want := range []string{
+		"50.0%</span>\naverage function coverage across 1 packages",
+		"Called: <code>dep.Parse</code>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+}
+
+func TestHTML_Check(t *testing.T) {
+	out := renderHTML(t, sampleCheck(), Options{})
+	for _, want := range []string{
+		`governor check: <span class="status-fail">fail</span>`,
+		"<h2>Findings (5)</h2>",
+		`<td class="level-error">error</td><td>test</td><td class="mono">calc_test.go:9</td><td>TestAdd: expected 4, got 5</td>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<h2>Coverage</h2>") {
+		t.Error("check run shows a coverage section")
+	}
+}
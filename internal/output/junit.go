@@ -0,0 +1,266 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+// JUnit writes the test results of r as JUnit XML, with one testsuite per
+// package and one testcase per test. Packages that failed to build become
+// suites with an errored "build" testcase. Packages without test files are
+// omitted.
+func JUnit(w io.Writer, r *report.RunResult) error {
+	pkgs := r.Tests
+	if len(pkgs) == 0 {
+		pkgs = legacyTests(r)
+	}
+	if len(pkgs) == 0 && r.Kind != report.Check {
+		return fmt.Errorf("run %s is a %s run and has no test results", r.ID, r.Kind)
+	}
+
+	doc := junitTestSuites{Name: "governor", Suites: []junitTestSuite{}}
+	for _, p := range pkgs {
+		if p.Status == "skip" && len(p.Cases) == 0 {
+			continue
+		}
+		s := junitSuite(r, p)
+		doc.Tests += s.Tests
+		doc.Failures += s.Failures
+		doc.Errors += s.Errors
+		doc.Skipped += s.Skipped
+		doc.elapsed += p.Elapsed
+		doc.Suites = append(doc.Suites, s)
+	}
+	doc.Time = seconds(doc.elapsed)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSuite(r *report.RunResult, p report.TestPackage) junitTestSuite {
+	s := junitTestSuite{
+		Name:      p.Package,
+		Time:      seconds(p.Elapsed),
+		SystemOut: systemOut(p.Output),
+	}
+	if !r.Started.IsZero() {
+		s.Timestamp = r.Started.UTC().Format("2006-01-02T15:04:05")
+	}
+	s.Properties = junitProperties(r)
+
+	failed := false
+	for _, c := range p.Cases {
+		tc := junitTestCase{
+			Name:      c.Name,
+			Classname: p.Package,
+			Time:      seconds(c.Elapsed),
+			SystemOut: systemOut(c.Output),
+		}
+		switch c.Status {
+		case "pass":
+		case "skip":
+			tc.Skipped = &junitSkipped{Message: caseMessage(c.Output)}
+			s.Skipped++
+		case "fail":
+			tc.Failure = &junitFailure{Message: caseMessage(c.Output), Type: "TestFailure", Text: xmlChars(c.Output)}
+			tc.SystemOut = nil // already carried by the failure
+			s.Failures++
+			failed = true
+		default:
+			// The test started but never reported an outcome, typically
+			// because the test binary panicked or timed out.
+			tc.Failure = &junitFailure{Message: "test did not complete", Type: "TestFailure", Text: xmlChars(c.Output)}
+			tc.SystemOut = nil
+			s.Failures++
+			failed = true
+		}
+		s.Cases = append(s.Cases, tc)
+	}
+
+	switch {
+	case p.Status == "error":
+		s.Cases = append(s.Cases, junitTestCase{
+			Name:      "build",
+			Classname: p.Package,
+			Time:      seconds(0),
+			Error:     &junitFailure{Message: "build failed", Type: "BuildError", Text: xmlChars(p.Output)},
+		})
+		s.Errors++
+		s.SystemOut = nil
+	case p.Status == "fail" && !failed:
+		// The package failed without a failing test, e.g. in TestMain or
+		// an init function.
+		s.Cases = append(s.Cases, junitTestCase{
+			Name:      "package",
+			Classname: p.Package,
+			Time:      seconds(0),
+			Error:     &junitFailure{Message: caseMessage(p.Output), Type: "PackageFailure", Text: xmlChars(p.Output)},
+		})
+		s.Errors++
+	}
+
+	s.Tests = len(s.Cases)
+	return s
+}
+
+// junitProperties returns the run metadata attached to every suite.
+func junitProperties(r *report.RunResult) *junitPropertyList {
+	var props []junitProperty
+	add := func(name, value string) {
+		if value != "" {
+			props = append(props, junitProperty{Name: name, Value: value})
+		}
+	}
+	add("governor.run", r.ID)
+	add("git.commit", r.Commit)
+	add("go.version", r.GoVersion)
+	add("governor.profile", r.Profile)
+	if len(props) == 0 {
+		return nil
+	}
+	return &junitPropertyList{Properties: props}
+}
+
+// caseMessage returns the first line of test output that is not test2json
+// framing, such as "=== RUN" or "--- FAIL:".
+func caseMessage(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "=== ") || strings.HasPrefix(line, "--- ") {
+			continue
+		}
+		if line == "FAIL" || line == "PASS" || strings.HasPrefix(line, "FAIL\t") || strings.HasPrefix(line, "ok ") {
+			continue
+		}
+		return line
+	}
+	return ""
+}
+
+// legacyTests reconstructs failing packages from runs recorded before
+// per-test results were stored.
+func legacyTests(r *report.RunResult) []report.TestPackage {
+	var pkgs []report.TestPackage
+	index := make(map[string]int)
+	for _, f := range r.TestFailures {
+		i, ok := index[f.Package]
+		if !ok {
+			i = len(pkgs)
+			index[f.Package] = i
+			pkgs = append(pkgs, report.TestPackage{Package: f.Package, Status: "fail"})
+		}
+		pkgs[i].Cases = append(pkgs[i].Cases, report.TestCase{Name: f.Test, Status: "fail", Output: f.Output})
+	}
+	for _, b := range r.BuildErrors {
+		pkgs = append(pkgs, report.TestPackage{Package: b.Package, Status: "error", Output: b.Message})
+	}
+	return pkgs
+}
+
+// seconds formats a duration in seconds as JUnit expects.
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
+
+// JUnit XML object model, following the schema understood by common CI
+// test-result viewers.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+
+	elapsed float64
+}
+
+type junitTestSuite struct {
+	Name       string             `xml:"name,attr"`
+	Tests      int                `xml:"tests,attr"`
+	Failures   int                `xml:"failures,attr"`
+	Errors     int                `xml:"errors,attr"`
+	Skipped    int                `xml:"skipped,attr"`
+	Time       string             `xml:"time,attr"`
+	Timestamp  string             `xml:"timestamp,attr,omitempty"`
+	Properties *junitPropertyList `xml:"properties,omitempty"`
+	Cases      []junitTestCase    `xml:"testcase"`
+	SystemOut  *junitOutput       `xml:"system-out,omitempty"`
+}
+
+type junitPropertyList struct {
+	Properties []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+// junitOutput holds captured output, written as CDATA to keep it readable.
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+// systemOut returns output as a system-out element, or nil if it is empty.
+func systemOut(output string) *junitOutput {
+	if output == "" {
+		return nil
+	}
+	return &junitOutput{Text: xmlChars(output)}
+}
+
+// xmlChars replaces the characters XML cannot carry, such as the escape
+// character that starts ANSI colour codes, with U+FFFD. encoding/xml does
+// this for attributes and character data but writes CDATA verbatim.
+func xmlChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isXMLChar(r) {
+			return r
+		}
+		return '\uFFFD'
+	}, s)
+}
+
+// isXMLChar reports whether r is in the XML 1.0 Char production.
+func isXMLChar(r rune) bool {
+	return r == 0x09 || r == 0x0A || r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",cdata"`
+}
@@ -0,0 +1,112 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+func renderJUnit(t *testing.T, r *report.RunResult) junitTestSuites {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := JUnit(&buf, r); err != nil {
+		t.Fatalf("JUnit: %v", err)
+	}
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("decoding JUnit XML: %v\n%s", err, buf.String())
+	}
+	return doc
+}
+
+func TestJUnit(t *testing.T) {
+	r := sampleCheck()
+	r.Tests = []report.TestPackage{
+		{Package: "example.com/proj/calc", Status: "fail", Elapsed: 0.5, Cases: []report.TestCase{
+			{Name: "TestAdd", Status: "fail", Elapsed: 0.25, Output: "=== RUN   TestAdd\n    calc_test.go:9: expected 4, got 5\n--- FAIL: TestAdd (0.25s)\n"},
+			{Name: "TestSub", Status: "pass", Elapsed: 0.01, Output: "=== RUN   TestSub\n--- PASS: TestSub (0.01s)\n"},
+			{Name: "TestNet", Status: "skip", Output: "=== RUN   TestNet\n    calc_test.go:20: needs network\n--- SKIP: TestNet (0.00s)\n"},
+		}},
+		{Package: "example.com/proj/broken", Status: "error", Output: "# example.com/proj/broken\nbroken/b.go:3:2: undefined: x\n"},
+		{Package: "example.com/proj/init", Status: "fail", Output: "panic: boom\nFAIL\texample.com/proj/init\t0.01s\n"},
+		{Package: "example.com/proj/empty", Status: "skip"},
+	}
+
+	doc := renderJUnit(t, r)
+	if len(doc.Suites) != 3 {
+		t.Fatalf("len(Suites) = %d, want 3 (package without tests omitted)", len(doc.Suites))
+	}
+	if doc.Tests != 5 || doc.Failures != 1 || doc.Errors != 2 || doc.Skipped != 1 {
+		t.Errorf("totals = %d tests, %d failures, %d errors, %d skipped; want 5, 1, 2, 1",
+			doc.Tests, doc.Failures, doc.Errors, doc.Skipped)
+	}
+
+	calc := doc.Suites[0]
+	if calc.Time != "0.500" || calc.Timestamp != "2026-03-01T12:00:00" {
+		t.Errorf("calc suite time = %s, timestamp = %s", calc.Time, calc.Timestamp)
+	}
+	add := calc.Cases[0]
+	if add.Classname != "example.com/proj/calc" || add.Time != "0.250" {
+		t.Errorf("TestAdd = %+v", add)
+	}
+	if add.Failure == nil || add.Failure.Message != "calc_test.go:9: expected 4, got 5" {
+		t.Errorf("TestAdd failure = %+v", add.Failure)
+	}
+	if calc.Cases[1].SystemOut == nil {
+		t.Error("passing test should carry its output as system-out")
+	}
+	if skip := calc.Cases[2].Skipped; skip == nil || skip.Message != "calc_test.go:20: needs network" {
+		t.Errorf("TestNet skipped = %+v", skip)
+	}
+
+	broken := doc.Suites[1]
+	if broken.Errors != 1 || broken.Cases[0].Error == nil || !strings.Contains(broken.Cases[0].Error.Text, "undefined: x") {
+		t.Errorf("broken suite = %+v", broken)
+	}
+
+	pkgFail := doc.Suites[2]
+	if pkgFail.Errors != 1 || pkgFail.Cases[0].Error.Message != "panic: boom" {
+		t.Errorf("init suite = %+v", pkgFail)
+	}
+}
+
+func TestJUnit_LegacyRun(t *testing.T) {
+	doc := renderJUnit(t, sampleCheck())
+	if doc.Failures != 2 || doc.Errors != 1 {
+		t.Errorf("failures = %d, errors = %d; want 2 and 1 from stored failures", doc.Failures, doc.Errors)
+	}
+}
+
+func TestJUnit_AuditRun(t *testing.T) {
+	var buf bytes.Buffer
+	if err := JUnit(&buf, sampleAudit()); err == nil {
+		t.Error("expected an error for an audit run")
+	}
+}
+
+func TestJUnit_ANSIOutput(t *testing.T) {
+	r := sampleCheck()
+	out := "=== RUN   TestColour\n    \x1b[31mcolour_test.go:7: red\x1b[0m\n--- FAIL: TestColour (0.00s)\n"
+	r.Tests = []report.TestPackage{
+		{Package: "example.com/proj/colour", Status: "fail", Cases: []report.TestCase{
+			{Name: "TestColour", Status: "fail", Output: out},
+			{Name: "TestPlain", Status: "pass", Output: "\x1b[32mok\x1b[0m\n"},
+		}},
+	}
+
+	doc := renderJUnit(t, r)
+	failure := doc.Suites[0].Cases[0].Failure
+	if failure == nil {
+		t.Fatal("expected a failure")
+	}
+	want := strings.ReplaceAll(out, "\x1b", "�")
+	if failure.Text != want {
+		t.Errorf("failure text = %q, want %q", failure.Text, want)
+	}
+	if out := doc.Suites[0].Cases[1].SystemOut; out == nil || out.Text != "�[32mok�[0m\n" {
+		t.Errorf("system-out = %+v, want the escape characters replaced", out)
+	}
+}
@@ -0,0 +1,400 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+// statusIcons decorates step and run statuses in Markdown.
+var statusIcons = map[string]string{
+	"pass":        "✅",
+	"done":        "✅",
+	"fail":        "❌",
+	"error":       "❌",
+	"unavailable": "⚠️",
+	"skipped":     "➖",
+	"timeout":     "⏱️",
+	"cancelled":   "⏹️",
+}
+
+// Limits on the detail of Markdown summaries.
+const (
+	topFindings = 10  // findings listed before the per-source sections
+	maxMessage  = 200 // characters kept from a finding's message
+)
+
+// levelIcons decorates findings in Markdown by level.
+var levelIcons = map[string]string{
+	levelError:   "❌",
+	levelWarning: "⚠️",
+	levelNote:    "ℹ️",
+}
+
+// Markdown writes a compact Markdown summary of r, suitable for a pull
+// request comment or a GitHub Actions step summary: the overall status, a
+// table of steps, the number of findings per source, the most severe
+// findings, and every finding in a collapsed section per source. Findings
+// link to opts.RepoURL when it is set.
+//
+// With opts.Baseline, the summary also counts new and resolved findings,
+// marks new ones, and for audits shows the change in coverage.
+//
+// With opts.MaxBytes, findings are dropped from the end of each section,
+// then whole sections, until the summary fits; the counts always cover
+// every finding.
+func Markdown(w io.Writer, r *report.RunResult, opts Options) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "### %s governor %s: %s\n\n", statusIcons[r.Status], r.Kind, orUnknown(r.Status))
+
+	steps := runSteps(r)
+	if len(steps) > 0 {
+		b.WriteString("| Step | Status | Time |\n|---|---|---|\n")
+		for _, st := range steps {
+			fmt.Fprintf(&b, "| %s | %s %s | %s |\n", st.Name, statusIcons[st.Status], st.Status, formatElapsed(st.Elapsed))
+		}
+		b.WriteString("\n")
+	}
+
+	all := findings(r, opts)
+	var order []string
+	bySource := make(map[string][]finding)
+	for _, f := range all {
+		if len(bySource[f.Source]) == 0 {
+			order = append(order, f.Source)
+		}
+		bySource[f.Source] = append(bySource[f.Source], f)
+	}
+	if len(all) > 0 {
+		parts := make([]string, len(order))
+		for i, src := range order {
+			parts[i] = fmt.Sprintf("%d %s", len(bySource[src]), mdEscape(src))
+		}
+		fmt.Fprintf(&b, "**%d %s:** %s\n\n", len(all), plural(len(all), "finding"), strings.Join(parts, ", "))
+	}
+
+	var added map[string]int
+	if opts.Baseline != nil {
+		added = writeComparison(&b, r, opts)
+	}
+
+	var sections []mdSection
+	if top := topOf(all); len(top) > 0 {
+		title := "Top findings"
+		if r.Kind == report.Check {
+			title = "Top failures"
+		}
+		s := mdSection{Title: title, Open: true}
+		for _, f := range top {
+			s.Items = append(s.Items, "**"+mdEscape(f.Source)+"** "+mdFinding(r, opts, f, added))
+		}
+		sections = append(sections, s)
+	}
+	for _, src := range order {
+		s := mdSection{Title: fmt.Sprintf("%s (%d)", mdEscape(src), len(bySource[src]))}
+		for _, f := range bySource[src] {
+			s.Items = append(s.Items, mdFinding(r, opts, f, added))
+		}
+		sections = append(sections, s)
+	}
+
+	footer := fmt.Sprintf("<sub>Run `%s`", r.ID)
+	if r.Commit != "" {
+		footer += fmt.Sprintf(" at `%s`", shortCommit(r.Commit))
+	}
+	if r.Profile != "" {
+		footer += fmt.Sprintf(" with profile `%s`", r.Profile)
+	}
+	footer += "</sub>\n"
+
+	budget := 0
+	if opts.MaxBytes > 0 {
+		budget = max(opts.MaxBytes-b.Len()-len(footer), 0)
+	}
+	b.WriteString(renderSections(sections, budget, r.ID))
+	b.WriteString(footer)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeComparison writes how r compares with opts.Baseline and returns the
+// fingerprints of findings that are new in r, with their multiplicity.
+func writeComparison(b *strings.Builder, r *report.RunResult, opts Options) map[string]int {
+	base := opts.Baseline
+	d, err := report.Compare(base, r, opts.ComplexityThreshold)
+	if err != nil {
+		fmt.Fprintf(b, "_Cannot compare with baseline: %s._\n\n", mdEscape(err.Error()))
+		return nil
+	}
+
+	added := make(map[string]int)
+	for _, s := range d.Sources {
+		for _, diag := range s.New {
+			added[report.Fingerprint(diag)]++
+		}
+	}
+
+	label := "`" + shortID(base.ID) + "`"
+	if base.Commit != "" {
+		label += " at `" + shortCommit(base.Commit) + "`"
+	}
+	n, resolved, _ := d.Totals()
+	fmt.Fprintf(b, "**Compared with %s:** %d new, %d resolved", label, n, resolved)
+
+	for _, m := range d.Metrics {
+		if m.Name == report.MetricCoverage {
+			fmt.Fprintf(b, ", coverage %.1f%% (%+.1f)", m.New, m.Delta())
+		}
+	}
+	b.WriteString("\n\n")
+	return added
+}
+
+// topOf returns the most severe errors and warnings, errors first.
+func topOf(all []finding) []finding {
+	var top []finding
+	for _, level := range []string{levelError, levelWarning} {
+		for _, f := range all {
+			if len(top) == topFindings {
+				return top
+			}
+			if f.Level == level {
+				top = append(top, f)
+			}
+		}
+	}
+	return top
+}
+
+// mdFinding formats f as a list item. Findings whose fingerprint is in
+// added are marked as new.
+func mdFinding(r *report.RunResult, opts Options, f finding, added map[string]int) string {
+	var b strings.Builder
+	b.WriteString(levelIcons[f.Level])
+	if loc := location(f.Loc.File, f.Loc.Line); loc != "" {
+		if u := fileURL(r, opts, f.Loc); u != "" {
+			fmt.Fprintf(&b, " [`%s`](%s)", loc, u)
+		} else {
+			fmt.Fprintf(&b, " `%s`", loc)
+		}
+		b.WriteString(":")
+	}
+	b.WriteString(" " + mdText(f.Message))
+	if added[f.Fingerprint] > 0 {
+		b.WriteString(" 🆕")
+	}
+	return b.String()
+}
+
+// fileURL returns the link to loc under opts.RepoURL, or "" if there is
+// no repository URL or the file lies outside the module.
+func fileURL(r *report.RunResult, opts Options, loc span) string {
+	if opts.RepoURL == "" || loc.File == "" || path.IsAbs(loc.File) || strings.HasPrefix(loc.File, "../") {
+		return ""
+	}
+	commit := r.Commit
+	if commit == "" {
+		commit = "HEAD"
+	}
+	u := strings.TrimSuffix(strings.ReplaceAll(opts.RepoURL, "{commit}", commit), "/") + "/" + loc.File
+	if loc.Line > 0 {
+		u += fmt.Sprintf("#L%d", loc.Line)
+	}
+	return u
+}
+
+// mdText returns the first line of s, shortened to maxMessage characters
+// and escaped with mdEscape.
+func mdText(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	if runes := []rune(s); len(runes) > maxMessage {
+		s = string(runes[:maxMessage-1]) + "…"
+	}
+	return mdEscape(s)
+}
+
+// mdEscaper replaces the characters that are markup in Markdown or HTML
+// with character references. Unlike backslash escapes, those are also
+// decoded in the HTML of section titles, and are never markup themselves.
+var mdEscaper = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;",
+	"\\", "&#92;", "`", "&#96;", "*", "&#42;", "_", "&#95;", "~", "&#126;",
+	"[", "&#91;", "]", "&#93;", "|", "&#124;",
+)
+
+// mdEscape returns s with its Markdown and HTML markup escaped, so that it
+// reads the same in a Markdown summary.
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+// mdSection is a collapsible list in a Markdown summary. Its Title is
+// HTML, escaped as need be with mdEscape.
+type mdSection struct {
+	Title string
+	Open  bool
+	Items []string
+}
+
+const sectionTail = "\n</details>\n\n"
+
+func (s mdSection) head() string {
+	open := "<details>"
+	if s.Open {
+		open = "<details open>"
+	}
+	return fmt.Sprintf("%s\n<summary><b>%s</b></summary>\n\n", open, s.Title)
+}
+
+// size returns the length of s rendered in full.
+func (s mdSection) size() int {
+	n := len(s.head()) + len(sectionTail)
+	for _, item := range s.Items {
+		n += len("- \n") + len(item)
+	}
+	return n
+}
+
+// render renders s within limit bytes, or in full if limit is negative.
+// Items that do not fit are dropped from the end. It returns "" if not
+// even the first item fits.
+func (s mdSection) render(limit int) string {
+	// Room for the line counting dropped items.
+	const more = len("- _… and 00000 more_\n")
+	if limit >= s.size() {
+		limit = -1
+	}
+
+	var b strings.Builder
+	b.WriteString(s.head())
+	written := 0
+	for i, item := range s.Items {
+		line := "- " + item + "\n"
+		need := b.Len() + len(line) + len(sectionTail)
+		if i < len(s.Items)-1 {
+			need += more
+		}
+		if limit >= 0 && need > limit {
+			break
+		}
+		b.WriteString(line)
+		written++
+	}
+	if written == 0 {
+		return ""
+	}
+	if rest := len(s.Items) - written; rest > 0 {
+		fmt.Fprintf(&b, "- _… and %d more_\n", rest)
+	}
+	b.WriteString(sectionTail)
+	return b.String()
+}
+
+// omissionReserve is the space kept for the note saying what was left out
+// of a summary to fit its size limit.
+const omissionReserve = 512
+
+// renderSections renders sections as collapsible lists within budget
+// bytes, or without limit if budget is zero. When they do not all fit, the
+// budget is shared so that small sections are shown in full and large ones
+// are cut evenly. Sections that do not fit at all are named in a note.
+func renderSections(sections []mdSection, budget int, runID string) string {
+	sizes := make([]int, len(sections))
+	total := 0
+	for i, s := range sections {
+		sizes[i] = s.size()
+		total += sizes[i]
+	}
+	if budget == 0 || total <= budget {
+		var b strings.Builder
+		for _, s := range sections {
+			b.WriteString(s.render(-1))
+		}
+		return b.String()
+	}
+
+	shares := share(sizes, max(budget-omissionReserve, 0))
+	var b strings.Builder
+	var skipped []string
+	for i, s := range sections {
+		out := s.render(shares[i])
+		if out == "" {
+			skipped = append(skipped, s.Title)
+		}
+		b.WriteString(out)
+	}
+	b.WriteString("_Truncated to fit the size limit")
+	if len(skipped) > 0 {
+		fmt.Fprintf(&b, "; not shown: %s", strings.Join(skipped, ", "))
+	}
+	fmt.Fprintf(&b, ". Run `governor runs show %s` for every finding._\n\n", shortID(runID))
+	return b.String()
+}
+
+// share divides budget between items of the given sizes. Items smaller
+// than an even share get their full size, and what they leave unused is
+// shared among the larger ones.
+func share(sizes []int, budget int) []int {
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return sizes[order[a]] < sizes[order[b]] })
+
+	shares := make([]int, len(sizes))
+	for k, i := range order {
+		shares[i] = min(sizes[i], budget/(len(order)-k))
+		budget -= shares[i]
+	}
+	return shares
+}
+
+// shortID abbreviates a run ID; the CLI accepts any unique prefix.
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// formatElapsed formats a step duration in seconds, or "-" if the step
+// did not run.
+func formatElapsed(seconds float64) string {
+	if seconds == 0 {
+		return "-"
+	}
+	d := time.Duration(seconds * float64(time.Second))
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// plural returns word, pluralised for n.
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+func shortCommit(c string) string {
+	if len(c) > 12 {
+		return c[:12]
+	}
+	return c
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,145 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Markdown(&buf, sampleCheck(), Options{}); err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"### ❌ governor check: fail",
+		"| test | ❌ fail | 1.5s |",
+		"| lint | ⚠️ unavailable | - |",
+		"**5 findings:** 1 build, 2 test, 2 staticcheck",
+		"at `0123456789ab`",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestMarkdown_Sections(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{RepoURL: "https://github.com/org/proj/blob/{commit}/"}
+	if err := Markdown(&buf, sampleCheck(), opts); err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<details open>\n<summary><b>Top failures</b></summary>\n\n- **build** ❌ [`broken/b.go:3`](https://github.com/org/proj/blob/0123456789abcdef/broken/b.go#L3): undefined: x\n",
+		"- **test** ❌ [`calc_test.go:9`](https://github.com/org/proj/blob/0123456789abcdef/calc_test.go#L9): TestAdd: expected 4, got 5\n",
+		"- **test** ❌ TestSub: panic\n",
+		"<details>\n<summary><b>staticcheck (2)</b></summary>\n\n- ❌ [`calc/calc.go:5`]",
+		"- ⚠️ [`calc/calc.go:8`](https://github.com/org/proj/blob/0123456789abcdef/calc/calc.go#L8): bad name\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Truncated") {
+		t.Errorf("unexpected truncation:\n%s", out)
+	}
+}
+
+func TestMarkdown_EscapesMessages(t *testing.T) {
+	r := sampleCheck()
+	r.StaticIssues[1].Message = "name *my_var* should be `myVar` | see [ST1003] \\o/ <b>"
+
+	var buf bytes.Buffer
+	if err := Markdown(&buf, r, Options{}); err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+	out := buf.String()
+	want := "- ⚠️ `calc/calc.go:8`: name &#42;my&#95;var&#42; should be &#96;myVar&#96; &#124; see &#91;ST1003&#93; &#92;o/ &lt;b&gt;\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("missing %q in:\n%s", want, out)
+	}
+}
+
+func TestMarkdown_Baseline(t *testing.T) {
+	base := sampleAudit()
+	base.ID = "aaaaaaaa-0000-0000-0000-000000000000"
+	base.Commit = "fedcba9876543210"
+	base.Coverage = base.Coverage[:1] // Sub did not exist yet
+	base.DeadFuncs = nil
+	base.Steps[4].Status = "done"
+
+	r := sampleAudit()
+	r.Steps[4].Status = "done"
+	r.Vulns = nil
+	base.Vulns[0].Summary = "Bad parsing"
+
+	var buf bytes.Buffer
+	if err := Markdown(&buf, r, Options{ComplexityThreshold: 15, Baseline: base}); err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"**Compared with `aaaaaaaa` at `fedcba987654`:** 1 new, 1 resolved, coverage 50.0% (-50.0)\n",
+		"<summary><b>Top findings</b></summary>",
+		"⚠️ `calc/calc.go:40`: unused is unreachable 🆕\n",
+		"cognitive complexity 31 (threshold 15)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestMarkdown_BaselineKindMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Markdown(&buf, sampleCheck(), Options{Baseline: sampleAudit()}); err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+	if !strings.Contains(buf.String(), "_Cannot compare with baseline:") {
+		t.Errorf("missing comparison error in:\n%s", buf.String())
+	}
+}
+
+func TestMarkdown_Truncated(t *testing.T) {
+	r := sampleAudit()
+	r.DeadFuncs = nil
+	for i := range 500 {
+		r.DeadFuncs = append(r.DeadFuncs, report.DeadFunc{
+			Package:  "example.com/proj/calc",
+			File:     "calc/calc.go",
+			Line:     i + 1,
+			Function: fmt.Sprintf("unused%d <%s>", i, strings.Repeat("x", 300)),
+		})
+	}
+
+	const limit = 8000
+	var buf bytes.Buffer
+	if err := Markdown(&buf, r, Options{ComplexityThreshold: 15, MaxBytes: limit}); err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+	out := buf.String()
+	if len(out) > limit {
+		t.Errorf("summary is %d bytes, want at most %d", len(out), limit)
+	}
+	for _, want := range []string{
+		"**504 findings:** 1 coverage, 1 complexity, 500 deadcode, 1 dupl, 1 vulncheck",
+		"more_\n",
+		// Small sections are kept whole while the large one is cut.
+		"<summary><b>dupl (1)</b></summary>",
+		"<summary><b>vulncheck (1)</b></summary>",
+		"_Truncated to fit the size limit. Run `governor runs show 66666666` for every finding._",
+		"&lt;xxxx",
+		"x…\n",
+		"<sub>Run `66666666-7777-8888-9999-000000000000`",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Package output renders stored runs in formats consumed by other tools,
+// such as code-scanning dashboards and CI systems. Every renderer works
+// from a report.RunResult alone, so a run can be rendered again later
+// from the history.
+package output
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+// Options controls how findings are selected for rendering.
+type Options struct {
+	// ComplexityThreshold is the cognitive complexity above which a
+	// function is reported as a finding.
+	ComplexityThreshold int
+
+	// BaseDir is prepended to file paths in formats that expect paths
+	// relative to the repository rather than the module, for modules
+	// that live in a subdirectory of the repository.
+	BaseDir string
+
+	// RepoURL is the URL under which files of the module are browsable.
+	// Markdown summaries link findings to it. "{commit}" is replaced with
+	// the run's commit.
+	RepoURL string
+
+	// Baseline is an earlier run of the same kind that Markdown summaries
+	// compare against.
+	Baseline *report.RunResult
+
+	// MaxBytes bounds the size of Markdown summaries. Zero means no limit.
+	MaxBytes int
+}
+
+// stepSources maps each step to the diagnostic sources it produces.
+var stepSources = map[string][]string{
+	"fix":         {"format"},
+	"format":      {"format"},
+	"test":        {"build", "test"},
+	"lint":        {"lint"},
+	"staticcheck": {"staticcheck"},
+	"coverage":    {"coverage"},
+	"complexity":  {"complexity"},
+	"deadcode":    {"deadcode"},
+	"dupl":        {"dupl"},
+	"vulncheck":   {"vulncheck"},
+}
+
+// relPath returns file as a slash-separated path relative to the root of
+// the run's module. Tools report files as absolute paths, paths relative
+// to the module root or, for coverage, module-qualified import paths.
+func relPath(r *report.RunResult, file string) string {
+	if file == "" {
+		return ""
+	}
+	if filepath.IsAbs(file) && r.Root != "" {
+		if rel, err := filepath.Rel(r.Root, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	file = filepath.ToSlash(file)
+	if r.Module != "" && strings.HasPrefix(file, r.Module+"/") {
+		file = strings.TrimPrefix(file, r.Module+"/")
+	}
+	return path.Clean(strings.TrimPrefix(file, "./"))
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+// sampleCheck returns a failed check run with findings from every check source.
+func sampleCheck() *report.RunResult {
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &report.RunResult{
+		ID:        "11111111-2222-3333-4444-555555555555",
+		Kind:      report.Check,
+		Started:   started,
+		Finished:  started.Add(3 * time.Second),
+		Root:      "/src/proj",
+		Module:    "example.com/proj",
+		Commit:    "0123456789abcdef",
+		Status:    "fail",
+		GoVersion: "go1.25.1",
+		Tools:     []report.ToolRecord{{Name: "staticcheck", Path: "go tool staticcheck", Version: "v0.6.1"}},
+		Steps: []report.StepRecord{
+			{Name: "format", Status: "pass"},
+			{Name: "test", Status: "fail", Elapsed: 1.5},
+			{Name: "lint", Status: "unavailable", Detail: "golangci-lint is required but not installed."},
+			{Name: "staticcheck", Status: "fail", Elapsed: 0.7},
+		},
+		BuildErrors: []report.BuildError{
+			{Package: "example.com/proj/broken", File: "/src/proj/broken/b.go", Line: 3, Col: 2, Message: "undefined: x"},
+		},
+		TestFailures: []report.TestFailure{
+			{Package: "example.com/proj/calc", Test: "TestAdd", File: "calc_test.go", Line: 9, Message: "expected 4, got 5", Output: "--- FAIL: TestAdd\n"},
+			{Package: "example.com/proj/calc", Test: "TestSub", Message: "panic"},
+		},
+		StaticIssues: []report.StaticIssue{
+			{Package: "/src/proj/calc", File: "/src/proj/calc/calc.go", Line: 5, Col: 1, EndLine: 5, EndCol: 9, Code: "SA4006", Severity: "error", Message: "value never used"},
+			{Package: "/src/proj/calc", File: "/src/proj/calc/calc.go", Line: 8, Col: 1, Code: "ST1003", Severity: "warning", Message: "bad name"},
+		},
+	}
+}
+
+// sampleAudit returns an audit run with findings from every audit source.
+func sampleAudit() *report.RunResult {
+	started := time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC)
+	return &report.RunResult{
+		ID:       "66666666-7777-8888-9999-000000000000",
+		Kind:     report.Audit,
+		Started:  started,
+		Finished: started.Add(10 * time.Second),
+		Root:     "/src/proj",
+		Module:   "example.com/proj",
+		Status:   "done",
+		Steps: []report.StepRecord{
+			{Name: "coverage", Status: "done"},
+			{Name: "complexity", Status: "done"},
+			{Name: "deadcode", Status: "done"},
+			{Name: "dupl", Status: "done"},
+			{Name: "vulncheck", Status: "error", Detail: "govulncheck failed"},
+		},
+		Coverage: []report.CoverageEntry{
+			{Package: "example.com/proj/calc", File: "example.com/proj/calc/calc.go", Function: "Add", Coverage: 100},
+			{Package: "example.com/proj/calc", File: "example.com/proj/calc/calc.go", Function: "Sub", Coverage: 0},
+		},
+		Complexity: []report.ComplexityEntry{
+			{Package: "calc", File: "calc/calc.go", Function: "Add", Line: 3, Complexity: 2},
+			{Package: "calc", File: "calc/calc.go", Function: "Mess", Line: 20, Complexity: 31},
+		},
+		DeadFuncs: []report.DeadFunc{
+			{Package: "example.com/proj/calc", File: "/src/proj/calc/calc.go", Line: 40, Function: "unused"},
+		},
+		Duplicates: []report.Duplicate{
+			{File1: "calc/a.go", StartLine1: 10, EndLine1: 30, File2: "calc/b.go", StartLine2: 5, EndLine2: 25, Tokens: 80},
+		},
+		Vulns: []report.Vuln{
+			{ID: "GO-2024-0001", Summary: "Bad parsing", AffectedPackage: "example.com/dep", FixedVersion: "v1.2.3", Symbols: []string{"dep.Parse"}},
+		},
+	}
+}
+
+func TestRelPath(t *testing.T) {
+	r := &report.RunResult{Root: "/src/proj", Module: "example.com/proj"}
+	tests := []struct {
+		file, want string
+	}{
+		{"/src/proj/calc/calc.go", "calc/calc.go"},
+		{"example.com/proj/calc/calc.go", "calc/calc.go"},
+		{"./calc/calc.go", "calc/calc.go"},
+		{"calc/calc.go", "calc/calc.go"},
+		{"/elsewhere/x.go", "/elsewhere/x.go"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := relPath(r, tt.file); got != tt.want {
+			t.Errorf("relPath(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+// SARIF schema and version emitted by SARIF.
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// srcRoot is the URI base ID that artifact locations are relative to.
+const srcRoot = "%SRCROOT%"
+
+// fingerprintKey names the partial fingerprint carrying report.Fingerprint,
+// so that code-scanning tools track findings the same way run diffs do.
+const fingerprintKey = "governor/v1"
+
+// sarifSteps lists the steps that produce SARIF runs, in output order.
+var sarifSteps = []string{"fix", "format", "test", "lint", "staticcheck", "coverage", "complexity", "deadcode", "dupl", "vulncheck"}
+
+// sarifDriver describes the tool behind a step.
+type sarifDriver struct {
+	name string
+	uri  string
+}
+
+var sarifDrivers = map[string]sarifDriver{
+	"fix":         {"gofumpt", "https://github.com/mvdan/gofumpt"},
+	"format":      {"gofumpt", "https://github.com/mvdan/gofumpt"},
+	"test":        {"go", "https://go.dev"},
+	"coverage":    {"go", "https://go.dev"},
+	"lint":        {"golangci-lint", "https://golangci-lint.run"},
+	"staticcheck": {"staticcheck", "https://staticcheck.dev"},
+	"complexity":  {"gocognit", "https://github.com/uudashr/gocognit"},
+	"deadcode":    {"deadcode", "https://pkg.go.dev/golang.org/x/tools/cmd/deadcode"},
+	"dupl":        {"dupl", "https://github.com/mibk/dupl"},
+	"vulncheck":   {"govulncheck", "https://go.dev/security/vuln"},
+}
+
+// SARIF writes r as a SARIF 2.1.0 log. Each step that ran becomes a SARIF
+// run of its own, so that dashboards can track every tool separately.
+// Steps that errored or were unavailable produce a run with a failed
+// invocation and no results, which leaves earlier results in place rather
+// than marking them fixed. Skipped steps are omitted.
+func SARIF(w io.Writer, r *report.RunResult, opts Options) error {
+	results := make(map[string][]finding)
+	for _, f := range findings(r, opts) {
+		results[f.Source] = append(results[f.Source], f)
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{},
+	}
+	for _, st := range runSteps(r) {
+		if st.Status == "skipped" {
+			continue
+		}
+		log.Runs = append(log.Runs, sarifRunFor(r, st, results))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// runSteps returns the steps recorded for r. Runs stored without step
+// outcomes report one completed step per source that has findings.
+func runSteps(r *report.RunResult) []report.StepRecord {
+	if len(r.Steps) > 0 {
+		return r.Steps
+	}
+
+	present := make(map[string]bool)
+	for _, d := range report.Diagnostics(r) {
+		present[d.Source] = true
+	}
+	var steps []report.StepRecord
+	for _, name := range sarifSteps {
+		if name == "fix" {
+			continue
+		}
+		for _, src := range stepSources[name] {
+			if present[src] {
+				steps = append(steps, report.StepRecord{Name: name, Status: "done"})
+				break
+			}
+		}
+	}
+	return steps
+}
+
+func sarifRunFor(r *report.RunResult, st report.StepRecord, results map[string][]finding) sarifRun {
+	drv, ok := sarifDrivers[st.Name]
+	if !ok {
+		drv = sarifDriver{name: st.Name}
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifToolComponent{
+			Name:           drv.name,
+			Version:        toolVersion(r, drv.name),
+			InformationURI: drv.uri,
+			Rules:          []sarifRule{},
+		}},
+		AutomationDetails: &sarifAutomationDetails{
+			ID: fmt.Sprintf("governor/%s/%s/%s", r.Kind, st.Name, r.ID),
+		},
+		Properties: map[string]any{"runId": r.ID},
+	}
+	if r.Commit != "" {
+		run.Properties["commit"] = r.Commit
+		run.Properties["dirty"] = r.Dirty
+	}
+	if r.Profile != "" {
+		run.Properties["profile"] = r.Profile
+	}
+	if r.Root != "" {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{
+			srcRoot: {URI: fileURI(r.Root)},
+		}
+	}
+
+	inv := sarifInvocation{
+		ExecutionSuccessful: st.Status != "error" && st.Status != "unavailable" && st.Status != "timeout" && st.Status != "cancelled",
+		Properties:          map[string]any{"status": st.Status, "elapsed": st.Elapsed},
+	}
+	if !inv.ExecutionSuccessful {
+		msg := st.Detail
+		if msg == "" {
+			msg = st.Name + " " + st.Status
+		}
+		inv.ToolExecutionNotifications = []sarifNotification{{
+			Level:   "error",
+			Message: sarifMessage{Text: msg},
+		}}
+		run.Invocations = []sarifInvocation{inv}
+		return run
+	}
+	run.Invocations = []sarifInvocation{inv}
+
+	rules := make(map[string]int)
+	out := []sarifResult{}
+	for _, src := range stepSources[st.Name] {
+		for _, f := range results[src] {
+			idx, ok := rules[f.Rule.ID]
+			if !ok {
+				idx = len(run.Tool.Driver.Rules)
+				rules[f.Rule.ID] = idx
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+					ID:                   f.Rule.ID,
+					ShortDescription:     &sarifMessage{Text: f.Rule.Desc},
+					HelpURI:              f.Rule.HelpURI,
+					DefaultConfiguration: &sarifConfiguration{Level: f.Level},
+				})
+			}
+			res := sarifResultFor(f)
+			res.RuleIndex = idx
+			out = append(out, res)
+		}
+	}
+	run.Results = &out
+	return run
+}
+
+// toolVersion returns the recorded version of a tool used by r.
+func toolVersion(r *report.RunResult, name string) string {
+	if name == "go" {
+		return r.GoVersion
+	}
+	for _, t := range r.Tools {
+		if t.Name == name {
+			return t.Version
+		}
+	}
+	return ""
+}
+
+// fileURI returns the file URI of a directory, with the trailing slash
+// SARIF requires of base URIs.
+func fileURI(dir string) string {
+	p := filepath.ToSlash(dir)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return "file://" + strings.TrimSuffix(p, "/") + "/"
+}
+
+// sarifResultFor converts a finding to a SARIF result, without its rule index.
+func sarifResultFor(f finding) sarifResult {
+	res := sarifResult{
+		RuleID:              f.Rule.ID,
+		Level:               f.Level,
+		Message:             sarifMessage{Text: f.Message},
+		Locations:           []sarifLocation{sarifLocationFor(f.Loc, f.Logical)},
+		PartialFingerprints: map[string]string{fingerprintKey: f.Fingerprint},
+		Properties:          f.Properties,
+	}
+	if f.Related != nil {
+		rel := sarifLocationFor(*f.Related, "")
+		rel.ID = 1
+		rel.Message = &sarifMessage{Text: "duplicate block"}
+		res.RelatedLocations = []sarifLocation{rel}
+	}
+	return res
+}
+
+// sarifLocationFor returns the location of a span. A zero line omits the
+// region; an empty logical name omits the logical location.
+func sarifLocationFor(s span, logical string) sarifLocation {
+	var loc sarifLocation
+	if s.File != "" {
+		loc.PhysicalLocation = &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: s.File, URIBaseID: srcRoot},
+		}
+		if s.Line > 0 {
+			region := &sarifRegion{StartLine: s.Line, StartColumn: s.Col}
+			if s.EndLine >= s.Line {
+				region.EndLine = s.EndLine
+				region.EndColumn = s.EndCol
+			}
+			loc.PhysicalLocation.Region = region
+		}
+	}
+	if logical != "" {
+		kind := "function"
+		if strings.LastIndex(logical, ".") < strings.LastIndex(logical, "/")+1 {
+			kind = "package"
+		}
+		loc.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: logical, Kind: kind}}
+	}
+	return loc
+}
+
+// SARIF 2.1.0 object model, limited to the properties governor emits.
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	AutomationDetails  *sarifAutomationDetails          `json:"automationDetails,omitempty"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Invocations        []sarifInvocation                `json:"invocations,omitempty"`
+	// Results is nil when the tool did not run to completion: an absent
+	// array means "not analysed", whereas an empty one means "no findings".
+	Results    *[]sarifResult `json:"results,omitempty"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifToolComponent `json:"driver"`
+}
+
+type sarifToolComponent struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     *sarifMessage       `json:"shortDescription,omitempty"`
+	HelpURI              string              `json:"helpUri,omitempty"`
+	DefaultConfiguration *sarifConfiguration `json:"defaultConfiguration,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+	Properties                 map[string]any      `json:"properties,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	RelatedLocations    []sarifLocation   `json:"relatedLocations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	ID               int                    `json:"id,omitempty"`
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	Message          *sarifMessage          `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind,omitempty"`
+}
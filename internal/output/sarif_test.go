@@ -0,0 +1,188 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/deixis/governor/internal/report"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// validateSARIF checks data against the SARIF 2.1.0 schema and decodes it.
+//
+// testdata/sarif-schema-2.1.0.json is the official OASIS schema, unmodified:
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/errata01/os/schemas/sarif-schema-2.1.0.json
+func validateSARIF(t *testing.T, data []byte) sarifLog {
+	t.Helper()
+
+	raw, err := os.ReadFile("testdata/sarif-schema-2.1.0.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatalf("parsing schema: %v", err)
+	}
+	// The schema declares draft-04, which the validator refuses outright. The
+	// keywords it uses mean the same in draft 2020-12, so validate it as such.
+	schema.Schema = ""
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		t.Fatalf("resolving schema: %v", err)
+	}
+
+	var instance any
+	if err := json.Unmarshal(data, &instance); err != nil {
+		t.Fatalf("decoding SARIF: %v", err)
+	}
+	if err := resolved.Validate(instance); err != nil {
+		t.Fatalf("SARIF does not match schema: %v\n%s", err, data)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	return log
+}
+
+func renderSARIF(t *testing.T, r *report.RunResult) sarifLog {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := SARIF(&buf, r, Options{ComplexityThreshold: 15}); err != nil {
+		t.Fatalf("SARIF: %v", err)
+	}
+	return validateSARIF(t, buf.Bytes())
+}
+
+func TestSARIF_Check(t *testing.T) {
+	r := sampleCheck()
+	log := renderSARIF(t, r)
+
+	if len(log.Runs) != 4 {
+		t.Fatalf("len(Runs) = %d, want 4 (one per step)", len(log.Runs))
+	}
+
+	test := log.Runs[1]
+	if test.Tool.Driver.Name != "go" || test.Tool.Driver.Version != "go1.25.1" {
+		t.Errorf("test driver = %+v, want go go1.25.1", test.Tool.Driver)
+	}
+	if test.Results == nil || len(*test.Results) != 3 {
+		t.Fatalf("test results = %v, want 1 build error and 2 test failures", test.Results)
+	}
+	build := (*test.Results)[0]
+	if build.RuleID != "build-error" || build.Level != "error" {
+		t.Errorf("build result = %+v", build)
+	}
+	loc := build.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "broken/b.go" || loc.ArtifactLocation.URIBaseID != srcRoot {
+		t.Errorf("build location = %+v, want broken/b.go relative to %s", loc.ArtifactLocation, srcRoot)
+	}
+	if loc.Region.StartLine != 3 || loc.Region.StartColumn != 2 {
+		t.Errorf("build region = %+v", loc.Region)
+	}
+	want := report.Fingerprint(report.Diagnostics(r)[0])
+	if got := build.PartialFingerprints[fingerprintKey]; got != want {
+		t.Errorf("fingerprint = %q, want %q", got, want)
+	}
+	if got := test.OriginalURIBaseIDs[srcRoot].URI; got != "file:///src/proj/" {
+		t.Errorf("%s = %q, want file:///src/proj/", srcRoot, got)
+	}
+
+	lint := log.Runs[2]
+	if lint.Invocations[0].ExecutionSuccessful {
+		t.Error("unavailable lint step should have a failed invocation")
+	}
+	if lint.Results != nil {
+		t.Errorf("unavailable lint step should have no results array, got %v", *lint.Results)
+	}
+
+	sc := log.Runs[3]
+	if sc.Tool.Driver.Version != "v0.6.1" {
+		t.Errorf("staticcheck version = %q, want v0.6.1", sc.Tool.Driver.Version)
+	}
+	if len(sc.Tool.Driver.Rules) != 2 {
+		t.Fatalf("staticcheck rules = %d, want 2", len(sc.Tool.Driver.Rules))
+	}
+	second := (*sc.Results)[1]
+	if second.RuleID != "ST1003" || second.RuleIndex != 1 || second.Level != "warning" {
+		t.Errorf("second staticcheck result = %+v", second)
+	}
+	if r := (*sc.Results)[0].Locations[0].PhysicalLocation.Region; r.EndLine != 5 || r.EndColumn != 9 {
+		t.Errorf("staticcheck region = %+v, want end 5:9", r)
+	}
+}
+
+func TestSARIF_Audit(t *testing.T) {
+	log := renderSARIF(t, sampleAudit())
+
+	byTool := make(map[string]sarifRun)
+	for _, run := range log.Runs {
+		byTool[run.Tool.Driver.Name] = run
+	}
+
+	count := func(name string) int {
+		run, ok := byTool[name]
+		if !ok || run.Results == nil {
+			return -1
+		}
+		return len(*run.Results)
+	}
+	if n := count("go"); n != 1 {
+		t.Errorf("coverage results = %d, want 1 uncovered function", n)
+	}
+	if n := count("gocognit"); n != 1 {
+		t.Errorf("complexity results = %d, want 1 above threshold", n)
+	}
+	if n := count("deadcode"); n != 1 {
+		t.Errorf("deadcode results = %d, want 1", n)
+	}
+	if n := count("govulncheck"); n != -1 {
+		t.Errorf("errored vulncheck step should have no results array, got %d", n)
+	}
+
+	dupl := (*byTool["dupl"].Results)[0]
+	if len(dupl.RelatedLocations) != 1 {
+		t.Fatalf("dupl related locations = %d, want 1", len(dupl.RelatedLocations))
+	}
+	rel := dupl.RelatedLocations[0]
+	if rel.PhysicalLocation.ArtifactLocation.URI != "calc/b.go" || rel.PhysicalLocation.Region.EndLine != 25 {
+		t.Errorf("related location = %+v", rel.PhysicalLocation)
+	}
+
+	cov := (*byTool["go"].Results)[0]
+	if got := cov.Locations[0].PhysicalLocation.ArtifactLocation.URI; got != "calc/calc.go" {
+		t.Errorf("coverage uri = %q, want calc/calc.go", got)
+	}
+	if got := cov.Locations[0].LogicalLocations[0].FullyQualifiedName; got != "example.com/proj/calc.Sub" {
+		t.Errorf("coverage logical location = %q", got)
+	}
+}
+
+func TestSARIF_VulnResult(t *testing.T) {
+	r := sampleAudit()
+	r.Steps[4].Status = "done"
+	log := renderSARIF(t, r)
+
+	run := log.Runs[len(log.Runs)-1]
+	rule := run.Tool.Driver.Rules[0]
+	if rule.ID != "GO-2024-0001" || rule.HelpURI != "https://pkg.go.dev/vuln/GO-2024-0001" {
+		t.Errorf("vuln rule = %+v", rule)
+	}
+	res := (*run.Results)[0]
+	if res.Message.Text != "Bad parsing (fixed in v1.2.3); called: dep.Parse" {
+		t.Errorf("vuln message = %q", res.Message.Text)
+	}
+}
+
+func TestSARIF_LegacyRunWithoutSteps(t *testing.T) {
+	r := sampleCheck()
+	r.Steps = nil
+	log := renderSARIF(t, r)
+
+	if len(log.Runs) != 2 {
+		t.Fatalf("len(Runs) = %d, want test and staticcheck", len(log.Runs))
+	}
+}
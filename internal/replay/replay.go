@@ -0,0 +1,310 @@
+// Package replay records the commands run by a workflow.Engine into
+// fixture files and serves them back, so that check and audit flows can
+// be tested deterministically without the tools they run installed.
+//
+// A fixture holds the tools that were resolved and, for each command, its
+// argv, working directory, exit code and output. Paths within the
+// workspace are stored relative to a $WORKSPACE placeholder, so that a
+// fixture replays in any copy of the module it was recorded in.
+// Temporary files a command was asked to write, such as a coverage
+// profile, are stored with it and written again on replay.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/deixis/governor/internal/runner"
+)
+
+// RecordEnv is the environment variable that makes ForTest record
+// fixtures instead of replaying them.
+const RecordEnv = "GOVERNOR_RECORD"
+
+const (
+	workspaceVar = "$WORKSPACE"
+	tempFileVar  = "$TEMPFILE"
+)
+
+// Runner runs commands. It is implemented by runner.Runner, Recorder and
+// Replayer.
+type Runner interface {
+	Run(ctx context.Context, argv []string, cwd string, opts ...runner.Option) (*runner.Result, error)
+}
+
+// Fixture is the recording of the commands of one or more runs.
+type Fixture struct {
+	// Tools maps each tool looked up to the argv that invokes it, or null
+	// if it was not installed.
+	Tools    map[string][]string `json:"tools"`
+	Commands []Command           `json:"commands"`
+}
+
+// Command is a recorded command invocation.
+type Command struct {
+	Argv     []string      `json:"argv"`
+	Cwd      string        `json:"cwd,omitempty"`
+	ExitCode int           `json:"exit_code"`
+	Status   runner.Status `json:"status,omitempty"`
+	Stdout   string        `json:"stdout,omitempty"`
+	Stderr   string        `json:"stderr,omitempty"`
+	// Files holds the content of the temporary files the command wrote,
+	// by the index of their path in Argv.
+	Files map[int]string `json:"files,omitempty"`
+}
+
+// Recorder runs commands with another Runner and records them.
+type Recorder struct {
+	runner    Runner
+	resolve   func(name string) []string
+	workspace string
+
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+// NewRecorder returns a Recorder running commands with r and resolving
+// tools with resolve, such as workflow.ResolveTool. Paths within
+// workspace are recorded relative to it.
+func NewRecorder(r Runner, resolve func(name string) []string, workspace string) *Recorder {
+	return &Recorder{
+		runner:    r,
+		resolve:   resolve,
+		workspace: workspace,
+		fixture:   Fixture{Tools: make(map[string][]string)},
+	}
+}
+
+// ResolveTool resolves a tool and records the result.
+func (r *Recorder) ResolveTool(name string) []string {
+	argv := r.resolve(name)
+	r.mu.Lock()
+	r.fixture.Tools[name] = argv
+	r.mu.Unlock()
+	return argv
+}
+
+// Dir returns the directory of the recorded runner, if it reports one.
+func (r *Recorder) Dir() string {
+	if d, ok := r.runner.(interface{ Dir() string }); ok {
+		return d.Dir()
+	}
+	return ""
+}
+
+// Run runs a command and records it. Commands that fail to start are not
+// recorded.
+func (r *Recorder) Run(ctx context.Context, argv []string, cwd string, opts ...runner.Option) (*runner.Result, error) {
+	res, err := r.runner.Run(ctx, argv, cwd, opts...)
+	if err != nil {
+		return res, err
+	}
+
+	c := Command{
+		Argv:     make([]string, len(argv)),
+		Cwd:      r.relative(cwd),
+		ExitCode: res.ExitCode,
+		Status:   res.Status,
+		Stdout:   r.relative(string(res.Stdout)),
+		Stderr:   r.relative(string(res.Stderr)),
+	}
+	for i, arg := range argv {
+		c.Argv[i] = r.relative(arg)
+		if c.Argv[i] != arg || !isTempFile(arg) {
+			continue
+		}
+		data, err := os.ReadFile(arg)
+		if err != nil {
+			continue
+		}
+		if c.Files == nil {
+			c.Files = make(map[int]string)
+		}
+		c.Argv[i] = tempFileVar
+		c.Files[i] = r.relative(string(data))
+	}
+
+	r.mu.Lock()
+	r.fixture.Commands = append(r.fixture.Commands, c)
+	r.mu.Unlock()
+	return res, nil
+}
+
+// Save writes the recorded fixture to path.
+func (r *Recorder) Save(path string) error {
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.fixture, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("marshalling fixture: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating fixture directory: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// relative replaces the workspace path in s with a placeholder.
+func (r *Recorder) relative(s string) string {
+	if r.workspace == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, r.workspace, workspaceVar)
+}
+
+// isTempFile reports whether path is a file in the temporary directory.
+func isTempFile(path string) bool {
+	if !filepath.IsAbs(path) {
+		return false
+	}
+	rel, err := filepath.Rel(os.TempDir(), path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// Replayer serves recorded commands back without running anything.
+type Replayer struct {
+	fixture   Fixture
+	workspace string
+
+	mu   sync.Mutex
+	used []bool
+}
+
+// Load returns a Replayer serving the fixture at path, with the workspace
+// placeholder standing for workspace.
+func Load(path, workspace string) (*Replayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading fixture: %w", err)
+	}
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing fixture %s: %w", path, err)
+	}
+	return &Replayer{fixture: f, workspace: workspace, used: make([]bool, len(f.Commands))}, nil
+}
+
+// ResolveTool returns the recorded argv of a tool, or nil if it was not
+// installed or not looked up when recording.
+func (r *Replayer) ResolveTool(name string) []string {
+	return r.fixture.Tools[name]
+}
+
+// Dir returns the workspace the recorded commands ran in.
+func (r *Replayer) Dir() string {
+	return r.workspace
+}
+
+// Run returns the result recorded for argv in cwd. Identical commands are
+// served in the order they were recorded; once all have been served, the
+// last is served again. Output is also written to the Stdout and Log
+// options, and recorded temporary files to their paths in argv.
+func (r *Replayer) Run(ctx context.Context, argv []string, cwd string, opts ...runner.Option) (*runner.Result, error) {
+	i, err := r.match(argv, cwd)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &r.fixture.Commands[i]
+	for j, content := range c.Files {
+		if j < len(argv) {
+			if err := os.WriteFile(argv[j], []byte(r.absolute(content)), 0o644); err != nil {
+				return nil, fmt.Errorf("replay: writing %s: %w", argv[j], err)
+			}
+		}
+	}
+
+	res := &runner.Result{
+		RunID:    fmt.Sprintf("replay-%d", i),
+		ExitCode: c.ExitCode,
+		Status:   c.Status,
+		Stdout:   []byte(r.absolute(c.Stdout)),
+		Stderr:   []byte(r.absolute(c.Stderr)),
+	}
+	if res.Status == "" {
+		res.Status = runner.Exited
+	}
+	o := runner.Apply(opts...)
+	if o.Stdout != nil {
+		_, _ = o.Stdout.Write(res.Stdout)
+	}
+	if o.Log != nil {
+		_, _ = o.Log.Write(res.Stdout)
+		_, _ = o.Log.Write(res.Stderr)
+	}
+	return res, nil
+}
+
+// match returns the index of the recorded command to serve for argv in
+// cwd.
+func (r *Replayer) match(argv []string, cwd string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	last := -1
+	for i := range r.fixture.Commands {
+		if !r.matches(&r.fixture.Commands[i], argv, cwd) {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return i, nil
+		}
+		last = i
+	}
+	if last < 0 {
+		return 0, fmt.Errorf("replay: no recorded command %q in %q", argv, cwd)
+	}
+	return last, nil
+}
+
+func (r *Replayer) matches(c *Command, argv []string, cwd string) bool {
+	if r.absolute(c.Cwd) != cwd || len(c.Argv) != len(argv) {
+		return false
+	}
+	for i, arg := range c.Argv {
+		if _, ok := c.Files[i]; ok && arg == tempFileVar {
+			continue
+		}
+		if r.absolute(arg) != argv[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// absolute replaces the workspace placeholder in s with the workspace.
+func (r *Replayer) absolute(s string) string {
+	return strings.ReplaceAll(s, workspaceVar, r.workspace)
+}
+
+// ForTest returns a Runner for t that replays the fixture at path in
+// workspace. When the GOVERNOR_RECORD environment variable is set, it
+// instead runs the real commands with r, resolving tools with resolve,
+// and records them to path when the test ends.
+func ForTest(t testing.TB, path, workspace string, r Runner, resolve func(name string) []string) Runner {
+	t.Helper()
+	if os.Getenv(RecordEnv) != "" {
+		rec := NewRecorder(r, resolve, workspace)
+		t.Cleanup(func() {
+			if err := rec.Save(path); err != nil {
+				t.Errorf("saving fixture: %v", err)
+			}
+		})
+		return rec
+	}
+	rep, err := Load(path, workspace)
+	if err != nil {
+		t.Fatalf("%v (set %s=1 to record it)", err, RecordEnv)
+	}
+	return rep
+}
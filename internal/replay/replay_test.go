@@ -0,0 +1,169 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deixis/governor/internal/runner"
+)
+
+func record(t *testing.T, workspace string, run func(*Recorder)) string {
+	t.Helper()
+	r := &runner.Runner{Workspace: workspace, Timeout: time.Minute, MaxOutput: 1 << 20}
+	rec := NewRecorder(r, func(name string) []string { return []string{"/bin/" + name} }, workspace)
+	rec.ResolveTool("sh")
+	run(rec)
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	return path
+}
+
+func TestReplay_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	recorded := t.TempDir()
+	path := record(t, recorded, func(r *Recorder) {
+		if _, err := r.Run(ctx, []string{"sh", "-c", "pwd; echo oops >&2; exit 3"}, recorded); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), recorded) {
+		t.Errorf("fixture contains the workspace path:\n%s", data)
+	}
+
+	workspace := t.TempDir()
+	rep, err := Load(path, workspace)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := rep.ResolveTool("sh"); len(got) != 1 || got[0] != "/bin/sh" {
+		t.Errorf("ResolveTool(sh) = %q", got)
+	}
+	if got := rep.ResolveTool("missing"); got != nil {
+		t.Errorf("ResolveTool(missing) = %q, want nil", got)
+	}
+
+	var stdout, log bytes.Buffer
+	res, err := rep.Run(ctx, []string{"sh", "-c", "pwd; echo oops >&2; exit 3"}, workspace,
+		runner.WithStdout(&stdout), runner.WithLog(&log))
+	if err != nil {
+		t.Fatalf("replay Run: %v", err)
+	}
+	if res.ExitCode != 3 || res.Status != runner.Exited {
+		t.Errorf("exit code = %d, status = %s", res.ExitCode, res.Status)
+	}
+	if got, want := string(res.Stdout), workspace+"\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+	if string(res.Stderr) != "oops\n" {
+		t.Errorf("stderr = %q", res.Stderr)
+	}
+	if stdout.String() != string(res.Stdout) || log.String() != workspace+"\noops\n" {
+		t.Errorf("Stdout option = %q, Log option = %q", stdout.String(), log.String())
+	}
+}
+
+func TestReplay_TempFiles(t *testing.T) {
+	ctx := context.Background()
+	workspace := t.TempDir()
+	path := record(t, workspace, func(r *Recorder) {
+		f, err := os.CreateTemp("", "replay-*.out")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		defer os.Remove(f.Name())
+		if _, err := r.Run(ctx, []string{"sh", "-c", `echo "$1/x" > "$2"`, "sh", workspace, f.Name()}, ""); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	})
+
+	rep, err := Load(path, workspace)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	out := filepath.Join(t.TempDir(), "other.out")
+	if _, err := rep.Run(ctx, []string{"sh", "-c", `echo "$1/x" > "$2"`, "sh", workspace, out}, ""); err != nil {
+		t.Fatalf("replay Run: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("temp file not written: %v", err)
+	}
+	if got, want := string(data), workspace+"/x\n"; got != want {
+		t.Errorf("temp file = %q, want %q", got, want)
+	}
+}
+
+func TestReplay_Order(t *testing.T) {
+	ctx := context.Background()
+	workspace := t.TempDir()
+	counter := filepath.Join(workspace, "n")
+	argv := []string{"sh", "-c", `echo x >> n; wc -l < n`}
+	path := record(t, workspace, func(r *Recorder) {
+		for range 2 {
+			if _, err := r.Run(ctx, argv, workspace); err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+		}
+	})
+	if err := os.Remove(counter); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := Load(path, workspace)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var got []string
+	for range 3 {
+		res, err := rep.Run(ctx, argv, workspace)
+		if err != nil {
+			t.Fatalf("replay Run: %v", err)
+		}
+		got = append(got, strings.TrimSpace(string(res.Stdout)))
+	}
+	if strings.Join(got, " ") != "1 2 2" {
+		t.Errorf("outputs = %q, want 1 2 2", got)
+	}
+	if _, err := os.Stat(counter); !os.IsNotExist(err) {
+		t.Errorf("replay ran the command: %v", err)
+	}
+}
+
+func TestReplay_Unmatched(t *testing.T) {
+	ctx := context.Background()
+	workspace := t.TempDir()
+	path := record(t, workspace, func(r *Recorder) {
+		if _, err := r.Run(ctx, []string{"sh", "-c", "true"}, workspace); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	})
+
+	rep, err := Load(path, workspace)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for _, tc := range []struct {
+		argv []string
+		cwd  string
+	}{
+		{[]string{"sh", "-c", "false"}, workspace},
+		{[]string{"sh", "-c", "true"}, t.TempDir()},
+	} {
+		if _, err := rep.Run(ctx, tc.argv, tc.cwd); err == nil || !strings.Contains(err.Error(), "no recorded command") {
+			t.Errorf("Run(%q, %q) error = %v, want no recorded command", tc.argv, tc.cwd, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+// metricSources are diagnostic sources that describe measurements rather
+// than findings. They are compared through metric deltas, not fingerprints.
+var metricSources = map[string]bool{
+	"coverage":   true,
+	"complexity": true,
+}
+
+// Fingerprint returns a stable identifier for a diagnostic. It covers what
+// the finding is and where it lives (source, package, file, symbol, detail
+// and message) but not its line or column, so that a finding keeps its
+// identity when unrelated edits shift it within the file. Positions quoted
+// in the message, as in compiler output or the other copy of a duplicate,
+// are left out too.
+func Fingerprint(d Diagnostic) string {
+	h := sha256.New()
+	for _, part := range []string{d.Source, d.Package, d.File, d.Symbol, d.Detail, positionFree(d.Message)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+var (
+	// messagePos matches the position starting a line of compiler or
+	// test output, as in "./a.go:12:3: " or "a_test.go:12: ".
+	messagePos = regexp.MustCompile(`(?m)^(\s*[^\s:]+\.go):[0-9]+(:[0-9]+)?:`)
+	// linesPos matches the line range of a duplicate's other copy, as in
+	// "a.go:10-40".
+	linesPos = regexp.MustCompile(`(\.go):[0-9]+-[0-9]+`)
+)
+
+// positionFree returns msg without the file positions it quotes.
+func positionFree(msg string) string {
+	msg = messagePos.ReplaceAllString(msg, "$1:")
+	return linesPos.ReplaceAllString(msg, "$1")
+}
+
+// Diff compares the findings and metrics of two runs of the same kind.
+type Diff struct {
+	Old     string        `json:"old"`
+	New     string        `json:"new"`
+	Kind    Kind          `json:"kind"`
+	Sources []SourceDiff  `json:"sources,omitempty"`
+	Metrics []MetricDelta `json:"metrics,omitempty"`
+
+	// Unmeasured lists the metrics left out of Metrics because one of the
+	// runs did not measure them.
+	Unmeasured []string `json:"unmeasured,omitempty"`
+}
+
+// SourceDiff holds the findings of one diagnostic source, split by whether
+// they appeared, disappeared or persisted between the two runs. Findings
+// of a step that did not run to completion in both runs cannot be
+// compared, and are listed as Unmeasured instead.
+type SourceDiff struct {
+	Source     string       `json:"source"`
+	New        []Diagnostic `json:"new,omitempty"`
+	Resolved   []Diagnostic `json:"resolved,omitempty"`
+	Unchanged  []Diagnostic `json:"unchanged,omitempty"`
+	Unmeasured []Diagnostic `json:"unmeasured,omitempty"`
+}
+
+// MetricDelta holds a metric value in both runs.
+type MetricDelta struct {
+	Name string  `json:"name"`
+	Old  float64 `json:"old"`
+	New  float64 `json:"new"`
+}
+
+// Delta returns New - Old.
+func (m MetricDelta) Delta() float64 {
+	return m.New - m.Old
+}
+
+// Totals returns the number of new, resolved and unchanged findings
+// across all sources.
+func (d *Diff) Totals() (added, resolved, unchanged int) {
+	for _, s := range d.Sources {
+		added += len(s.New)
+		resolved += len(s.Resolved)
+		unchanged += len(s.Unchanged)
+	}
+	return added, resolved, unchanged
+}
+
+// Compare diffs two runs by diagnostic fingerprint. Findings that occur
+// several times with the same fingerprint are matched one-for-one. Only
+// the findings and metrics of steps that ran to completion in both runs
+// are compared, so that a step skipped after a failure does not make its
+// findings look new or resolved.
+func Compare(oldRun, newRun *RunResult, complexityThreshold int) (*Diff, error) {
+	if oldRun.Kind != newRun.Kind {
+		return nil, fmt.Errorf("cannot diff %s run %s against %s run %s", oldRun.Kind, oldRun.ID, newRun.Kind, newRun.ID)
+	}
+
+	d := &Diff{Old: oldRun.ID, New: newRun.ID, Kind: newRun.Kind}
+
+	var order []string
+	bySource := make(map[string]*SourceDiff)
+	source := func(name string) *SourceDiff {
+		if s, ok := bySource[name]; ok {
+			return s
+		}
+		order = append(order, name)
+		s := &SourceDiff{Source: name}
+		bySource[name] = s
+		return s
+	}
+
+	oldRan, newRan := completedSteps(oldRun), completedSteps(newRun)
+	measured := func(source string) bool {
+		step := diagnosticSteps[source]
+		return (oldRan == nil || oldRan[step]) && (newRan == nil || newRan[step])
+	}
+
+	// Index old findings by fingerprint, then consume them with new ones.
+	pending := make(map[string][]Diagnostic)
+	var oldKeys []string
+	for _, diag := range Diagnostics(oldRun) {
+		if metricSources[diag.Source] {
+			continue
+		}
+		if !measured(diag.Source) {
+			s := source(diag.Source)
+			s.Unmeasured = append(s.Unmeasured, diag)
+			continue
+		}
+		fp := Fingerprint(diag)
+		if _, ok := pending[fp]; !ok {
+			oldKeys = append(oldKeys, fp)
+		}
+		pending[fp] = append(pending[fp], diag)
+		source(diag.Source)
+	}
+
+	for _, diag := range Diagnostics(newRun) {
+		if metricSources[diag.Source] {
+			continue
+		}
+		s := source(diag.Source)
+		if !measured(diag.Source) {
+			s.Unmeasured = append(s.Unmeasured, diag)
+			continue
+		}
+		fp := Fingerprint(diag)
+		if olds := pending[fp]; len(olds) > 0 {
+			pending[fp] = olds[1:]
+			s.Unchanged = append(s.Unchanged, diag)
+		} else {
+			s.New = append(s.New, diag)
+		}
+	}
+
+	for _, fp := range oldKeys {
+		for _, diag := range pending[fp] {
+			s := source(diag.Source)
+			s.Resolved = append(s.Resolved, diag)
+		}
+	}
+
+	for _, name := range order {
+		d.Sources = append(d.Sources, *bySource[name])
+	}
+
+	oldMetrics := ComputeMetrics(oldRun, complexityThreshold)
+	newMetrics := ComputeMetrics(newRun, complexityThreshold)
+	oldMeasured, newMeasured := Measured(oldRun), Measured(newRun)
+	for _, name := range MetricNames(d.Kind) {
+		if !oldMeasured[name] || !newMeasured[name] {
+			d.Unmeasured = append(d.Unmeasured, name)
+			continue
+		}
+		d.Metrics = append(d.Metrics, MetricDelta{Name: name, Old: oldMetrics[name], New: newMetrics[name]})
+	}
+
+	return d, nil
+}
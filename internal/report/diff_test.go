@@ -0,0 +1,207 @@
+package report
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFingerprint_IgnoresPosition(t *testing.T) {
+	a := Diagnostic{Source: "lint", Package: "p", File: "p/a.go", Line: 10, Col: 2, Detail: "errcheck", Message: "unchecked error"}
+	b := a
+	b.Line, b.Col = 42, 7
+	if Fingerprint(a) != Fingerprint(b) {
+		t.Error("fingerprint should not depend on line or column")
+	}
+	c := a
+	c.Message = "other"
+	if Fingerprint(a) == Fingerprint(c) {
+		t.Error("fingerprint should depend on message")
+	}
+}
+
+func TestFingerprint_IgnoresPositionsInMessage(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		old  *RunResult
+		new  *RunResult
+	}{
+		{
+			name: "dupl",
+			old:  &RunResult{Duplicates: []Duplicate{{File1: "a.go", StartLine1: 10, EndLine1: 40, File2: "b.go", StartLine2: 5, EndLine2: 35, Tokens: 120}}},
+			new:  &RunResult{Duplicates: []Duplicate{{File1: "a.go", StartLine1: 13, EndLine1: 43, File2: "b.go", StartLine2: 9, EndLine2: 39, Tokens: 120}}},
+		},
+		{
+			name: "test",
+			old:  &RunResult{TestFailures: []TestFailure{{Package: "p", Test: "TestA", File: "p/a_test.go", Line: 12, Message: "a_test.go:12: got 1, want 2"}}},
+			new:  &RunResult{TestFailures: []TestFailure{{Package: "p", Test: "TestA", File: "p/a_test.go", Line: 15, Message: "a_test.go:15: got 1, want 2"}}},
+		},
+		{
+			name: "build",
+			old:  &RunResult{BuildErrors: []BuildError{{Package: "p/q", File: "p/q/a.go", Line: 3, Col: 2, Message: "# p/q\n./a.go:3:2: undefined: x\n./a.go:9:5: undefined: y"}}},
+			new:  &RunResult{BuildErrors: []BuildError{{Package: "p/q", File: "p/q/a.go", Line: 5, Col: 2, Message: "# p/q\n./a.go:5:2: undefined: x\n./a.go:11:5: undefined: y"}}},
+		},
+	} {
+		oldDiags, newDiags := Diagnostics(tc.old), Diagnostics(tc.new)
+		if Fingerprint(oldDiags[0]) != Fingerprint(newDiags[0]) {
+			t.Errorf("%s: fingerprint changed when the finding moved:\n%q\n%q", tc.name, oldDiags[0].Message, newDiags[0].Message)
+		}
+	}
+
+	a := Diagnostic{Source: "build", Message: "./a.go:3:2: undefined: x"}
+	b := Diagnostic{Source: "build", Message: "./a.go:3:2: undefined: y"}
+	if Fingerprint(a) == Fingerprint(b) {
+		t.Error("fingerprint should depend on the message after its position")
+	}
+}
+
+func TestCompare_Check(t *testing.T) {
+	oldRun := &RunResult{
+		ID:   "old",
+		Kind: Check,
+		LintIssues: []LintIssue{
+			{File: "p/a.go", Line: 3, Linter: "errcheck", Message: "unchecked error"},
+			{File: "p/a.go", Line: 9, Linter: "errcheck", Message: "unchecked error"},
+			{File: "p/b.go", Line: 1, Linter: "unused", Message: "func x is unused"},
+		},
+		TestFailures: []TestFailure{{Package: "p", Test: "TestA", Message: "boom"}},
+	}
+	newRun := &RunResult{
+		ID:   "new",
+		Kind: Check,
+		LintIssues: []LintIssue{
+			// Same finding, moved down by an edit.
+			{File: "p/a.go", Line: 5, Linter: "errcheck", Message: "unchecked error"},
+			{File: "p/c.go", Line: 2, Linter: "govet", Message: "printf verb"},
+		},
+	}
+
+	d, err := Compare(oldRun, newRun, 15)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+
+	added, resolved, unchanged := d.Totals()
+	if added != 1 || resolved != 3 || unchanged != 1 {
+		t.Errorf("Totals = %d new, %d resolved, %d unchanged; want 1, 3, 1", added, resolved, unchanged)
+	}
+
+	sources := make(map[string]SourceDiff)
+	for _, s := range d.Sources {
+		sources[s.Source] = s
+	}
+	if lint := sources["lint"]; len(lint.New) != 1 || len(lint.Resolved) != 2 || len(lint.Unchanged) != 1 {
+		t.Errorf("lint = %d new, %d resolved, %d unchanged; want 1, 2, 1", len(lint.New), len(lint.Resolved), len(lint.Unchanged))
+	}
+	if test := sources["test"]; len(test.Resolved) != 1 {
+		t.Errorf("test resolved = %d, want 1", len(test.Resolved))
+	}
+
+	for _, m := range d.Metrics {
+		if m.Name == MetricLintIssues && (m.Old != 3 || m.New != 2) {
+			t.Errorf("lint_issues delta = %v -> %v, want 3 -> 2", m.Old, m.New)
+		}
+	}
+}
+
+func TestCompare_AuditMetrics(t *testing.T) {
+	oldRun := &RunResult{
+		ID:   "old",
+		Kind: Audit,
+		Coverage: []CoverageEntry{
+			{Package: "p", Function: "A", Coverage: 50},
+			{Package: "p", Function: "B", Coverage: 100},
+		},
+		Complexity: []ComplexityEntry{{Package: "p", Function: "A", Complexity: 20}},
+		Vulns:      []Vuln{{ID: "GO-2024-0001"}},
+	}
+	newRun := &RunResult{
+		ID:   "new",
+		Kind: Audit,
+		Coverage: []CoverageEntry{
+			{Package: "p", Function: "A", Coverage: 100},
+			{Package: "p", Function: "B", Coverage: 100},
+		},
+		Complexity: []ComplexityEntry{{Package: "p", Function: "A", Complexity: 12}},
+	}
+
+	d, err := Compare(oldRun, newRun, 15)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+
+	want := map[string][2]float64{
+		MetricCoverage:      {75, 100},
+		MetricComplexFuncs:  {1, 0},
+		MetricMaxComplexity: {20, 12},
+		MetricVulns:         {1, 0},
+	}
+	for _, m := range d.Metrics {
+		if w, ok := want[m.Name]; ok && (m.Old != w[0] || m.New != w[1]) {
+			t.Errorf("%s = %v -> %v, want %v -> %v", m.Name, m.Old, m.New, w[0], w[1])
+		}
+	}
+
+	// Coverage and complexity are metrics, not findings.
+	for _, s := range d.Sources {
+		if s.Source == "coverage" || s.Source == "complexity" {
+			t.Errorf("unexpected findings source %q", s.Source)
+		}
+	}
+}
+
+func TestCompare_Unmeasured(t *testing.T) {
+	// The old run stopped after lint failed, so staticcheck never ran.
+	oldRun := &RunResult{
+		ID:         "old",
+		Kind:       Check,
+		LintIssues: []LintIssue{{File: "p/a.go", Line: 3, Linter: "errcheck", Message: "unchecked error"}},
+		Steps: []StepRecord{
+			{Name: "format", Status: "pass"},
+			{Name: "lint", Status: "fail"},
+			{Name: "staticcheck", Status: "skipped"},
+			{Name: "test", Status: "skipped"},
+		},
+	}
+	newRun := &RunResult{
+		ID:           "new",
+		Kind:         Check,
+		StaticIssues: []StaticIssue{{File: "p/a.go", Line: 7, Code: "SA4006", Message: "value never used"}},
+		Steps: []StepRecord{
+			{Name: "format", Status: "pass"},
+			{Name: "lint", Status: "pass"},
+			{Name: "staticcheck", Status: "fail"},
+			{Name: "test", Status: "pass"},
+		},
+	}
+
+	d, err := Compare(oldRun, newRun, 15)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+
+	added, resolved, _ := d.Totals()
+	if added != 0 || resolved != 1 {
+		t.Errorf("Totals = %d new, %d resolved; want 0, 1", added, resolved)
+	}
+	for _, s := range d.Sources {
+		if s.Source == "staticcheck" && (len(s.New) != 0 || len(s.Unmeasured) != 1) {
+			t.Errorf("staticcheck = %d new, %d unmeasured; want 0, 1", len(s.New), len(s.Unmeasured))
+		}
+	}
+	for _, m := range d.Metrics {
+		switch m.Name {
+		case MetricStaticIssues, MetricTestFailures, MetricBuildErrors:
+			t.Errorf("unexpected delta for unmeasured metric %s", m.Name)
+		}
+	}
+	if want := []string{MetricBuildErrors, MetricTestFailures, MetricStaticIssues}; !slices.Equal(d.Unmeasured, want) {
+		t.Errorf("Unmeasured = %v, want %v", d.Unmeasured, want)
+	}
+}
+
+func TestCompare_KindMismatch(t *testing.T) {
+	_, err := Compare(&RunResult{ID: "a", Kind: Check}, &RunResult{ID: "b", Kind: Audit}, 15)
+	if err == nil {
+		t.Error("expected error comparing check and audit runs")
+	}
+}
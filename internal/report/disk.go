@@ -2,49 +2,83 @@ package report
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/deixis/governor/internal/flock"
 )
 
-// DiskStore writes RunResult as JSON files to a lazily-created temp directory.
+// indexFile is the name of the run index within a DiskStore directory.
+const indexFile = "index.json"
+
+// DiskStore persists RunResults as JSON files in a directory, alongside
+// an index of run metadata. The directory outlives the process so that
+// runs are shared between CLI invocations and MCP sessions. Concurrent
+// writers, including other processes, are serialised with a lock file.
 type DiskStore struct {
-	mu  sync.Mutex
-	dir string
+	mu        sync.Mutex
+	dir       string
+	retention Retention
 }
 
-// NewDiskStore creates a new DiskStore. The underlying temp directory
-// is created lazily on the first Save.
-func NewDiskStore() *DiskStore {
-	return &DiskStore{}
+// NewDiskStore creates a DiskStore rooted at dir. The directory is
+// created lazily on the first Save. Runs falling outside retention are
+// pruned after every Save.
+func NewDiskStore(dir string, retention Retention) *DiskStore {
+	return &DiskStore{dir: dir, retention: retention}
 }
 
-// Save writes a RunResult as a JSON file to disk.
+// Dir returns the storage directory.
+func (s *DiskStore) Dir() string {
+	return s.dir
+}
+
+// Save writes a RunResult as a JSON file to disk and records it in the index.
 func (s *DiskStore) Save(result *RunResult) error {
-	dir, err := s.ensureDir()
-	if err != nil {
-		return err
-	}
 	data, err := json.Marshal(result)
 	if err != nil {
 		return fmt.Errorf("marshalling result %s: %w", result.ID, err)
 	}
-	path := filepath.Join(dir, result.ID+".json")
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := writeFileAtomic(s.runPath(result.ID), data); err != nil {
 		return fmt.Errorf("writing result %s: %w", result.ID, err)
 	}
-	return nil
+
+	entries, err := s.readIndex()
+	if err != nil {
+		return err
+	}
+	entry := NewEntry(result)
+	entry.Size = int64(len(data)) + dirSize(filepath.Join(LogDir(s.dir), result.ID))
+	entries = append(removeEntry(entries, result.ID), entry)
+
+	for _, e := range s.retention.Expired(entries, time.Now()) {
+		if err := s.removeRun(e.ID); err != nil {
+			return err
+		}
+		entries = removeEntry(entries, e.ID)
+	}
+
+	return s.writeIndex(entries)
 }
 
 // Load reads a RunResult from disk.
 func (s *DiskStore) Load(runID string) (*RunResult, error) {
-	dir, err := s.ensureDir()
-	if err != nil {
-		return nil, err
+	if !validRunID(runID) {
+		return nil, fmt.Errorf("invalid run ID %q", runID)
 	}
-	path := filepath.Join(dir, runID+".json")
-	data, err := os.ReadFile(path)
+	data, err := os.ReadFile(s.runPath(runID))
 	if err != nil {
 		return nil, fmt.Errorf("reading result %s: %w", runID, err)
 	}
@@ -55,16 +89,147 @@ func (s *DiskStore) Load(runID string) (*RunResult, error) {
 	return &result, nil
 }
 
-func (s *DiskStore) ensureDir() (string, error) {
+// List returns the indexed runs matching filter, most recent first.
+func (s *DiskStore) List(filter Filter) ([]Entry, error) {
+	entries, err := s.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	var out []Entry
+	for _, e := range entries {
+		if filter.Match(e) {
+			out = append(out, e)
+		}
+	}
+	sortNewestFirst(out)
+	return out, nil
+}
+
+// Delete removes a stored run and its index entry.
+func (s *DiskStore) Delete(runID string) error {
+	if !validRunID(runID) {
+		return fmt.Errorf("invalid run ID %q", runID)
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.removeRun(runID); err != nil {
+		return err
+	}
+	entries, err := s.readIndex()
+	if err != nil {
+		return err
+	}
+	return s.writeIndex(removeEntry(entries, runID))
+}
+
+func (s *DiskStore) runPath(runID string) string {
+	return filepath.Join(s.dir, runID+".json")
+}
+
+// removeRun deletes the stored result for runID and its step logs. A
+// missing file is not an error.
+func (s *DiskStore) removeRun(runID string) error {
+	if err := os.Remove(s.runPath(runID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing result %s: %w", runID, err)
+	}
+	if err := os.RemoveAll(filepath.Join(LogDir(s.dir), runID)); err != nil {
+		return fmt.Errorf("removing logs of %s: %w", runID, err)
+	}
+	return nil
+}
+
+// lock creates the store directory if needed and takes the store lock,
+// excluding both other goroutines and other processes.
+func (s *DiskStore) lock() (func(), error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating result directory: %w", err)
+	}
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	if s.dir != "" {
-		return s.dir, nil
+	f, err := os.OpenFile(filepath.Join(s.dir, ".lock"), os.O_RDWR|os.O_CREATE, 0o644)
+	if err == nil {
+		if err = flock.Lock(f, true); err != nil {
+			_ = f.Close()
+		}
+	}
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("locking result directory: %w", err)
+	}
+	return func() {
+		_ = flock.Unlock(f)
+		_ = f.Close()
+		s.mu.Unlock()
+	}, nil
+}
+
+// readIndex returns the index entries. A missing index is empty.
+func (s *DiskStore) readIndex() ([]Entry, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, indexFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading run index: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing run index: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *DiskStore) writeIndex(entries []Entry) error {
+	sortNewestFirst(entries)
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling run index: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(s.dir, indexFile), data); err != nil {
+		return fmt.Errorf("writing run index: %w", err)
+	}
+	return nil
+}
+
+func removeEntry(entries []Entry, runID string) []Entry {
+	out := entries[:0]
+	for _, e := range entries {
+		if e.ID != runID {
+			out = append(out, e)
+		}
 	}
-	dir, err := os.MkdirTemp("", "governor-runs-*")
+	return out
+}
+
+// validRunID reports whether runID is safe to use as a file name.
+func validRunID(runID string) bool {
+	return runID != "" && !strings.ContainsAny(runID, `/\`) && runID != "." && runID != ".."
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory
+// and renames it into place, so readers never observe a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
 	if err != nil {
-		return "", fmt.Errorf("creating result directory: %w", err)
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, 0o644); err != nil {
+		_ = os.Remove(tmp)
+		return err
 	}
-	s.dir = dir
-	return dir, nil
+	return os.Rename(tmp, path)
 }
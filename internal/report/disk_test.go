@@ -0,0 +1,195 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiskStore_PersistsAcrossInstances(t *testing.T) {
+	dir := t.TempDir()
+	started := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+
+	s1 := NewDiskStore(dir, Retention{})
+	err := s1.Save(&RunResult{
+		ID:       "run-1",
+		Kind:     Check,
+		Started:  started,
+		Commit:   "abc123",
+		Packages: []string{"./..."},
+		Status:   "fail",
+		LintIssues: []LintIssue{
+			{File: "foo/bar.go", Line: 3, Linter: "errcheck", Message: "unchecked"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// A second store on the same directory (e.g. another process) sees the run.
+	s2 := NewDiskStore(dir, Retention{})
+	got, err := s2.Load("run-1")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got.Kind != Check || got.Commit != "abc123" || len(got.LintIssues) != 1 {
+		t.Errorf("Load = %+v, want check run with commit and one lint issue", got)
+	}
+
+	entries := readIndexFile(t, dir)
+	if len(entries) != 1 {
+		t.Fatalf("index has %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.ID != "run-1" || e.Status != "fail" || e.Commit != "abc123" || !e.Started.Equal(started) {
+		t.Errorf("index entry = %+v", e)
+	}
+	if e.Size <= 0 {
+		t.Errorf("index entry Size = %d, want > 0", e.Size)
+	}
+}
+
+func TestDiskStore_RetentionMaxRuns(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDiskStore(dir, Retention{MaxRuns: 2})
+	now := time.Now()
+
+	for i, id := range []string{"a", "b", "c"} {
+		err := s.Save(&RunResult{ID: id, Kind: Audit, Started: now.Add(time.Duration(i) * time.Second)})
+		if err != nil {
+			t.Fatalf("Save(%s): %v", id, err)
+		}
+	}
+
+	if _, err := s.Load("a"); err == nil {
+		t.Error("oldest run should have been pruned")
+	}
+	for _, id := range []string{"b", "c"} {
+		if _, err := s.Load(id); err != nil {
+			t.Errorf("Load(%s): %v", id, err)
+		}
+	}
+	if n := len(readIndexFile(t, dir)); n != 2 {
+		t.Errorf("index has %d entries, want 2", n)
+	}
+}
+
+func TestDiskStore_LoadRejectsPaths(t *testing.T) {
+	s := NewDiskStore(t.TempDir(), Retention{})
+	if _, err := s.Load("../escape"); err == nil {
+		t.Error("expected error for run ID containing a path separator")
+	}
+}
+
+func TestRetention_Expired(t *testing.T) {
+	now := time.Now()
+	entries := []Entry{
+		{ID: "new", Started: now.Add(-time.Hour), Size: 10},
+		{ID: "mid", Started: now.Add(-48 * time.Hour), Size: 10},
+		{ID: "old", Started: now.Add(-30 * 24 * time.Hour), Size: 10},
+	}
+
+	tests := []struct {
+		name string
+		r    Retention
+		want []string
+	}{
+		{"unlimited", Retention{}, nil},
+		{"max runs", Retention{MaxRuns: 1}, []string{"mid", "old"}},
+		{"max age", Retention{MaxAge: 7 * 24 * time.Hour}, []string{"old"}},
+		{"max size", Retention{MaxSize: 25}, []string{"old"}},
+		{"max size keeps newest", Retention{MaxSize: 5}, []string{"mid", "old"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range tt.r.Expired(entries, now) {
+				got = append(got, e.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expired = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expired = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func readIndexFile(t *testing.T, dir string) []Entry {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		t.Fatalf("reading index: %v", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("parsing index: %v", err)
+	}
+	return entries
+}
+
+func TestDiskStore_ListAndDelete(t *testing.T) {
+	s := NewDiskStore(t.TempDir(), Retention{})
+	now := time.Now()
+	runs := []*RunResult{
+		{ID: "c1", Kind: Check, Started: now.Add(-3 * time.Hour)},
+		{ID: "a1", Kind: Audit, Started: now.Add(-2 * time.Hour)},
+		{ID: "c2", Kind: Check, Started: now.Add(-time.Hour)},
+	}
+	for _, r := range runs {
+		if err := s.Save(r); err != nil {
+			t.Fatalf("Save(%s): %v", r.ID, err)
+		}
+	}
+
+	all, err := s.List(Filter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if ids := entryIDs(all); ids != "c2,a1,c1" {
+		t.Errorf("List = %s, want newest first c2,a1,c1", ids)
+	}
+
+	checks, _ := s.List(Filter{Kind: Check, Since: now.Add(-150 * time.Minute)})
+	if ids := entryIDs(checks); ids != "c2" {
+		t.Errorf("List(check, since) = %s, want c2", ids)
+	}
+
+	if err := s.Delete("a1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Load("a1"); err == nil {
+		t.Error("Load after Delete should fail")
+	}
+	all, _ = s.List(Filter{})
+	if ids := entryIDs(all); ids != "c2,c1" {
+		t.Errorf("List after Delete = %s, want c2,c1", ids)
+	}
+}
+
+func TestLRUStore_DeleteEvictsCache(t *testing.T) {
+	s := NewLRUStore(5, NewDiskStore(t.TempDir(), Retention{}))
+	if err := s.Save(&RunResult{ID: "r1", Kind: Check, Started: time.Now()}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := s.Delete("r1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Load("r1"); err == nil {
+		t.Error("Load after Delete should miss both cache and disk")
+	}
+}
+
+func entryIDs(entries []Entry) string {
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return strings.Join(ids, ",")
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"sort"
+	"time"
+)
+
+// Entry is the index record of a stored run. It carries enough metadata
+// to list and filter runs without loading the full result.
+type Entry struct {
+	ID       string    `json:"id"`
+	Kind     Kind      `json:"kind"`
+	Started  time.Time `json:"started"`
+	Commit   string    `json:"commit,omitempty"`
+	Packages []string  `json:"packages,omitempty"`
+	Status   string    `json:"status,omitempty"`
+	Size     int64     `json:"size"` // bytes on disk
+}
+
+// NewEntry builds the index record for result.
+func NewEntry(result *RunResult) Entry {
+	return Entry{
+		ID:       result.ID,
+		Kind:     result.Kind,
+		Started:  result.Started,
+		Commit:   result.Commit,
+		Packages: result.Packages,
+		Status:   result.Status,
+	}
+}
+
+// Retention bounds how many runs are kept. Zero fields are unlimited.
+type Retention struct {
+	MaxRuns int
+	MaxAge  time.Duration
+	MaxSize int64 // total bytes
+}
+
+// Expired returns the entries that fall outside the retention policy
+// at time now. Newer runs are always kept in preference to older ones.
+func (r Retention) Expired(entries []Entry, now time.Time) []Entry {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sortNewestFirst(sorted)
+
+	var expired []Entry
+	var size int64
+	kept := 0
+	for _, e := range sorted {
+		switch {
+		case r.MaxRuns > 0 && kept >= r.MaxRuns,
+			r.MaxAge > 0 && now.Sub(e.Started) > r.MaxAge,
+			r.MaxSize > 0 && size+e.Size > r.MaxSize && kept > 0:
+			expired = append(expired, e)
+		default:
+			kept++
+			size += e.Size
+		}
+	}
+	return expired
+}
+
+// sortNewestFirst orders entries by start time, most recent first.
+func sortNewestFirst(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Started.After(entries[j].Started)
+	})
+}
@@ -0,0 +1,136 @@
+package report
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// logsDir is the name of the directory holding the complete output of
+// steps, within a DiskStore directory.
+const logsDir = "logs"
+
+// LogDir returns the directory holding the step logs of the runs stored in
+// the DiskStore directory storeDir. Each run has its own subdirectory,
+// removed with the run.
+func LogDir(storeDir string) string {
+	return filepath.Join(storeDir, logsDir)
+}
+
+// LogPath returns the path of the log of step in run runID under logDir.
+func LogPath(logDir, runID, step string) string {
+	return filepath.Join(logDir, runID, step+".log")
+}
+
+// LogPage is a range of lines of a log.
+type LogPage struct {
+	Lines []string
+	From  int // line number of Lines[0], from 1
+	Total int // lines in the log
+}
+
+// To returns the line number of the last line of the page, or From-1 if it
+// is empty.
+func (p *LogPage) To() int {
+	return p.From + len(p.Lines) - 1
+}
+
+// ReadLog returns up to n lines of the log at path, starting at line from.
+// Lines are numbered from 1; a negative from counts from the end, so that
+// -n returns the last n lines.
+func ReadLog(path string, from, n int) (*LogPage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("log %s not found (it may have been pruned with its run)", filepath.Base(path))
+		}
+		return nil, fmt.Errorf("opening log: %w", err)
+	}
+	defer f.Close()
+
+	// Count the lines first when paging from the end.
+	total := -1
+	if from < 0 {
+		total, err = countLines(f)
+		if err != nil {
+			return nil, err
+		}
+		from = max(total+from+1, 1)
+		if _, err := f.Seek(0, 0); err != nil {
+			return nil, fmt.Errorf("reading log: %w", err)
+		}
+	}
+	from = max(from, 1)
+
+	page := &LogPage{From: from}
+	sc := newLogScanner(f)
+	line := 0
+	for sc.Scan() {
+		line++
+		if line >= from && len(page.Lines) < n {
+			page.Lines = append(page.Lines, sc.Text())
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading log: %w", err)
+	}
+	page.Total = line
+	return page, nil
+}
+
+func countLines(f *os.File) (int, error) {
+	sc := newLogScanner(f)
+	n := 0
+	for sc.Scan() {
+		n++
+	}
+	if err := sc.Err(); err != nil {
+		return 0, fmt.Errorf("reading log: %w", err)
+	}
+	return n, nil
+}
+
+// newLogScanner returns a line scanner accepting the long lines some
+// tools print.
+func newLogScanner(f *os.File) *bufio.Scanner {
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	return sc
+}
+
+// dirSize returns the total size of the files under dir, or 0 if it does
+// not exist.
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && !d.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// StepLog returns the path of the log of step in r.
+func (r *RunResult) StepLog(step string) (string, error) {
+	for _, s := range r.Steps {
+		if s.Name != step {
+			continue
+		}
+		if s.Log == "" {
+			return "", fmt.Errorf("step %s of run %s has no log", step, r.ID)
+		}
+		return s.Log, nil
+	}
+	names := make([]string, len(r.Steps))
+	for i, s := range r.Steps {
+		names[i] = s.Name
+	}
+	return "", fmt.Errorf("run %s has no step %s (steps: %s)", r.ID, step, strings.Join(names, ", "))
+}
@@ -0,0 +1,95 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeLog(t *testing.T, path string, lines int) {
+	t.Helper()
+	var b strings.Builder
+	for i := 1; i <= lines; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writeLog(t, path, 10)
+
+	tests := []struct {
+		from, n  int
+		wantFrom int
+		want     []string
+	}{
+		{1, 3, 1, []string{"line 1", "line 2", "line 3"}},
+		{9, 5, 9, []string{"line 9", "line 10"}},
+		{0, 1, 1, []string{"line 1"}},
+		{-2, 5, 9, []string{"line 9", "line 10"}},
+		{-20, 2, 1, []string{"line 1", "line 2"}},
+		{11, 5, 11, nil},
+	}
+	for _, tt := range tests {
+		page, err := ReadLog(path, tt.from, tt.n)
+		if err != nil {
+			t.Fatalf("ReadLog(%d, %d): %v", tt.from, tt.n, err)
+		}
+		if page.From != tt.wantFrom || page.Total != 10 || !reflect.DeepEqual(page.Lines, tt.want) {
+			t.Errorf("ReadLog(%d, %d) = %+v, want from %d, 10 lines, %q", tt.from, tt.n, page, tt.wantFrom, tt.want)
+		}
+	}
+
+	if _, err := ReadLog(filepath.Join(t.TempDir(), "missing.log"), 1, 1); err == nil || !strings.Contains(err.Error(), "pruned") {
+		t.Errorf("ReadLog(missing) error = %v, want not found", err)
+	}
+}
+
+func TestStepLog(t *testing.T) {
+	rr := &RunResult{ID: "r", Steps: []StepRecord{
+		{Name: "test", Log: "/logs/r/test.log"},
+		{Name: "lint"},
+	}}
+	if path, err := rr.StepLog("test"); err != nil || path != "/logs/r/test.log" {
+		t.Errorf("StepLog(test) = %q, %v", path, err)
+	}
+	if _, err := rr.StepLog("lint"); err == nil {
+		t.Error("StepLog(lint) succeeded for a step without a log")
+	}
+	if _, err := rr.StepLog("vet"); err == nil || !strings.Contains(err.Error(), "steps: test, lint") {
+		t.Errorf("StepLog(vet) error = %v, want the steps listed", err)
+	}
+}
+
+func TestDiskStore_RemovesLogsWithRun(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDiskStore(dir, Retention{MaxRuns: 1})
+	now := time.Now()
+
+	logPath := LogPath(LogDir(dir), "a", "test")
+	writeLog(t, logPath, 100)
+	if err := s.Save(&RunResult{ID: "a", Kind: Check, Started: now}); err != nil {
+		t.Fatalf("Save(a): %v", err)
+	}
+	if e := readIndexFile(t, dir)[0]; e.Size < 100 {
+		t.Errorf("index entry Size = %d, want the log included", e.Size)
+	}
+
+	// Saving b prunes a, and its logs with it.
+	if err := s.Save(&RunResult{ID: "b", Kind: Check, Started: now.Add(time.Second)}); err != nil {
+		t.Fatalf("Save(b): %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(logPath)); !os.IsNotExist(err) {
+		t.Errorf("logs of pruned run still exist: %v", err)
+	}
+}
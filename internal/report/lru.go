@@ -91,6 +91,23 @@ func (s *LRUStore) Load(runID string) (*RunResult, error) {
 	return result, nil
 }
 
+// List delegates to the backing store, which holds the full index.
+func (s *LRUStore) List(filter Filter) ([]Entry, error) {
+	return s.back.List(filter)
+}
+
+// Delete drops the result from the cache and the backing store.
+func (s *LRUStore) Delete(runID string) error {
+	s.mu.Lock()
+	if e, ok := s.items[runID]; ok {
+		s.remove(e)
+		delete(s.items, runID)
+	}
+	s.mu.Unlock()
+
+	return s.back.Delete(runID)
+}
+
 func (s *LRUStore) pushFront(e *lruEntry) {
 	e.prev = nil
 	e.next = s.head
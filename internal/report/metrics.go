@@ -0,0 +1,155 @@
+package report
+
+// Metric names, shared by run diffs and trends.
+const (
+	MetricCoverage      = "coverage"       // statement coverage, percent
+	MetricComplexFuncs  = "complex_funcs"  // functions above the complexity threshold
+	MetricMaxComplexity = "max_complexity" // highest cognitive complexity
+	MetricDuplicates    = "duplicates"     // duplicate block pairs
+	MetricDeadFuncs     = "dead_funcs"     // unreachable functions
+	MetricVulns         = "vulns"          // reachable vulnerabilities
+	MetricBuildErrors   = "build_errors"
+	MetricTestFailures  = "test_failures"
+	MetricLintIssues    = "lint_issues"
+	MetricStaticIssues  = "static_issues"
+)
+
+// checkMetrics and auditMetrics list the metrics measured by each kind
+// of run, in display order.
+var (
+	checkMetrics = []string{MetricBuildErrors, MetricTestFailures, MetricLintIssues, MetricStaticIssues}
+	auditMetrics = []string{MetricCoverage, MetricComplexFuncs, MetricMaxComplexity, MetricDuplicates, MetricDeadFuncs, MetricVulns}
+)
+
+// MetricNames returns the names of the metrics measured by runs of kind.
+func MetricNames(kind Kind) []string {
+	switch kind {
+	case Check:
+		return checkMetrics
+	case Audit:
+		return auditMetrics
+	}
+	return nil
+}
+
+// metricSteps maps each metric to the step that measures it.
+var metricSteps = map[string]string{
+	MetricCoverage:      "coverage",
+	MetricComplexFuncs:  "complexity",
+	MetricMaxComplexity: "complexity",
+	MetricDuplicates:    "dupl",
+	MetricDeadFuncs:     "deadcode",
+	MetricVulns:         "vulncheck",
+	MetricBuildErrors:   "test",
+	MetricTestFailures:  "test",
+	MetricLintIssues:    "lint",
+	MetricStaticIssues:  "staticcheck",
+}
+
+// Measured reports which metrics r actually measured. A metric counts as
+// measured only if the step producing it ran to completion; a step that
+// was skipped, unavailable or errored yields no data rather than a zero.
+// Runs recorded without step outcomes fall back to the metrics of their kind.
+func Measured(r *RunResult) map[string]bool {
+	out := make(map[string]bool)
+	ran := completedSteps(r)
+	for _, name := range MetricNames(r.Kind) {
+		if ran == nil || ran[metricSteps[name]] {
+			out[name] = true
+		}
+	}
+	return out
+}
+
+// diagnosticSteps maps each diagnostic source to the step that finds it.
+var diagnosticSteps = map[string]string{
+	"format":      "format",
+	"build":       "test",
+	"test":        "test",
+	"lint":        "lint",
+	"staticcheck": "staticcheck",
+	"coverage":    "coverage",
+	"complexity":  "complexity",
+	"deadcode":    "deadcode",
+	"dupl":        "dupl",
+	"vulncheck":   "vulncheck",
+}
+
+// completedSteps returns the steps of r that ran to completion, or nil for
+// a run recorded without step outcomes. The fix phase counts as format.
+func completedSteps(r *RunResult) map[string]bool {
+	if len(r.Steps) == 0 {
+		return nil
+	}
+	ran := make(map[string]bool)
+	for _, st := range r.Steps {
+		switch st.Status {
+		case "pass", "fail", "done":
+			ran[st.Name] = true
+			if st.Name == "fix" {
+				ran["format"] = true
+			}
+		}
+	}
+	return ran
+}
+
+// Metrics maps metric names to values for a single run.
+type Metrics map[string]float64
+
+// ComputeMetrics derives the code health metrics measured by r.
+// complexityThreshold is the score above which a function counts as complex.
+func ComputeMetrics(r *RunResult, complexityThreshold int) Metrics {
+	m := make(Metrics)
+
+	switch r.Kind {
+	case Check:
+		m[MetricBuildErrors] = float64(len(r.BuildErrors))
+		m[MetricTestFailures] = float64(len(r.TestFailures))
+		m[MetricLintIssues] = float64(len(r.LintIssues))
+		m[MetricStaticIssues] = float64(len(r.StaticIssues))
+
+	case Audit:
+		m[MetricCoverage] = coverage(r)
+
+		complexFuncs, maxComplexity := 0, 0
+		for _, c := range r.Complexity {
+			if c.Over(complexityThreshold) {
+				complexFuncs++
+			}
+			maxComplexity = max(maxComplexity, c.Complexity)
+		}
+		m[MetricComplexFuncs] = float64(complexFuncs)
+		m[MetricMaxComplexity] = float64(maxComplexity)
+		m[MetricDuplicates] = float64(len(r.Duplicates))
+		m[MetricDeadFuncs] = float64(len(r.DeadFuncs))
+		m[MetricVulns] = float64(len(r.Vulns))
+	}
+
+	return m
+}
+
+// coverage returns the percentage of the statements of r that ran, as the
+// total of go tool cover. Runs recorded without the statements of each
+// file fall back to the average function coverage.
+func coverage(r *RunResult) float64 {
+	if len(r.CoverageFiles) == 0 {
+		if len(r.Coverage) == 0 {
+			return 0
+		}
+		var sum float64
+		for _, c := range r.Coverage {
+			sum += c.Coverage
+		}
+		return sum / float64(len(r.Coverage))
+	}
+	var statements, covered int
+	for _, f := range r.CoverageFiles {
+		statements += f.Statements
+		covered += f.Covered
+	}
+	if statements == 0 {
+		return 0
+	}
+	return 100 * float64(covered) / float64(statements)
+}
@@ -0,0 +1,30 @@
+package report
+
+import "testing"
+
+func TestComputeMetrics_StatementCoverage(t *testing.T) {
+	r := &RunResult{
+		Kind: Audit,
+		// A small function left untested in a large, well-tested file.
+		Coverage: []CoverageEntry{
+			{Package: "example.com/m/p", Function: "Big", Coverage: 90},
+			{Package: "example.com/m/p", Function: "Small", Coverage: 0},
+		},
+		CoverageFiles: []CoverageFile{
+			{File: "example.com/m/p/a.go", Statements: 98, Covered: 88},
+			{File: "example.com/m/q/b.go", Statements: 2, Covered: 2},
+		},
+	}
+	if got := ComputeMetrics(r, 15)[MetricCoverage]; got != 90 {
+		t.Errorf("coverage = %v, want 90 (covered statements over all statements)", got)
+	}
+	if got := ComputeMetrics(Scope(r, "example.com/m/q"), 15)[MetricCoverage]; got != 100 {
+		t.Errorf("scoped coverage = %v, want 100", got)
+	}
+
+	// Runs recorded without statement counts average their functions.
+	r.CoverageFiles = nil
+	if got := ComputeMetrics(r, 15)[MetricCoverage]; got != 45 {
+		t.Errorf("coverage without files = %v, want 45", got)
+	}
+}
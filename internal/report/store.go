@@ -6,6 +6,7 @@ package report
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // Kind identifies the type of a run.
@@ -22,6 +23,27 @@ const (
 type Store interface {
 	Save(result *RunResult) error
 	Load(runID string) (*RunResult, error)
+	// List returns index entries matching filter, most recent first.
+	List(filter Filter) ([]Entry, error)
+	// Delete removes a stored run. Deleting an unknown run is not an error.
+	Delete(runID string) error
+}
+
+// Filter selects runs when listing a Store. Zero fields match everything.
+type Filter struct {
+	Kind  Kind
+	Since time.Time
+}
+
+// Match reports whether e satisfies the filter.
+func (f Filter) Match(e Entry) bool {
+	if f.Kind != "" && e.Kind != f.Kind {
+		return false
+	}
+	if !f.Since.IsZero() && e.Started.Before(f.Since) {
+		return false
+	}
+	return true
 }
 
 // RunResult holds the structured output from a tool run.
@@ -29,20 +51,99 @@ type RunResult struct {
 	ID   string `json:"id"`
 	Kind Kind   `json:"kind"`
 
+	// Run metadata.
+	Started          time.Time    `json:"started"`
+	Finished         time.Time    `json:"finished"`
+	Root             string       `json:"root,omitempty"`              // repository root the run was made in
+	Module           string       `json:"module,omitempty"`            // main module path
+	Commit           string       `json:"commit,omitempty"`            // git HEAD at the time of the run
+	Dirty            bool         `json:"dirty,omitempty"`             // true if the worktree had uncommitted changes
+	Packages         []string     `json:"packages,omitempty"`          // package patterns the run was scoped to
+	ResolvedPackages []string     `json:"resolved_packages,omitempty"` // import paths the patterns matched
+	Status           string       `json:"status,omitempty"`            // check: pass, fail; audit: done, error
+	Steps            []StepRecord `json:"steps,omitempty"`
+	GoVersion        string       `json:"go_version,omitempty"`
+	Tools            []ToolRecord `json:"tools,omitempty"`       // external tools invoked by the run
+	ConfigHash       string       `json:"config_hash,omitempty"` // hash of the effective configuration
+	Profile          string       `json:"profile,omitempty"`     // configuration profile applied, if any
+
 	// Validation fields.
 	AutoFixes    int           `json:"auto_fixes,omitempty"`
 	FormatIssues []FormatIssue `json:"format_issues,omitempty"`
 	BuildErrors  []BuildError  `json:"build_errors,omitempty"`
 	TestFailures []TestFailure `json:"test_failures,omitempty"`
+	Tests        []TestPackage `json:"tests,omitempty"` // every package and test case from go test -json
 	LintIssues   []LintIssue   `json:"lint_issues,omitempty"`
 	StaticIssues []StaticIssue `json:"static_issues,omitempty"`
 
 	// Audit fields.
-	Coverage   []CoverageEntry   `json:"coverage,omitempty"`
-	Complexity []ComplexityEntry `json:"complexity,omitempty"`
-	DeadFuncs  []DeadFunc        `json:"dead_funcs,omitempty"`
-	Duplicates []Duplicate       `json:"duplicates,omitempty"`
-	Vulns      []Vuln            `json:"vulns,omitempty"`
+	Coverage      []CoverageEntry   `json:"coverage,omitempty"`
+	CoverageFiles []CoverageFile    `json:"coverage_files,omitempty"` // statement coverage per file, from the cover profile
+	Complexity    []ComplexityEntry `json:"complexity,omitempty"`
+	DeadFuncs     []DeadFunc        `json:"dead_funcs,omitempty"`
+	Duplicates    []Duplicate       `json:"duplicates,omitempty"`
+	Vulns         []Vuln            `json:"vulns,omitempty"`
+}
+
+// Duration returns how long the run took, or zero if it has not finished.
+func (r *RunResult) Duration() time.Duration {
+	if r.Finished.IsZero() {
+		return 0
+	}
+	return r.Finished.Sub(r.Started)
+}
+
+// StepRecord holds the outcome and timing of a single step in a run.
+type StepRecord struct {
+	Name    string  `json:"name"`
+	Status  string  `json:"status"` // check: pass, fail, skipped, unavailable; audit: done, error, unavailable, skipped; both: timeout, cancelled
+	Detail  string  `json:"detail,omitempty"`
+	Elapsed float64 `json:"elapsed"`       // seconds
+	Log     string  `json:"log,omitempty"` // path of the complete output of the step's commands
+
+	Usage *Usage `json:"usage,omitempty"` // resources used by the step's commands
+}
+
+// Usage is the resources used by the commands of a step, or of a run.
+type Usage struct {
+	Commands int     `json:"commands"`
+	Wall     float64 `json:"wall"`    // seconds, summed over commands
+	User     float64 `json:"user"`    // seconds of user CPU time
+	System   float64 `json:"system"`  // seconds of system CPU time
+	MaxRSS   int64   `json:"max_rss"` // peak resident set size of any one process, in bytes
+}
+
+// Add accumulates o into u: times add up, peak memory is the larger.
+func (u *Usage) Add(o Usage) {
+	u.Commands += o.Commands
+	u.Wall += o.Wall
+	u.User += o.User
+	u.System += o.System
+	u.MaxRSS = max(u.MaxRSS, o.MaxRSS)
+}
+
+// Usage returns the resources used by all steps of r, or nil if none
+// were recorded.
+func (r *RunResult) Usage() *Usage {
+	var total *Usage
+	for _, s := range r.Steps {
+		if s.Usage == nil {
+			continue
+		}
+		if total == nil {
+			total = &Usage{}
+		}
+		total.Add(*s.Usage)
+	}
+	return total
+}
+
+// ToolRecord identifies an external tool binary used by a run.
+type ToolRecord struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`                 // binary path, or "go tool <name>"
+	Version    string `json:"version,omitempty"`    // module version, when known
+	Constraint string `json:"constraint,omitempty"` // version pinned in .governor
 }
 
 // Expect returns an error if the run's Kind does not match want.
@@ -79,6 +180,23 @@ type TestFailure struct {
 	Output  string `json:"output,omitempty"`
 }
 
+// TestPackage holds the outcome of one package in a go test run.
+type TestPackage struct {
+	Package string     `json:"package"`
+	Status  string     `json:"status"`           // pass, fail, skip, or error when the package failed to build
+	Elapsed float64    `json:"elapsed"`          // seconds
+	Output  string     `json:"output,omitempty"` // output not attributed to a test, including build output
+	Cases   []TestCase `json:"cases,omitempty"`
+}
+
+// TestCase holds the outcome of a single test, subtest, benchmark or example.
+type TestCase struct {
+	Name    string  `json:"name"`
+	Status  string  `json:"status"`  // pass, fail or skip
+	Elapsed float64 `json:"elapsed"` // seconds
+	Output  string  `json:"output,omitempty"`
+}
+
 // LintIssue represents a linter finding.
 type LintIssue struct {
 	Package string `json:"package"`
@@ -110,6 +228,20 @@ type CoverageEntry struct {
 	Coverage float64 `json:"coverage"` // 0.0–100.0
 }
 
+// CoverageFile holds the statement coverage of one source file.
+type CoverageFile struct {
+	File       string      `json:"file"` // module-qualified, as in the cover profile
+	Statements int         `json:"statements"`
+	Covered    int         `json:"covered"`
+	Uncovered  []LineRange `json:"uncovered,omitempty"` // lines of blocks that never ran
+}
+
+// LineRange is an inclusive range of source lines.
+type LineRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
 // ComplexityEntry holds per-function cognitive complexity data.
 type ComplexityEntry struct {
 	Package    string `json:"package"`
@@ -117,6 +249,19 @@ type ComplexityEntry struct {
 	Function   string `json:"function"`
 	Line       int    `json:"line"`
 	Complexity int    `json:"complexity"`
+
+	// Threshold is the score above which the function counts as complex,
+	// when an override sets one for its package.
+	Threshold int `json:"threshold,omitempty"`
+}
+
+// Over reports whether the function is more complex than its threshold,
+// or else than threshold.
+func (c ComplexityEntry) Over(threshold int) bool {
+	if c.Threshold > 0 {
+		threshold = c.Threshold
+	}
+	return c.Complexity > threshold
 }
 
 // DeadFunc represents an unreachable function found by deadcode.
@@ -145,25 +290,38 @@ type Vuln struct {
 	AffectedPackage string   `json:"affected_package"`
 	FixedVersion    string   `json:"fixed_version,omitempty"`
 	Symbols         []string `json:"symbols,omitempty"` // called vulnerable symbols
+	// Traces are the call stacks through which the code reaches a
+	// vulnerable symbol, each starting at the vulnerable symbol and ending
+	// in the main module.
+	Traces [][]TraceFrame `json:"traces,omitempty"`
+}
+
+// TraceFrame is a function in a vulnerability call trace.
+type TraceFrame struct {
+	Module   string `json:"module,omitempty"`
+	Package  string `json:"package,omitempty"`
+	Function string `json:"function,omitempty"` // with its receiver, e.g. "(*Reader).Read"
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
 }
 
 // Diagnostic is a uniform interface for all diagnostic types.
 type Diagnostic struct {
-	Source  string // "format", "build", "test", "lint", "staticcheck"
-	Package string
-	File    string
-	Line    int
-	Col     int
-	Symbol  string // e.g. "TestAdd" for test failures
-	Detail  string // linter name, staticcheck code, etc.
-	Message string
-	Output  string // full test output (test failures only)
+	Source  string `json:"source"` // "format", "build", "test", "lint", "staticcheck"
+	Package string `json:"package,omitempty"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Col     int    `json:"col,omitempty"`
+	Symbol  string `json:"symbol,omitempty"` // e.g. "TestAdd" for test failures
+	Detail  string `json:"detail,omitempty"` // linter name, staticcheck code, etc.
+	Message string `json:"message"`
+	Output  string `json:"output,omitempty"` // full test output (test failures only)
 }
 
 // ByPackage returns all diagnostics for a given package import path.
 func ByPackage(result *RunResult, pkg string) []Diagnostic {
 	var out []Diagnostic
-	for _, d := range toDiagnostics(result) {
+	for _, d := range Diagnostics(result) {
 		if d.Package == pkg {
 			out = append(out, d)
 		}
@@ -182,7 +340,7 @@ func BySymbol(result *RunResult, sym string) []Diagnostic {
 	}
 
 	var out []Diagnostic
-	for _, d := range toDiagnostics(result) {
+	for _, d := range Diagnostics(result) {
 		if d.Package == pkg && d.Symbol == name {
 			out = append(out, d)
 		}
@@ -205,7 +363,8 @@ func splitSymbol(sym string) (string, string) {
 	return pkg, name
 }
 
-func toDiagnostics(r *RunResult) []Diagnostic {
+// Diagnostics flattens every finding in r into the uniform Diagnostic shape.
+func Diagnostics(r *RunResult) []Diagnostic {
 	var out []Diagnostic
 
 	for _, f := range r.FormatIssues {
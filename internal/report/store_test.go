@@ -0,0 +1,20 @@
+package report
+
+import "testing"
+
+func TestRunResult_Usage(t *testing.T) {
+	rr := &RunResult{Steps: []StepRecord{
+		{Name: "format"},
+		{Name: "test", Usage: &Usage{Commands: 2, Wall: 3, User: 5, System: 1, MaxRSS: 300}},
+		{Name: "lint", Usage: &Usage{Commands: 1, Wall: 2, User: 4, System: 0.5, MaxRSS: 500}},
+	}}
+	got := rr.Usage()
+	want := Usage{Commands: 3, Wall: 5, User: 9, System: 1.5, MaxRSS: 500}
+	if got == nil || *got != want {
+		t.Errorf("Usage() = %+v, want %+v", got, want)
+	}
+
+	if u := (&RunResult{Steps: []StepRecord{{Name: "test"}}}).Usage(); u != nil {
+		t.Errorf("Usage() = %+v, want nil without recorded usage", u)
+	}
+}
@@ -0,0 +1,112 @@
+package report
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultTrendMetrics are the metrics tracked by trends when none are selected.
+var DefaultTrendMetrics = []string{
+	MetricCoverage,
+	MetricComplexFuncs,
+	MetricDuplicates,
+	MetricDeadFuncs,
+	MetricVulns,
+	MetricLintIssues,
+}
+
+// Point is a metric value recorded by a single run.
+type Point struct {
+	RunID string    `json:"run_id"`
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Series is the time series of one metric, oldest point first.
+type Series struct {
+	Metric string  `json:"metric"`
+	Points []Point `json:"points"`
+}
+
+// TrendOptions controls how a trend is computed.
+type TrendOptions struct {
+	Metrics             []string // default: DefaultTrendMetrics
+	Prefix              string   // only count findings under this package or directory prefix
+	ComplexityThreshold int
+}
+
+// Trend builds one series per metric from runs. A run contributes a point
+// to a metric only if it measured that metric (see Measured).
+func Trend(runs []*RunResult, opts TrendOptions) []Series {
+	metrics := opts.Metrics
+	if len(metrics) == 0 {
+		metrics = DefaultTrendMetrics
+	}
+
+	sorted := make([]*RunResult, len(runs))
+	copy(sorted, runs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Started.Before(sorted[j].Started)
+	})
+
+	series := make([]Series, len(metrics))
+	for i, name := range metrics {
+		series[i] = Series{Metric: name}
+	}
+
+	for _, r := range sorted {
+		measured := Measured(r)
+		values := ComputeMetrics(Scope(r, opts.Prefix), opts.ComplexityThreshold)
+		for i := range series {
+			if !measured[series[i].Metric] {
+				continue
+			}
+			series[i].Points = append(series[i].Points, Point{
+				RunID: r.ID,
+				Time:  r.Started,
+				Value: values[series[i].Metric],
+			})
+		}
+	}
+
+	return series
+}
+
+// Scope returns a copy of r restricted to findings whose package import
+// path or file path starts with prefix. A leading "./" in prefix is ignored
+// so that directory patterns can be used. An empty prefix returns r.
+func Scope(r *RunResult, prefix string) *RunResult {
+	prefix = strings.TrimPrefix(prefix, "./")
+	if prefix == "" {
+		return r
+	}
+	in := func(pkg, file string) bool {
+		return strings.HasPrefix(pkg, prefix) || strings.HasPrefix(strings.TrimPrefix(file, "./"), prefix)
+	}
+
+	scoped := *r
+	scoped.FormatIssues = filter(r.FormatIssues, func(f FormatIssue) bool { return in(f.Package, f.File) })
+	scoped.BuildErrors = filter(r.BuildErrors, func(b BuildError) bool { return in(b.Package, b.File) })
+	scoped.TestFailures = filter(r.TestFailures, func(t TestFailure) bool { return in(t.Package, t.File) })
+	scoped.LintIssues = filter(r.LintIssues, func(l LintIssue) bool { return in(l.Package, l.File) })
+	scoped.StaticIssues = filter(r.StaticIssues, func(s StaticIssue) bool { return in(s.Package, s.File) })
+	scoped.Coverage = filter(r.Coverage, func(c CoverageEntry) bool { return in(c.Package, c.File) })
+	scoped.CoverageFiles = filter(r.CoverageFiles, func(c CoverageFile) bool { return in(path.Dir(c.File), c.File) })
+	scoped.Complexity = filter(r.Complexity, func(c ComplexityEntry) bool { return in(c.Package, c.File) })
+	scoped.DeadFuncs = filter(r.DeadFuncs, func(d DeadFunc) bool { return in(d.Package, d.File) })
+	scoped.Duplicates = filter(r.Duplicates, func(d Duplicate) bool { return in("", d.File1) || in("", d.File2) })
+	scoped.Vulns = filter(r.Vulns, func(v Vuln) bool { return in(v.AffectedPackage, "") })
+	return &scoped
+}
+
+func filter[T any](items []T, keep func(T) bool) []T {
+	var out []T
+	for _, item := range items {
+		if keep(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
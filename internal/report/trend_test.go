@@ -0,0 +1,104 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrend(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	runs := []*RunResult{
+		{
+			ID: "a2", Kind: Audit, Started: t0.Add(48 * time.Hour),
+			Coverage: []CoverageEntry{
+				{Package: "example.com/m/core", Function: "A", Coverage: 80},
+				{Package: "example.com/m/legacy", Function: "B", Coverage: 0},
+			},
+			Vulns: []Vuln{{ID: "GO-1", AffectedPackage: "example.com/m/core"}},
+		},
+		{
+			ID: "c1", Kind: Check, Started: t0.Add(24 * time.Hour),
+			LintIssues: []LintIssue{{File: "core/a.go", Linter: "errcheck"}},
+		},
+		{
+			ID: "a1", Kind: Audit, Started: t0,
+			Coverage: []CoverageEntry{{Package: "example.com/m/core", Function: "A", Coverage: 60}},
+		},
+	}
+
+	series := Trend(runs, TrendOptions{
+		Metrics:             []string{MetricCoverage, MetricVulns, MetricLintIssues},
+		ComplexityThreshold: 15,
+	})
+	if len(series) != 3 {
+		t.Fatalf("len(series) = %d, want 3", len(series))
+	}
+
+	cov := series[0]
+	if len(cov.Points) != 2 || cov.Points[0].RunID != "a1" || cov.Points[1].RunID != "a2" {
+		t.Fatalf("coverage points = %+v, want a1 then a2", cov.Points)
+	}
+	if cov.Points[0].Value != 60 || cov.Points[1].Value != 40 {
+		t.Errorf("coverage values = %v, %v; want 60, 40", cov.Points[0].Value, cov.Points[1].Value)
+	}
+
+	// Lint issues are measured by check runs only.
+	lint := series[2]
+	if len(lint.Points) != 1 || lint.Points[0].RunID != "c1" || lint.Points[0].Value != 1 {
+		t.Errorf("lint points = %+v, want one point of 1 from c1", lint.Points)
+	}
+
+	scoped := Trend(runs, TrendOptions{
+		Metrics: []string{MetricCoverage},
+		Prefix:  "example.com/m/core",
+	})
+	if got := scoped[0].Points[1].Value; got != 80 {
+		t.Errorf("scoped coverage = %v, want 80", got)
+	}
+}
+
+func TestScope_DirectoryPrefix(t *testing.T) {
+	r := &RunResult{
+		Kind: Check,
+		LintIssues: []LintIssue{
+			{File: "internal/pb/gen.go"},
+			{File: "internal/core/core.go"},
+		},
+	}
+	got := Scope(r, "./internal/pb")
+	if len(got.LintIssues) != 1 || got.LintIssues[0].File != "internal/pb/gen.go" {
+		t.Errorf("Scope = %+v, want only internal/pb issue", got.LintIssues)
+	}
+	if len(r.LintIssues) != 2 {
+		t.Error("Scope must not modify the original run")
+	}
+}
+
+func TestTrend_SkipsUnmeasuredSteps(t *testing.T) {
+	runs := []*RunResult{
+		{
+			ID: "c1", Kind: Check, Started: time.Now(),
+			Steps: []StepRecord{
+				{Name: "test", Status: "fail"},
+				{Name: "lint", Status: "skipped"},
+			},
+		},
+		{
+			ID: "a1", Kind: Audit, Started: time.Now(),
+			Steps: []StepRecord{
+				{Name: "coverage", Status: "done"},
+				{Name: "vulncheck", Status: "unavailable"},
+			},
+		},
+	}
+	series := Trend(runs, TrendOptions{Metrics: []string{MetricLintIssues, MetricVulns, MetricCoverage}})
+	if n := len(series[0].Points); n != 0 {
+		t.Errorf("lint_issues has %d points, want 0 (lint was skipped)", n)
+	}
+	if n := len(series[1].Points); n != 0 {
+		t.Errorf("vulns has %d points, want 0 (vulncheck was unavailable)", n)
+	}
+	if n := len(series[2].Points); n != 1 {
+		t.Errorf("coverage has %d points, want 1", n)
+	}
+}
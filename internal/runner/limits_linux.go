@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// limitArgv returns argv wrapped in a shell that applies l to itself
+// before replacing itself with the command, so that the command and every
+// process it starts inherit the limits. The command is looked up in the
+// PATH of env, the command's environment, if it has one.
+func limitArgv(argv []string, l Limits, env []string) ([]string, error) {
+	// Resolve the binary up front, so that a missing tool is reported the
+	// same way with or without limits.
+	name := argv[0]
+	if !strings.Contains(name, "/") {
+		path, err := lookPath(name, env)
+		if err != nil {
+			return nil, fmt.Errorf("executing %s: %w", name, err)
+		}
+		name = path
+	}
+
+	script := []string{"set -e"}
+	if l.CPU > 0 {
+		// ulimit -t takes whole seconds.
+		script = append(script, fmt.Sprintf("ulimit -t %d", int64((l.CPU+time.Second-1)/time.Second)))
+	}
+	if l.Memory > 0 {
+		// ulimit -v takes KiB.
+		script = append(script, fmt.Sprintf("ulimit -v %d", max(l.Memory/1024, 1)))
+	}
+	if l.OpenFiles > 0 {
+		script = append(script, fmt.Sprintf("ulimit -n %d", l.OpenFiles))
+	}
+	script = append(script, `exec "$@"`)
+
+	wrapped := []string{"/bin/sh", "-c", strings.Join(script, "; "), argv[0], name}
+	return append(wrapped, argv[1:]...), nil
+}
+
+// lookPath searches for the executable name in the directories of the
+// PATH of env, as exec.LookPath does in those of the process's PATH, which
+// it falls back to if env is nil or has no PATH.
+func lookPath(name string, env []string) (string, error) {
+	path, ok := "", false
+	for _, kv := range env {
+		// Like exec.Cmd, the last value of a variable wins.
+		if v, found := strings.CutPrefix(kv, "PATH="); found {
+			path, ok = v, true
+		}
+	}
+	if !ok {
+		return exec.LookPath(name)
+	}
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			dir = "." // an empty entry is the current directory
+		}
+		// Not filepath.Join, which would drop the "./" that stops
+		// exec.LookPath from searching the process's PATH for it.
+		if p, err := exec.LookPath(dir + "/" + name); err == nil {
+			if !filepath.IsAbs(p) {
+				return p, &exec.Error{Name: name, Err: exec.ErrDot}
+			}
+			return p, nil
+		}
+	}
+	return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun_WithLimits(t *testing.T) {
+	r := newTestRunner(t)
+	limits := Limits{CPU: 1500 * time.Millisecond, Memory: 1 << 30, OpenFiles: 64}
+
+	// The limits are inherited by the processes the command starts.
+	res, err := r.Run(context.Background(), []string{"sh", "-c", "sh -c 'ulimit -t; ulimit -v; ulimit -n'"}, "", WithLimits(limits))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := strings.Fields(string(res.Stdout)), []string{"2", "1048576", "64"}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("limits = %v, want %v (stderr %q)", got, want, res.Stderr)
+	}
+}
+
+func TestRun_WithLimits_BinaryNotFound(t *testing.T) {
+	r := newTestRunner(t)
+	_, err := r.Run(context.Background(), []string{"nonexistent-binary-xyz"}, "", WithLimits(Limits{OpenFiles: 64}))
+	if err == nil {
+		t.Fatal("expected error for missing binary")
+	}
+}
+
+func TestRun_WithLimits_EnvPath(t *testing.T) {
+	// The tool is only on the PATH of the command's environment.
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "governor-env-tool"), []byte("#!/bin/sh\necho found\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	env := []string{"PATH=/nonexistent", "PATH=" + bin}
+
+	r := newTestRunner(t)
+	res, err := r.Run(context.Background(), []string{"governor-env-tool"}, "", WithEnv(env), WithLimits(Limits{OpenFiles: 64}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.TrimSpace(string(res.Stdout)); got != "found" {
+		t.Errorf("stdout = %q, want found (stderr %q)", got, res.Stderr)
+	}
+
+	_, err = r.Run(context.Background(), []string{"governor-env-tool"}, "", WithEnv([]string{"PATH=/nonexistent"}), WithLimits(Limits{OpenFiles: 64}))
+	if !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("error = %v, want not found outside the environment's PATH", err)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package runner
+
+import "fmt"
+
+// limitArgv reports that resource limits are not supported.
+func limitArgv(argv []string, _ Limits, _ []string) ([]string, error) {
+	return nil, fmt.Errorf("executing %s: resource limits are only supported on Linux", argv[0])
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// newOutput returns a buffer for an output stream of a command.
+func (r *Runner) newOutput() *headTail {
+	tail := r.TailOutput
+	if tail <= 0 || tail > r.MaxOutput {
+		tail = r.MaxOutput / 2
+	}
+	return &headTail{head: r.MaxOutput - tail, tail: tail}
+}
+
+// headTail keeps the first head and the last tail bytes written to it.
+type headTail struct {
+	head, tail int
+	first      []byte
+	last       []byte // holds up to twice tail bytes, to copy rarely
+	total      int64
+}
+
+func (w *headTail) Write(p []byte) (int, error) {
+	w.total += int64(len(p))
+	rest := p
+	if n := min(w.head-len(w.first), len(rest)); n > 0 {
+		w.first = append(w.first, rest[:n]...)
+		rest = rest[n:]
+	}
+	if w.tail > 0 && len(rest) > 0 {
+		w.last = append(w.last, rest...)
+		if len(w.last) > 2*w.tail {
+			w.last = append(w.last[:0], w.last[len(w.last)-w.tail:]...)
+		}
+	}
+	return len(p), nil
+}
+
+// Truncated reports whether some of the output was dropped.
+func (w *headTail) Truncated() bool {
+	return w.total > int64(w.head+w.tail)
+}
+
+// Bytes returns the kept output. When some was dropped, a line between
+// the beginning and the end says how much, and the result stays within
+// head+tail bytes.
+func (w *headTail) Bytes() []byte {
+	last := w.last[max(len(w.last)-w.tail, 0):]
+	if !w.Truncated() {
+		return append(w.first, last...)
+	}
+	omitted := w.total - int64(len(w.first)+len(last))
+	marker := fmt.Sprintf("\n[... %d bytes omitted ...]\n", omitted)
+	first := w.first
+	if over := len(first) + len(marker) + len(last) - (w.head + w.tail); over > 0 {
+		if over > len(first) {
+			// Too small for the marker: keep the output only.
+			return append(first, last...)
+		}
+		first = first[:len(first)-over]
+	}
+	out := make([]byte, 0, len(first)+len(marker)+len(last))
+	out = append(out, first...)
+	out = append(out, marker...)
+	return append(out, last...)
+}
+
+// lockedWriter serialises writes from the goroutines copying a command's
+// standard output and standard error.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
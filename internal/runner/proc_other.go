@@ -0,0 +1,31 @@
+//go:build !unix
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup does nothing: process groups are only used on Unix.
+func setProcessGroup(*exec.Cmd) {}
+
+// terminate stops cmd. Without process groups, processes it started are
+// not reached.
+func terminate(cmd *exec.Cmd) error {
+	return kill(cmd)
+}
+
+// maxRSS returns 0: peak memory is only known on Unix.
+func maxRSS(*os.ProcessState) int64 {
+	return 0
+}
+
+// kill stops cmd immediately.
+func kill(cmd *exec.Cmd) error {
+	err := cmd.Process.Kill()
+	if err == os.ErrProcessDone {
+		return nil
+	}
+	return err
+}
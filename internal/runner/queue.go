@@ -0,0 +1,215 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/deixis/governor/internal/flock"
+)
+
+// lockPollInterval is how often a command waiting for another process to
+// release the workspace lock tries again.
+const lockPollInterval = 100 * time.Millisecond
+
+// Queue orders the commands run in a workspace. At most limit commands run
+// at once, and a command run WithExclusive, such as one rewriting files,
+// runs alone. Commands start in the order they were queued, so that
+// exclusive commands are not starved.
+//
+// When the queue has a lock file, commands also hold a shared or exclusive
+// lock on it while they run, so that other processes using the workspace,
+// such as a CLI run next to an MCP server, are excluded the same way.
+type Queue struct {
+	limit    int
+	lockFile string
+
+	mu      sync.Mutex
+	running int
+	writing bool // the running command is exclusive
+	waiting []*queued
+}
+
+type queued struct {
+	exclusive bool
+	ready     chan struct{} // closed when the command may start
+}
+
+// Wait describes why a command has not started yet. The zero Wait means
+// it is no longer waiting.
+type Wait struct {
+	Ahead        int  // commands of this process running or queued before it
+	OtherProcess bool // another process holds the workspace lock
+}
+
+func (w Wait) String() string {
+	if w.OtherProcess {
+		return "another process is using the workspace"
+	}
+	if w.Ahead == 1 {
+		return "1 command ahead"
+	}
+	return fmt.Sprintf("%d commands ahead", w.Ahead)
+}
+
+// NewQueue returns a queue running at most limit commands at once, or one
+// if limit is not positive. If lockFile is not empty, it is created, along
+// with its directory, if needed and locked while commands run.
+func NewQueue(limit int, lockFile string) *Queue {
+	return &Queue{limit: max(limit, 1), lockFile: lockFile}
+}
+
+// acquire waits until a command may start, reporting to wait, if not nil,
+// each time it has to wait, and with the zero Wait once it may start after
+// waiting. It returns a function to call once the command has finished.
+// If ctx is done first, it returns ctx's error.
+func (q *Queue) acquire(ctx context.Context, exclusive bool, wait func(Wait)) (func(), error) {
+	waited := false
+	if wait != nil {
+		report := wait
+		wait = func(w Wait) {
+			waited = true
+			report(w)
+		}
+	}
+	if err := q.enter(ctx, exclusive, wait); err != nil {
+		return func() {}, err
+	}
+	var f *os.File
+	if q.lockFile != "" {
+		var err error
+		if f, err = q.lock(ctx, exclusive, wait); err != nil {
+			q.leave()
+			return func() {}, err
+		}
+	}
+	if waited {
+		wait(Wait{})
+	}
+	if f == nil {
+		return q.leave, nil
+	}
+	return func() {
+		_ = flock.Unlock(f)
+		_ = f.Close()
+		q.leave()
+	}, nil
+}
+
+// enter takes a place among the running commands of this process.
+func (q *Queue) enter(ctx context.Context, exclusive bool, wait func(Wait)) error {
+	q.mu.Lock()
+	if len(q.waiting) == 0 && q.canStart(exclusive) {
+		q.start(exclusive)
+		q.mu.Unlock()
+		return nil
+	}
+	w := &queued{exclusive: exclusive, ready: make(chan struct{})}
+	ahead := q.running + len(q.waiting)
+	q.waiting = append(q.waiting, w)
+	q.mu.Unlock()
+
+	if wait != nil {
+		wait(Wait{Ahead: ahead})
+	}
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	select {
+	case <-w.ready:
+		// Started just as ctx was done: give the place back.
+		q.running--
+		q.writing = false
+	default:
+		for i, o := range q.waiting {
+			if o == w {
+				q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+				break
+			}
+		}
+	}
+	q.dispatch()
+	return ctx.Err()
+}
+
+// leave gives back the place of a finished command.
+func (q *Queue) leave() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.running--
+	q.writing = false
+	q.dispatch()
+}
+
+// canStart reports whether a command may start now. q.mu must be held.
+func (q *Queue) canStart(exclusive bool) bool {
+	if q.writing {
+		return false
+	}
+	if exclusive {
+		return q.running == 0
+	}
+	return q.running < q.limit
+}
+
+func (q *Queue) start(exclusive bool) {
+	q.running++
+	q.writing = exclusive
+}
+
+// dispatch starts the waiting commands that may start, in order. q.mu must
+// be held.
+func (q *Queue) dispatch() {
+	for len(q.waiting) > 0 && q.canStart(q.waiting[0].exclusive) {
+		w := q.waiting[0]
+		q.waiting = q.waiting[1:]
+		q.start(w.exclusive)
+		close(w.ready)
+	}
+}
+
+// lock takes the workspace lock, polling while another process holds it.
+func (q *Queue) lock(ctx context.Context, exclusive bool, wait func(Wait)) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(q.lockFile), 0o755); err != nil {
+		return nil, fmt.Errorf("opening workspace lock: %w", err)
+	}
+	f, err := os.OpenFile(q.lockFile, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening workspace lock: %w", err)
+	}
+	var t *time.Ticker
+	for {
+		ok, err := flock.TryLock(f, exclusive)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("locking workspace: %w", err)
+		}
+		if ok {
+			if t != nil {
+				t.Stop()
+			}
+			return f, nil
+		}
+		if t == nil {
+			if wait != nil {
+				wait(Wait{OtherProcess: true})
+			}
+			t = time.NewTicker(lockPollInterval)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			_ = f.Close()
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// acquireAsync acquires a place in q in the background. The returned
+// channel yields the release function once the place is granted.
+func acquireAsync(t *testing.T, q *Queue, exclusive bool, wait func(Wait)) <-chan func() {
+	t.Helper()
+	ch := make(chan func(), 1)
+	go func() {
+		release, err := q.acquire(context.Background(), exclusive, wait)
+		if err != nil {
+			t.Errorf("acquire: %v", err)
+			return
+		}
+		ch <- release
+	}()
+	return ch
+}
+
+func mustAcquire(t *testing.T, ch <-chan func()) func() {
+	t.Helper()
+	select {
+	case release := <-ch:
+		return release
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not start")
+		return nil
+	}
+}
+
+func mustWait(t *testing.T, ch <-chan func()) {
+	t.Helper()
+	select {
+	case <-ch:
+		t.Fatal("command started, want it queued")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// waits records the wait states reported to a command.
+type waits struct {
+	mu  sync.Mutex
+	got []Wait
+}
+
+func (w *waits) record(s Wait) {
+	w.mu.Lock()
+	w.got = append(w.got, s)
+	w.mu.Unlock()
+}
+
+func (w *waits) states() []Wait {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]Wait(nil), w.got...)
+}
+
+func TestQueue_Limit(t *testing.T) {
+	q := NewQueue(2, "")
+	r1 := mustAcquire(t, acquireAsync(t, q, false, nil))
+	r2 := mustAcquire(t, acquireAsync(t, q, false, nil))
+
+	var w waits
+	third := acquireAsync(t, q, false, w.record)
+	mustWait(t, third)
+
+	r1()
+	mustAcquire(t, third)()
+	r2()
+
+	if want := []Wait{{Ahead: 2}, {}}; !reflect.DeepEqual(w.states(), want) {
+		t.Errorf("wait states = %+v, want %+v", w.states(), want)
+	}
+}
+
+func TestQueue_ExclusiveRunsAlone(t *testing.T) {
+	q := NewQueue(4, "")
+	reader := mustAcquire(t, acquireAsync(t, q, false, nil))
+
+	writer := acquireAsync(t, q, true, nil)
+	mustWait(t, writer)
+	// Readers queued after a writer do not overtake it.
+	later := acquireAsync(t, q, false, nil)
+	mustWait(t, later)
+
+	reader()
+	release := mustAcquire(t, writer)
+	mustWait(t, later)
+	release()
+	mustAcquire(t, later)()
+}
+
+func TestQueue_CancelWhileWaiting(t *testing.T) {
+	q := NewQueue(1, "")
+	release := mustAcquire(t, acquireAsync(t, q, true, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := q.acquire(ctx, false, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The cancelled command left no trace in the queue.
+	release()
+	mustAcquire(t, acquireAsync(t, q, true, nil))()
+}
+
+func TestRun_CancelledWhileQueued(t *testing.T) {
+	r := newTestRunner(t)
+	r.Queue = NewQueue(1, filepath.Join(r.Workspace, "workspace.lock"))
+	release := mustAcquire(t, acquireAsync(t, r.Queue, true, nil))
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var w waits
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	res, err := r.Run(ctx, []string{"echo", "hello"}, "", WithWait(w.record))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != Cancelled || res.ExitCode != -1 {
+		t.Errorf("Status = %s, ExitCode = %d, want cancelled before start", res.Status, res.ExitCode)
+	}
+	if want := []Wait{{Ahead: 1}}; !reflect.DeepEqual(w.states(), want) {
+		t.Errorf("wait states = %+v, want %+v", w.states(), want)
+	}
+}
@@ -1,10 +1,37 @@
 package runner
 
+import "time"
+
 // Result holds the output of a command execution.
 type Result struct {
 	RunID     string // unique identifier for this run
-	ExitCode  int    // process exit code
+	ExitCode  int    // process exit code; -1 if the command was killed
+	Status    Status // how the command ended
 	Stdout    []byte // captured stdout (may be truncated)
 	Stderr    []byte // captured stderr (may be truncated)
 	Truncated bool   // true if output exceeded the size cap
+	Usage     Usage  // resources the command used
+}
+
+// Usage is the resources used by a command. CPU times and peak memory
+// include the processes it started and waited for, such as the test
+// binaries run by go test.
+type Usage struct {
+	Wall   time.Duration // from start to exit
+	User   time.Duration // user CPU time
+	System time.Duration // system CPU time
+	MaxRSS int64         // peak resident set size of any one process, in bytes; 0 if unknown
 }
+
+// Status describes how a command ended.
+type Status string
+
+const (
+	// Exited means the command ran to completion, successfully or not.
+	Exited Status = "exited"
+	// TimedOut means the command was stopped for exceeding Runner.Timeout.
+	TimedOut Status = "timed_out"
+	// Cancelled means the command was stopped because the caller's
+	// context was cancelled.
+	Cancelled Status = "cancelled"
+)
@@ -3,10 +3,10 @@
 package runner
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -15,20 +15,139 @@ import (
 	"github.com/google/uuid"
 )
 
+// DefaultGracePeriod is how long a cancelled command has to exit after
+// SIGTERM when Runner.GracePeriod is unset.
+const DefaultGracePeriod = 5 * time.Second
+
 // Runner executes commands safely within a workspace boundary.
+//
+// Each command runs in its own process group. When it times out or its
+// context is cancelled, the whole group is sent SIGTERM, so that processes
+// it started, such as the test binaries run by go test, stop with it. Any
+// still running after the grace period are killed.
+//
+// At most MaxOutput bytes of each output stream are kept in the Result:
+// the beginning and the last TailOutput bytes, as the end of a failing
+// command's output is often what explains it. Use WithLog to keep all of
+// it.
 type Runner struct {
-	Workspace string
-	Timeout   time.Duration
-	MaxOutput int // bytes
+	Workspace   string
+	Timeout     time.Duration
+	MaxOutput   int           // bytes
+	TailOutput  int           // bytes of MaxOutput kept from the end; half if zero
+	GracePeriod time.Duration // between SIGTERM and SIGKILL; DefaultGracePeriod if zero
+	Queue       *Queue        // orders commands sharing the workspace; nil runs them at once
+}
+
+// Dir returns the directory commands run in when Run is given no cwd.
+func (r *Runner) Dir() string {
+	return r.Workspace
+}
+
+// Option configures a single call to Run.
+type Option func(*Options)
+
+// Options holds the settings of a single call to Run.
+type Options struct {
+	// Stdout, if set, receives the command's standard output as it is
+	// produced, in addition to it being captured in the Result. It sees
+	// the full output, regardless of MaxOutput.
+	Stdout io.Writer
+	// Env, if set, is the environment of the command. Otherwise it
+	// inherits the environment of the current process.
+	Env []string
+	// Limits are applied to every process the command starts.
+	Limits Limits
+	// Log, if set, receives the command's complete standard output and
+	// standard error, interleaved as they are produced.
+	Log io.Writer
+	// Exclusive commands run alone in the Runner's Queue.
+	Exclusive bool
+	// Wait, if set, is called each time the command has to wait in the
+	// Runner's Queue, and with the zero Wait when it starts after waiting.
+	Wait func(Wait)
+}
+
+// Limits are resource limits on a process. Zero values are unlimited.
+// They are only supported on Linux.
+type Limits struct {
+	CPU       time.Duration // CPU time
+	Memory    int64         // address space, in bytes
+	OpenFiles int           // open file descriptors
+}
+
+// IsZero reports whether l sets no limits.
+func (l Limits) IsZero() bool {
+	return l.CPU <= 0 && l.Memory <= 0 && l.OpenFiles <= 0
+}
+
+// Apply returns the settings described by opts. It lets other
+// implementations of a command runner honour the same options.
+func Apply(opts ...Option) Options {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithStdout streams the command's standard output to w while it runs.
+// Errors from w are ignored and do not affect the command.
+func WithStdout(w io.Writer) Option {
+	return func(o *Options) {
+		o.Stdout = w
+	}
+}
+
+// WithEnv runs the command with the environment env, in the form
+// "key=value", instead of the environment of the current process.
+func WithEnv(env []string) Option {
+	return func(o *Options) {
+		o.Env = env
+	}
+}
+
+// WithLimits applies resource limits to the command and the processes it
+// starts. They are inherited, so a runaway test binary started by go test
+// is bounded as well.
+func WithLimits(l Limits) Option {
+	return func(o *Options) {
+		o.Limits = l
+	}
+}
+
+// WithLog copies the complete output of the command to w, regardless of
+// MaxOutput. Errors from w are ignored and do not affect the command.
+func WithLog(w io.Writer) Option {
+	return func(o *Options) {
+		o.Log = w
+	}
+}
+
+// WithExclusive runs the command alone in the Runner's Queue, as needed by
+// commands that rewrite files others read.
+func WithExclusive() Option {
+	return func(o *Options) {
+		o.Exclusive = true
+	}
+}
+
+// WithWait calls fn each time the command has to wait in the Runner's
+// Queue, and with the zero Wait when it starts after waiting.
+func WithWait(fn func(Wait)) Option {
+	return func(o *Options) {
+		o.Wait = fn
+	}
 }
 
 // Run executes a command with the given argv. The first element is the
 // binary name (resolved via PATH), and the rest are arguments.
 // cwd is resolved relative to the workspace root and must remain within it.
-func (r *Runner) Run(ctx context.Context, argv []string, cwd string) (*Result, error) {
+func (r *Runner) Run(ctx context.Context, argv []string, cwd string, opts ...Option) (*Result, error) {
 	if len(argv) == 0 {
 		return nil, fmt.Errorf("empty argv")
 	}
+	o := Apply(opts...)
 
 	// Resolve and validate cwd.
 	dir, err := r.resolveDir(cwd)
@@ -37,8 +156,28 @@ func (r *Runner) Run(ctx context.Context, argv []string, cwd string) (*Result, e
 	}
 
 	timeout := r.Timeout
-	maxOutput := r.MaxOutput
+	grace := r.GracePeriod
+	if grace <= 0 {
+		grace = DefaultGracePeriod
+	}
+
+	if !o.Limits.IsZero() {
+		if argv, err = limitArgv(argv, o.Limits, o.Env); err != nil {
+			return nil, err
+		}
+	}
 
+	// Time spent queued does not count towards the timeout. If ctx is done
+	// while queued, the command is reported as stopped before it started.
+	if r.Queue != nil {
+		release, err := r.Queue.acquire(ctx, o.Exclusive, o.Wait)
+		if err != nil && ctx.Err() == nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	parent := ctx
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
@@ -46,21 +185,61 @@ func (r *Runner) Run(ctx context.Context, argv []string, cwd string) (*Result, e
 
 	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
 	cmd.Dir = dir
+	cmd.Env = o.Env
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error { return terminate(cmd) }
+	// Bounds the wait for the command to exit after SIGTERM, and for its
+	// output to be closed after it exits, in case processes that outlive
+	// it hold on to it.
+	cmd.WaitDelay = grace
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &limitWriter{buf: &stdout, limit: maxOutput}
-	cmd.Stderr = &limitWriter{buf: &stderr, limit: maxOutput}
+	stdout, stderr := r.newOutput(), r.newOutput()
+	outs, errs := []io.Writer{stdout}, []io.Writer{stderr}
+	if o.Stdout != nil {
+		outs = append(outs, ignoreErrors{o.Stdout})
+	}
+	if o.Log != nil {
+		log := &lockedWriter{w: ignoreErrors{o.Log}}
+		outs = append(outs, log)
+		errs = append(errs, log)
+	}
+	cmd.Stdout = io.MultiWriter(outs...)
+	cmd.Stderr = io.MultiWriter(errs...)
 
+	start := time.Now()
 	runErr := cmd.Run()
+	usage := Usage{Wall: time.Since(start)}
+	if ps := cmd.ProcessState; ps != nil {
+		usage.User = ps.UserTime()
+		usage.System = ps.SystemTime()
+		usage.MaxRSS = maxRSS(ps)
+	}
 
-	truncated := stdout.Len() >= maxOutput || stderr.Len() >= maxOutput
+	status := Exited
+	if ctx.Err() != nil {
+		// Whatever the leader did, make sure nothing it started is left.
+		if cmd.Process != nil {
+			_ = kill(cmd)
+		}
+		status = TimedOut
+		if errors.Is(parent.Err(), context.Canceled) {
+			status = Cancelled
+		}
+	}
 
 	exitCode := 0
 	if runErr != nil {
 		var exitErr *exec.ExitError
-		if errors.As(runErr, &exitErr) {
+		switch {
+		case errors.As(runErr, &exitErr):
 			exitCode = exitErr.ExitCode()
-		} else {
+		case errors.Is(runErr, exec.ErrWaitDelay) && cmd.ProcessState != nil:
+			// The command exited, but left processes holding its output.
+			exitCode = cmd.ProcessState.ExitCode()
+		case status != Exited:
+			// Stopped before it could start.
+			exitCode = -1
+		default:
 			// Binary not found or other exec error.
 			return nil, fmt.Errorf("executing %s: %w", argv[0], runErr)
 		}
@@ -69,9 +248,11 @@ func (r *Runner) Run(ctx context.Context, argv []string, cwd string) (*Result, e
 	return &Result{
 		RunID:     runID,
 		ExitCode:  exitCode,
+		Status:    status,
 		Stdout:    stdout.Bytes(),
 		Stderr:    stderr.Bytes(),
-		Truncated: truncated,
+		Truncated: stdout.Truncated() || stderr.Truncated(),
+		Usage:     usage,
 	}, nil
 }
 
@@ -100,22 +281,13 @@ func (r *Runner) resolveDir(cwd string) (string, error) {
 	return dir, nil
 }
 
-// limitWriter writes up to limit bytes to buf, then silently discards the rest.
-type limitWriter struct {
-	buf   *bytes.Buffer
-	limit int
+// ignoreErrors wraps a writer so that its failures do not interrupt the
+// command writing to it.
+type ignoreErrors struct {
+	w io.Writer
 }
 
-func (w *limitWriter) Write(p []byte) (int, error) {
-	remaining := w.limit - w.buf.Len()
-	if remaining <= 0 {
-		return len(p), nil // discard
-	}
-	if len(p) > remaining {
-		// Write only what fits, but report all bytes as consumed
-		// to avoid short write errors from io.Copy.
-		w.buf.Write(p[:remaining])
-		return len(p), nil
-	}
-	return w.buf.Write(p)
+func (w ignoreErrors) Write(p []byte) (int, error) {
+	_, _ = w.w.Write(p)
+	return len(p), nil
 }
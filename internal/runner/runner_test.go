@@ -1,9 +1,11 @@
 package runner
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -106,14 +108,80 @@ func TestRun_Timeout(t *testing.T) {
 	r := newTestRunner(t)
 	r.Timeout = 100 * time.Millisecond
 
-	_, err := r.Run(context.Background(), []string{"sleep", "10"}, "")
-	// On timeout, exec.CommandContext sends SIGKILL which produces an ExitError
-	// (not a context error). Either way, we should get a result or an error.
+	start := time.Now()
+	res, err := r.Run(context.Background(), []string{"sleep", "10"}, "")
 	if err != nil {
-		// Some systems may return an error rather than a result.
-		return
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != TimedOut {
+		t.Errorf("Status = %q, want %q", res.Status, TimedOut)
+	}
+	if res.ExitCode == 0 {
+		t.Error("ExitCode = 0, want non-zero")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("Run took %v after a 100ms timeout", d)
+	}
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	r := newTestRunner(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	res, err := r.Run(ctx, []string{"sleep", "10"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != Cancelled {
+		t.Errorf("Status = %q, want %q", res.Status, Cancelled)
+	}
+}
+
+func TestRun_CancelledBeforeStart(t *testing.T) {
+	r := newTestRunner(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := r.Run(ctx, []string{"echo", "hello"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != Cancelled || res.ExitCode != -1 {
+		t.Errorf("Status, ExitCode = %q, %d, want %q, -1", res.Status, res.ExitCode, Cancelled)
+	}
+}
+
+func TestRun_ExitedStatus(t *testing.T) {
+	r := newTestRunner(t)
+	res, err := r.Run(context.Background(), []string{"/usr/bin/false"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != Exited {
+		t.Errorf("Status = %q, want %q", res.Status, Exited)
+	}
+}
+
+func TestRun_GracePeriod(t *testing.T) {
+	r := newTestRunner(t)
+	r.Timeout = 100 * time.Millisecond
+	r.GracePeriod = 300 * time.Millisecond
+
+	// The command ignores SIGTERM, so it is only stopped by SIGKILL once
+	// the grace period has passed.
+	start := time.Now()
+	res, err := r.Run(context.Background(), []string{"sh", "-c", "trap '' TERM; sleep 30"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != TimedOut {
+		t.Errorf("Status = %q, want %q", res.Status, TimedOut)
+	}
+	d := time.Since(start)
+	if d < 400*time.Millisecond || d > 5*time.Second {
+		t.Errorf("Run took %v, want about 400ms", d)
 	}
-	// If we got a result, the exit code should be non-zero.
 }
 
 func TestRun_OutputTruncation(t *testing.T) {
@@ -132,3 +200,103 @@ func TestRun_OutputTruncation(t *testing.T) {
 		t.Errorf("len(Stdout) = %d, want <= %d", len(res.Stdout), r.MaxOutput)
 	}
 }
+
+func TestRun_WithStdout(t *testing.T) {
+	r := newTestRunner(t)
+	r.MaxOutput = 100
+
+	// The stream sees the full output even when the result is truncated.
+	var stream bytes.Buffer
+	res, err := r.Run(context.Background(), []string{"sh", "-c", "dd if=/dev/zero bs=200 count=1 2>/dev/null"}, "", WithStdout(&stream))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stream.Len() != 200 {
+		t.Errorf("streamed %d bytes, want 200", stream.Len())
+	}
+	if !res.Truncated {
+		t.Error("Truncated = false, want true")
+	}
+}
+
+func TestRun_WithEnv(t *testing.T) {
+	r := newTestRunner(t)
+	res, err := r.Run(context.Background(), []string{"sh", "-c", `echo "$FOO|$HOME"`}, "", WithEnv([]string{"FOO=bar"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.TrimSpace(string(res.Stdout)); got != "bar|" {
+		t.Errorf("Stdout = %q, want only the given environment", got)
+	}
+}
+
+func TestRun_OutputKeepsHeadAndTail(t *testing.T) {
+	r := newTestRunner(t)
+	r.MaxOutput = 100
+	r.TailOutput = 40
+
+	res, err := r.Run(context.Background(), []string{"sh", "-c", "printf 'head%0300dtail'"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := string(res.Stdout)
+	if !res.Truncated {
+		t.Error("Truncated = false, want true")
+	}
+	if len(out) > r.MaxOutput {
+		t.Errorf("len(Stdout) = %d, want <= %d", len(out), r.MaxOutput)
+	}
+	if !strings.HasPrefix(out, "head") || !strings.HasSuffix(out, "tail") || !strings.Contains(out, "bytes omitted") {
+		t.Errorf("Stdout = %q, want the head, an omission marker and the tail", out)
+	}
+}
+
+func TestRun_OutputNotTruncated(t *testing.T) {
+	r := newTestRunner(t)
+	r.MaxOutput = 10
+
+	res, err := r.Run(context.Background(), []string{"printf", "0123456789"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Truncated || string(res.Stdout) != "0123456789" {
+		t.Errorf("Stdout = %q, Truncated = %v, want the whole output", res.Stdout, res.Truncated)
+	}
+}
+
+func TestRun_WithLog(t *testing.T) {
+	r := newTestRunner(t)
+	r.MaxOutput = 10
+
+	var log bytes.Buffer
+	res, err := r.Run(context.Background(), []string{"sh", "-c", "echo out-0123456789; echo err >&2"}, "", WithLog(&log))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Truncated {
+		t.Error("Truncated = false, want true")
+	}
+	// The streams are copied concurrently, so their order is not fixed.
+	got := log.String()
+	if len(got) != len("out-0123456789\nerr\n") || !strings.Contains(got, "out-0123456789\n") || !strings.Contains(got, "err\n") {
+		t.Errorf("log = %q, want both streams in full", got)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	r := newTestRunner(t)
+	res, err := r.Run(context.Background(), []string{"sh", "-c", "i=0; while [ $i -lt 20000 ]; do i=$((i+1)); done; sleep 0.05"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	u := res.Usage
+	if u.Wall < 50*time.Millisecond {
+		t.Errorf("Wall = %v, want at least the sleep", u.Wall)
+	}
+	if u.User+u.System <= 0 {
+		t.Errorf("CPU time = %v user, %v system, want > 0", u.User, u.System)
+	}
+	if runtime.GOOS != "windows" && u.MaxRSS <= 0 {
+		t.Errorf("MaxRSS = %d, want > 0", u.MaxRSS)
+	}
+}
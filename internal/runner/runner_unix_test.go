@@ -0,0 +1,62 @@
+//go:build unix
+
+package runner
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRun_TimeoutKillsProcessGroup(t *testing.T) {
+	r := newTestRunner(t)
+	r.Timeout = 200 * time.Millisecond
+
+	// The shell starts a child that would outlive it if only the shell
+	// were killed, and reports its PID.
+	res, err := r.Run(context.Background(), []string{"sh", "-c", "sleep 30 & echo $!; wait"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != TimedOut {
+		t.Fatalf("Status = %q, want %q", res.Status, TimedOut)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(res.Stdout)))
+	if err != nil {
+		t.Fatalf("reading child PID from %q: %v", res.Stdout, err)
+	}
+	// Until it is reaped, a killed child still answers signal 0.
+	deadline := time.Now().Add(5 * time.Second)
+	for syscall.Kill(pid, 0) == nil {
+		if time.Now().After(deadline) {
+			_ = syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("child %d still running after timeout", pid)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestQueue_LockFileExcludesOtherProcesses(t *testing.T) {
+	// Two queues on the same lock file stand for two processes.
+	lock := filepath.Join(t.TempDir(), "workspace.lock")
+	q1, q2 := NewQueue(4, lock), NewQueue(4, lock)
+
+	r1 := mustAcquire(t, acquireAsync(t, q1, false, nil))
+	// Readers share the workspace across processes.
+	mustAcquire(t, acquireAsync(t, q2, false, nil))()
+
+	var w waits
+	writer := acquireAsync(t, q2, true, w.record)
+	mustWait(t, writer)
+	r1()
+	mustAcquire(t, writer)()
+
+	if want := []Wait{{OtherProcess: true}, {}}; !reflect.DeepEqual(w.states(), want) {
+		t.Errorf("wait states = %+v, want %+v", w.states(), want)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/deixis/governor/internal/config"
 	"github.com/deixis/governor/internal/report"
-	"github.com/google/uuid"
 )
 
 // AuditResult holds the full outcome of an audit run.
@@ -18,94 +20,112 @@ type AuditResult struct {
 // AuditStepResult holds the outcome of a single audit step.
 type AuditStepResult struct {
 	Name   string
-	Status string // done, error, unavailable, skipped
+	Status string // done, error, unavailable, skipped, timeout, cancelled
 	Detail string // error or unavailability message
 	Output string // formatted summary (only when done)
+
+	Elapsed time.Duration
 }
 
 // Audit runs all configured audit steps (coverage, complexity, deadcode,
-// dupl, vulncheck) without stopping on failure.
+// dupl, vulncheck) without stopping on failure. Packages with overrides
+// run each step with their own settings; complexity, deadcode and dupl
+// analyse every package and keep the findings in the group's packages.
 func (e *Engine) Audit(ctx context.Context, packages []string) (*AuditResult, error) {
-	runID := uuid.New().String()
 	pkgs := e.ResolvePackages(packages)
+	rr, ctx := e.newRun(ctx, report.Audit, pkgs)
 
-	rr := &report.RunResult{ID: runID, Kind: report.Audit}
-
-	steps := e.Config.AuditSteps()
+	groups := e.packageGroups(rr, pkgs)
+	steps := groupSteps(groups, (*config.Config).AuditSteps)
 	results := make([]AuditStepResult, len(steps))
 	for i, step := range steps {
 		results[i] = AuditStepResult{Name: step, Status: "skipped"}
 	}
 
+	prog := progressFrom(ctx)
+	prog.plan(len(steps))
+
 	// Run all steps — no fail-fast.
 	for i, step := range steps {
+		prog.stepStarted(i, step)
+		ctx := withStep(ctx, step)
+		stepStart := time.Now()
 		switch step {
 		case "coverage":
-			entries, err := e.runCoverage(ctx, pkgs)
+			type coverage struct {
+				entries []report.CoverageEntry
+				files   []report.CoverageFile
+			}
+			covs, err := forGroups(ctx, e, groups, step, func(e *Engine, ctx context.Context, g packageGroup) (coverage, error) {
+				entries, files, err := e.runCoverage(ctx, g.packages)
+				return coverage{entries, files}, err
+			})
 			if err != nil {
-				var unavail ErrToolUnavailable
-				if errors.As(err, &unavail) {
-					results[i] = AuditStepResult{Name: step, Status: "unavailable", Detail: err.Error()}
-				} else {
-					results[i] = AuditStepResult{Name: step, Status: "error", Detail: err.Error()}
-				}
+				results[i] = auditStepError(step, err)
 			} else {
+				var entries []report.CoverageEntry
+				for _, c := range covs {
+					entries = append(entries, c.entries...)
+					rr.CoverageFiles = append(rr.CoverageFiles, c.files...)
+				}
 				rr.Coverage = entries
 				results[i] = AuditStepResult{Name: step, Status: "done", Output: FormatCoverageSummary(entries)}
 			}
 
 		case "complexity":
-			entries, err := e.runComplexity(ctx, pkgs)
-			if err != nil {
-				var unavail ErrToolUnavailable
-				if errors.As(err, &unavail) {
-					results[i] = AuditStepResult{Name: step, Status: "unavailable", Detail: err.Error()}
-				} else {
-					results[i] = AuditStepResult{Name: step, Status: "error", Detail: err.Error()}
+			lists, err := forGroups(ctx, e, groups, step, func(ge *Engine, ctx context.Context, g packageGroup) ([]report.ComplexityEntry, error) {
+				entries, err := ge.runComplexity(ctx, pkgs)
+				threshold := ge.Config.ComplexityThreshold()
+				entries = slices.DeleteFunc(entries, func(c report.ComplexityEntry) bool { return !g.hasFile(e.repoRoot(), c.File) })
+				for j := range entries {
+					if threshold != e.Config.ComplexityThreshold() {
+						entries[j].Threshold = threshold
+					}
 				}
+				return entries, err
+			})
+			if err != nil {
+				results[i] = auditStepError(step, err)
 			} else {
+				entries := concatGroups(lists)
 				rr.Complexity = entries
 				results[i] = AuditStepResult{Name: step, Status: "done", Output: FormatComplexitySummary(entries)}
 			}
 
 		case "deadcode":
-			funcs, err := e.runDeadcode(ctx, pkgs)
+			lists, err := forGroups(ctx, e, groups, step, func(e *Engine, ctx context.Context, g packageGroup) ([]report.DeadFunc, error) {
+				funcs, err := e.runDeadcode(ctx, pkgs)
+				return slices.DeleteFunc(funcs, func(f report.DeadFunc) bool { return !g.hasPackage(f.Package) }), err
+			})
 			if err != nil {
-				var unavail ErrToolUnavailable
-				if errors.As(err, &unavail) {
-					results[i] = AuditStepResult{Name: step, Status: "unavailable", Detail: err.Error()}
-				} else {
-					results[i] = AuditStepResult{Name: step, Status: "error", Detail: err.Error()}
-				}
+				results[i] = auditStepError(step, err)
 			} else {
+				funcs := concatGroups(lists)
 				rr.DeadFuncs = funcs
 				results[i] = AuditStepResult{Name: step, Status: "done", Output: FormatDeadcodeSummary(funcs)}
 			}
 
 		case "dupl":
-			duplicates, err := e.runDupl(ctx, pkgs)
+			lists, err := forGroups(ctx, e, groups, step, func(ge *Engine, ctx context.Context, g packageGroup) ([]report.Duplicate, error) {
+				duplicates, err := ge.runDupl(ctx, pkgs)
+				return slices.DeleteFunc(duplicates, func(d report.Duplicate) bool { return !g.hasFile(e.repoRoot(), d.File1) }), err
+			})
 			if err != nil {
-				var unavail ErrToolUnavailable
-				if errors.As(err, &unavail) {
-					results[i] = AuditStepResult{Name: step, Status: "unavailable", Detail: err.Error()}
-				} else {
-					results[i] = AuditStepResult{Name: step, Status: "error", Detail: err.Error()}
-				}
+				results[i] = auditStepError(step, err)
 			} else {
+				duplicates := mergeUnique(lists, func(d report.Duplicate) string { return fmt.Sprint(d) })
 				rr.Duplicates = duplicates
 				results[i] = AuditStepResult{Name: step, Status: "done", Output: FormatDuplSummary(duplicates)}
 			}
 
 		case "vulncheck":
-			vulns, err := e.runVulncheck(ctx, pkgs)
+			lists, err := forGroups(ctx, e, groups, step, func(e *Engine, ctx context.Context, g packageGroup) ([]report.Vuln, error) {
+				return e.runVulncheck(ctx, g.packages)
+			})
 			if err != nil {
-				var unavail ErrToolUnavailable
-				if errors.As(err, &unavail) {
-					results[i] = AuditStepResult{Name: step, Status: "unavailable", Detail: err.Error()}
-				} else {
-					results[i] = AuditStepResult{Name: step, Status: "error", Detail: err.Error()}
-				}
+				results[i] = auditStepError(step, err)
 			} else {
+				vulns := mergeUnique(lists, func(v report.Vuln) string { return v.ID })
 				rr.Vulns = vulns
 				results[i] = AuditStepResult{Name: step, Status: "done", Output: FormatVulncheckSummary(vulns)}
 			}
@@ -113,10 +133,46 @@ func (e *Engine) Audit(ctx context.Context, packages []string) (*AuditResult, er
 		default:
 			results[i] = AuditStepResult{Name: step, Status: "error", Detail: fmt.Sprintf("unknown step: %s", step)}
 		}
+		results[i].Elapsed = time.Since(stepStart)
+		prog.stepFinished(results[i].Status)
+
+		// Leave the remaining steps skipped once the run is cancelled.
+		if results[i].Status == "cancelled" {
+			break
+		}
 	}
 
+	rr.Status = "done"
+	for _, r := range results {
+		rr.Steps = append(rr.Steps, report.StepRecord{
+			Name:    r.Name,
+			Status:  r.Status,
+			Detail:  r.Detail,
+			Elapsed: r.Elapsed.Seconds(),
+			Log:     stepLog(ctx, r.Name),
+			Usage:   stepUsage(ctx, r.Name),
+		})
+		if r.Status != "done" {
+			rr.Status = "error"
+		}
+	}
+	e.finishRun(ctx, rr)
+
 	return &AuditResult{
 		RunResult: rr,
 		Steps:     results,
 	}, nil
 }
+
+// auditStepError returns the result of an audit step that failed with err.
+func auditStepError(step string, err error) AuditStepResult {
+	var unavail ErrToolUnavailable
+	var interrupted ErrInterrupted
+	switch {
+	case errors.As(err, &unavail):
+		return AuditStepResult{Name: step, Status: "unavailable", Detail: err.Error()}
+	case errors.As(err, &interrupted):
+		return AuditStepResult{Name: step, Status: interrupted.StepStatus(), Detail: interrupted.Error()}
+	}
+	return AuditStepResult{Name: step, Status: "error", Detail: err.Error()}
+}
@@ -123,3 +123,31 @@ func TestAudit_UnknownStep(t *testing.T) {
 		t.Errorf("Steps[0].Status = %q, want error", result.Steps[0].Status)
 	}
 }
+
+func TestAudit_CancelledSkipsRemainingSteps(t *testing.T) {
+	fr := &fakeRunner{
+		Results: map[string]*runner.Result{
+			"go test": {ExitCode: -1, Status: runner.Cancelled},
+		},
+	}
+	e := &Engine{
+		Config:    &config.Config{Audit: config.AuditConfig{Steps: []string{"coverage", "complexity"}}},
+		Runner:    fr,
+		Workspace: "/project",
+		RepoRoot:  "/project",
+	}
+
+	result, err := e.Audit(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+	if got := result.Steps[0]; got.Status != "cancelled" || got.Detail != "go test was cancelled" {
+		t.Errorf("Steps[0] = %s (%s), want cancelled (go test was cancelled)", got.Status, got.Detail)
+	}
+	if result.Steps[1].Status != "skipped" {
+		t.Errorf("Steps[1].Status = %q, want skipped", result.Steps[1].Status)
+	}
+	if result.RunResult.Status != "error" {
+		t.Errorf("RunResult.Status = %q, want error", result.RunResult.Status)
+	}
+}
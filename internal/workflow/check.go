@@ -4,10 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/deixis/governor/internal/config"
 	"github.com/deixis/governor/internal/report"
-	"github.com/google/uuid"
 )
 
 // CheckResult holds the full outcome of a check run.
@@ -20,37 +25,58 @@ type CheckResult struct {
 // StepResult holds the outcome of a single check step.
 type StepResult struct {
 	Name   string
-	Status string // pass, fail, skipped, unavailable
+	Status string // pass, fail, skipped, unavailable, timeout, cancelled
 	Detail string // extra info (e.g. "golangci-lint not found")
 	Output string // summary from the underlying tool (only on failure)
+
+	Elapsed time.Duration
 }
 
 // Check runs the full check pipeline: optional fix phase, then
 // configured check steps (test, lint, staticcheck) in sequence,
-// stopping on first failure.
+// stopping on first failure. Packages with overrides run each step with
+// their own settings.
 func (e *Engine) Check(ctx context.Context, packages []string, fix bool) (*CheckResult, error) {
-	runID := uuid.New().String()
 	pkgs := e.ResolvePackages(packages)
+	rr, ctx := e.newRun(ctx, report.Check, pkgs)
 
-	rr := &report.RunResult{ID: runID, Kind: report.Check}
+	groups := e.packageGroups(rr, pkgs)
+	steps := groupSteps(groups, (*config.Config).CheckSteps)
+	prog := progressFrom(ctx)
+	prog.plan(len(steps) + 1)
 
 	// --- Fix phase ---
-	fixRes, _ := e.RunFixPhase(ctx, fix)
+	fixStep := report.StepRecord{Name: "fix", Status: "pass"}
+	if !fix {
+		fixStep.Name = "format"
+	}
+	prog.stepStarted(0, fixStep.Name)
+	fixStart := time.Now()
+	fixRes, _ := e.RunFixPhase(withStep(ctx, fixStep.Name), fix)
 	if fixRes != nil {
 		rr.AutoFixes = fixRes.AutoFixes
 		rr.FormatIssues = fixRes.FormatIssues
 	}
+	fixStep.Elapsed = time.Since(fixStart).Seconds()
+	fixStep.Log = stepLog(ctx, fixStep.Name)
+	fixStep.Usage = stepUsage(ctx, fixStep.Name)
 
 	// If fix=false and there are format issues, treat as failure.
 	if !fix && len(rr.FormatIssues) > 0 {
+		fixStep.Status = "fail"
+		prog.stepFinished(fixStep.Status)
+		rr.Steps = []report.StepRecord{fixStep}
+		rr.Status = "fail"
+		e.finishRun(ctx, rr)
 		return &CheckResult{
 			RunResult: rr,
 			FailedIdx: -2, // sentinel: format failure before steps ran
 		}, nil
 	}
+	prog.stepFinished(fixStep.Status)
+	rr.Steps = append(rr.Steps, fixStep)
 
 	// --- Check phase ---
-	steps := e.Config.CheckSteps()
 	results := make([]StepResult, len(steps))
 	for i, step := range steps {
 		results[i] = StepResult{Name: step, Status: "skipped"}
@@ -58,27 +84,46 @@ func (e *Engine) Check(ctx context.Context, packages []string, fix bool) (*Check
 
 	failedIdx := -1
 	for i, step := range steps {
+		prog.stepStarted(i+1, step)
+		ctx := withStep(ctx, step)
+		stepStart := time.Now()
 		switch step {
 		case "test":
-			summary, err := e.runTest(ctx, pkgs)
+			summaries, err := forGroups(ctx, e, groups, step, func(e *Engine, ctx context.Context, g packageGroup) (*TestSummary, error) {
+				return e.runTest(ctx, g.packages)
+			})
+			summary := mergeTestSummaries(summaries)
+			if summary != nil {
+				rr.Tests = summary.Packages
+			}
 			if err != nil {
-				results[i] = StepResult{Name: step, Status: "fail", Output: err.Error()}
+				results[i] = checkStepError(step, err)
 				failedIdx = i
 			} else if summary.Status == "FAIL" {
 				results[i] = StepResult{Name: step, Status: "fail", Output: summary.String()}
 				failedIdx = i
 				for _, f := range summary.Errors {
 					msg := FirstLine(f.Output)
-					rr.TestFailures = append(rr.TestFailures, report.TestFailure{
+					tf := report.TestFailure{
 						Package: f.Package,
 						Test:    f.Test,
 						Message: msg,
 						Output:  f.Output,
-					})
+					}
+					// Test output reports files relative to the package
+					// directory; record them as package-qualified paths.
+					if file, line, _ := sourceLocation(f.Output); file != "" {
+						tf.File, tf.Line = path.Join(f.Package, file), line
+					}
+					rr.TestFailures = append(rr.TestFailures, tf)
 				}
 				for _, be := range summary.BuildErrors {
+					file, line, col := sourceLocation(be.Output)
 					rr.BuildErrors = append(rr.BuildErrors, report.BuildError{
 						Package: be.ImportPath,
+						File:    e.buildFile(rr.Module, file),
+						Line:    line,
+						Col:     col,
 						Message: be.Output,
 					})
 				}
@@ -87,21 +132,19 @@ func (e *Engine) Check(ctx context.Context, packages []string, fix bool) (*Check
 			}
 
 		case "lint":
-			summary, err := e.runLint(ctx, pkgs)
+			summaries, err := forGroups(ctx, e, groups, step, func(e *Engine, ctx context.Context, g packageGroup) (*LintSummary, error) {
+				return e.runLint(ctx, g.packages)
+			})
+			summary := mergeLintSummaries(summaries)
 			if err != nil {
-				var unavail ErrToolUnavailable
-				if errors.As(err, &unavail) {
-					results[i] = StepResult{Name: step, Status: "unavailable", Detail: err.Error()}
-					failedIdx = i
-				} else {
-					results[i] = StepResult{Name: step, Status: "fail", Output: err.Error()}
-					failedIdx = i
-				}
+				results[i] = checkStepError(step, err)
+				failedIdx = i
 			} else if len(summary.Issues) > 0 {
 				results[i] = StepResult{Name: step, Status: "fail", Output: summary.String()}
 				failedIdx = i
 				for _, issue := range summary.Issues {
 					rr.LintIssues = append(rr.LintIssues, report.LintIssue{
+						Package: filePackage(rr.Module, e.repoRoot(), issue.File),
 						File:    issue.File,
 						Line:    issue.Line,
 						Col:     issue.Column,
@@ -114,16 +157,13 @@ func (e *Engine) Check(ctx context.Context, packages []string, fix bool) (*Check
 			}
 
 		case "staticcheck":
-			scResult, err := e.runStaticcheck(ctx, pkgs)
+			scResults, err := forGroups(ctx, e, groups, step, func(e *Engine, ctx context.Context, g packageGroup) (*StaticcheckResult, error) {
+				return e.runStaticcheck(ctx, g.packages)
+			})
+			scResult := mergeStaticcheckResults(scResults)
 			if err != nil {
-				var unavail ErrToolUnavailable
-				if errors.As(err, &unavail) {
-					results[i] = StepResult{Name: step, Status: "unavailable", Detail: err.Error()}
-					failedIdx = i
-				} else {
-					results[i] = StepResult{Name: step, Status: "fail", Output: err.Error()}
-					failedIdx = i
-				}
+				results[i] = checkStepError(step, err)
+				failedIdx = i
 			} else if len(scResult.Issues) > 0 {
 				results[i] = StepResult{Name: step, Status: "fail", Output: scResult.String()}
 				failedIdx = i
@@ -137,11 +177,30 @@ func (e *Engine) Check(ctx context.Context, packages []string, fix bool) (*Check
 			failedIdx = i
 		}
 
+		results[i].Elapsed = time.Since(stepStart)
+		prog.stepFinished(results[i].Status)
+
 		if failedIdx >= 0 {
 			break
 		}
 	}
 
+	for _, r := range results {
+		rr.Steps = append(rr.Steps, report.StepRecord{
+			Name:    r.Name,
+			Status:  r.Status,
+			Detail:  r.Detail,
+			Elapsed: r.Elapsed.Seconds(),
+			Log:     stepLog(ctx, r.Name),
+			Usage:   stepUsage(ctx, r.Name),
+		})
+	}
+	rr.Status = "pass"
+	if failedIdx >= 0 {
+		rr.Status = "fail"
+	}
+	e.finishRun(ctx, rr)
+
 	return &CheckResult{
 		RunResult: rr,
 		Steps:     results,
@@ -149,6 +208,51 @@ func (e *Engine) Check(ctx context.Context, packages []string, fix bool) (*Check
 	}, nil
 }
 
+// sourcePos matches a "file.go:line[:col]: " position, as printed by the
+// compiler and by t.Error and friends.
+var sourcePos = regexp.MustCompile(`^\s*(\S+\.go):(\d+)(?::(\d+))?: `)
+
+// sourceLocation returns the first source position reported in output.
+func sourceLocation(output string) (file string, line, col int) {
+	for _, l := range strings.Split(output, "\n") {
+		m := sourcePos.FindStringSubmatch(l)
+		if m == nil {
+			continue
+		}
+		line, _ = strconv.Atoi(m[2])
+		col, _ = strconv.Atoi(m[3])
+		return m[1], line, col
+	}
+	return "", 0, 0
+}
+
+// buildFile returns the file of a compiler error, which go reports
+// relative to the directory it ran in, as a module-qualified path like the
+// files of test failures. Files outside the module are made absolute.
+func (e *Engine) buildFile(module, file string) string {
+	if file == "" || filepath.IsAbs(file) {
+		return file
+	}
+	abs := filepath.Join(e.commandDir(), file)
+	rel, err := filepath.Rel(e.repoRoot(), abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return abs
+	}
+	if module == "" {
+		return filepath.ToSlash(rel)
+	}
+	return path.Join(module, filepath.ToSlash(rel))
+}
+
+// commandDir returns the directory the commands run without a cwd run in:
+// that of the runner if it reports one, or else the workspace.
+func (e *Engine) commandDir() string {
+	if r, ok := e.Runner.(DirReporter); ok && r.Dir() != "" {
+		return r.Dir()
+	}
+	return e.Workspace
+}
+
 // FirstLine returns the first non-empty line of s, trimmed,
 // skipping test framework boilerplate lines.
 func FirstLine(s string) string {
@@ -204,3 +308,16 @@ func FormatFailureSymbols(rr *report.RunResult) []string {
 
 	return out
 }
+
+// checkStepError returns the result of a check step that failed with err.
+func checkStepError(step string, err error) StepResult {
+	var unavail ErrToolUnavailable
+	var interrupted ErrInterrupted
+	switch {
+	case errors.As(err, &unavail):
+		return StepResult{Name: step, Status: "unavailable", Detail: err.Error()}
+	case errors.As(err, &interrupted):
+		return StepResult{Name: step, Status: interrupted.StepStatus(), Detail: interrupted.Error()}
+	}
+	return StepResult{Name: step, Status: "fail", Output: err.Error()}
+}
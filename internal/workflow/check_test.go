@@ -3,6 +3,9 @@ package workflow
 import (
 	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -17,14 +20,36 @@ type fakeRunner struct {
 	// The key is derived from argv by the caller.
 	Results map[string]*runner.Result
 	Err     map[string]error
+
+	// Options records the options of the last call for each key.
+	Options map[string]runner.Options
+
+	// Waits maps a command key to the wait states reported before it runs.
+	Waits map[string][]runner.Wait
 }
 
-func (f *fakeRunner) Run(_ context.Context, argv []string, _ string) (*runner.Result, error) {
+func (f *fakeRunner) Run(_ context.Context, argv []string, _ string, opts ...runner.Option) (*runner.Result, error) {
 	key := fakeRunnerKey(argv)
+	if f.Options == nil {
+		f.Options = make(map[string]runner.Options)
+	}
+	f.Options[key] = runner.Apply(opts...)
+	if wait := f.Options[key].Wait; wait != nil {
+		for _, w := range f.Waits[key] {
+			wait(w)
+		}
+	}
 	if err, ok := f.Err[key]; ok {
 		return nil, err
 	}
 	if r, ok := f.Results[key]; ok {
+		if o := f.Options[key]; o.Stdout != nil {
+			_, _ = o.Stdout.Write(r.Stdout)
+		}
+		if o := f.Options[key]; o.Log != nil {
+			_, _ = o.Log.Write(r.Stdout)
+			_, _ = o.Log.Write(r.Stderr)
+		}
 		return r, nil
 	}
 	// Default: success with no output.
@@ -137,6 +162,43 @@ func TestCheck_TestFails(t *testing.T) {
 	}
 }
 
+func TestCheck_TestTimesOut(t *testing.T) {
+	// go test was stopped after one package completed.
+	fr := &fakeRunner{
+		Results: map[string]*runner.Result{
+			"go test": {ExitCode: -1, Status: runner.TimedOut, Stdout: append(passingTestJSON(),
+				`{"Action":"pass","Package":"example.com/foo"}`+"\n"...)},
+		},
+	}
+	e := &Engine{
+		Config:    &config.Config{Check: config.CheckConfig{Steps: []string{"test", "lint"}}},
+		Runner:    fr,
+		Workspace: "/project",
+		RepoRoot:  "/project",
+	}
+
+	result, err := e.Check(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if result.FailedIdx != 0 {
+		t.Fatalf("FailedIdx = %d, want 0", result.FailedIdx)
+	}
+	step := result.Steps[0]
+	if step.Status != "timeout" {
+		t.Errorf("Steps[0].Status = %q, want timeout", step.Status)
+	}
+	if !strings.HasPrefix(step.Detail, "go test timed out") {
+		t.Errorf("Steps[0].Detail = %q, want go test timed out", step.Detail)
+	}
+	if result.Steps[1].Status != "skipped" {
+		t.Errorf("Steps[1].Status = %q, want skipped", result.Steps[1].Status)
+	}
+	if rr := result.RunResult; rr.Status != "fail" || len(rr.Tests) != 1 {
+		t.Errorf("RunResult: status %q with %d test packages, want fail with 1", rr.Status, len(rr.Tests))
+	}
+}
+
 func TestCheck_UnknownStep(t *testing.T) {
 	fr := &fakeRunner{
 		Results: map[string]*runner.Result{
@@ -193,3 +255,190 @@ func TestCheck_NilSlicesOnPass(t *testing.T) {
 		t.Errorf("StaticIssues should be nil, got %v", rr.StaticIssues)
 	}
 }
+
+func TestCheck_RecordsMetadata(t *testing.T) {
+	fr := &fakeRunner{
+		Results: map[string]*runner.Result{
+			"go test": {ExitCode: 1, Stdout: failingTestJSON()},
+			"go env":  {ExitCode: 0, Stdout: []byte("go1.25.1\n")},
+			"go list": {ExitCode: 0, Stdout: []byte("example.com/foo\nexample.com/foo/bar\n")},
+			"git":     {ExitCode: 0, Stdout: []byte("0123abcd\n")},
+		},
+	}
+	e := &Engine{
+		Config:    &config.Config{Check: config.CheckConfig{Steps: []string{"test", "lint"}}},
+		Runner:    fr,
+		Workspace: "/project",
+		RepoRoot:  "/project",
+	}
+
+	result, err := e.Check(context.Background(), []string{"./..."}, false)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	rr := result.RunResult
+	if rr.Started.IsZero() || rr.Finished.Before(rr.Started) {
+		t.Errorf("Started = %v, Finished = %v; want a valid interval", rr.Started, rr.Finished)
+	}
+	if rr.Commit != "0123abcd" {
+		t.Errorf("Commit = %q, want 0123abcd", rr.Commit)
+	}
+	if rr.GoVersion != "go1.25.1" {
+		t.Errorf("GoVersion = %q, want go1.25.1", rr.GoVersion)
+	}
+	if len(rr.ResolvedPackages) != 2 {
+		t.Errorf("ResolvedPackages = %v, want 2 packages", rr.ResolvedPackages)
+	}
+	if rr.ConfigHash != e.Config.Hash() {
+		t.Errorf("ConfigHash = %q, want %q", rr.ConfigHash, e.Config.Hash())
+	}
+
+	var names, statuses []string
+	for _, st := range rr.Steps {
+		names = append(names, st.Name)
+		statuses = append(statuses, st.Status)
+	}
+	if got := strings.Join(names, ","); got != "format,test,lint" {
+		t.Errorf("step names = %s, want format,test,lint", got)
+	}
+	if got := strings.Join(statuses, ","); got != "pass,fail,skipped" {
+		t.Errorf("step statuses = %s, want pass,fail,skipped", got)
+	}
+}
+
+func TestCheck_RecordsProfile(t *testing.T) {
+	e := &Engine{
+		Config: &config.Config{
+			Check:    config.CheckConfig{Steps: []string{"test", "lint"}},
+			Profiles: map[string]config.Profile{"quick": {Check: config.CheckConfig{Steps: []string{"test"}}}},
+		},
+		Runner:    &fakeRunner{Results: map[string]*runner.Result{"go test": {Stdout: passingTestJSON()}}},
+		Workspace: "/project",
+		RepoRoot:  "/project",
+	}
+	pe, err := e.WithProfile("quick")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := pe.Check(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	rr := result.RunResult
+	if rr.Profile != "quick" {
+		t.Errorf("Profile = %q, want quick", rr.Profile)
+	}
+	if rr.ConfigHash == e.Config.Hash() {
+		t.Error("ConfigHash is that of the configuration without the profile")
+	}
+	if len(rr.Steps) != 2 || rr.Steps[1].Name != "test" {
+		t.Errorf("steps = %+v, want format and the profile's test", rr.Steps)
+	}
+	if _, err := e.WithProfile("ci"); err == nil {
+		t.Error("WithProfile(ci) succeeded, want an unknown profile error")
+	}
+}
+
+func TestCheck_LintIssuesHavePackage(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/foo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lintJSON := `{"Issues":[
+		{"FromLinter":"errcheck","Text":"unchecked error","Pos":{"Filename":"internal/db/db.go","Line":3,"Column":2}},
+		{"FromLinter":"unused","Text":"func x is unused","Pos":{"Filename":"main.go","Line":9,"Column":6}}
+	]}`
+	fr := &resolvingRunner{
+		fakeRunner: fakeRunner{Results: map[string]*runner.Result{
+			"/usr/bin/golangci-lint": {ExitCode: 1, Stdout: []byte(lintJSON)},
+		}},
+		Tools: map[string][]string{"golangci-lint": {"/usr/bin/golangci-lint"}},
+	}
+	e := &Engine{
+		Config:    &config.Config{Check: config.CheckConfig{Steps: []string{"lint"}}},
+		Runner:    fr,
+		Workspace: dir,
+		RepoRoot:  dir,
+	}
+
+	result, err := e.Check(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	var got []string
+	for _, issue := range result.RunResult.LintIssues {
+		got = append(got, issue.Package)
+	}
+	if want := []string{"example.com/foo/internal/db", "example.com/foo"}; !slices.Equal(got, want) {
+		t.Errorf("lint issue packages = %q, want %q", got, want)
+	}
+}
+
+// dirRunner is a fakeRunner running commands in dir, as runner.Runner
+// runs them in its workspace.
+type dirRunner struct {
+	fakeRunner
+	dir string
+}
+
+func (r *dirRunner) Dir() string {
+	return r.dir
+}
+
+func TestCheck_BuildErrorInSubpackage(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/foo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// go reports files relative to the directory it runs in.
+	testJSON := func(file string) []byte {
+		return []byte(strings.Join([]string{
+			`{"ImportPath":"example.com/foo/api/v1","Action":"build-output","Output":"# example.com/foo/api/v1\n"}`,
+			`{"ImportPath":"example.com/foo/api/v1","Action":"build-output","Output":"` + file + `:12:2: undefined: serve\n"}`,
+			`{"ImportPath":"example.com/foo/api/v1","Action":"build-fail"}`,
+			`{"Action":"fail","Package":"example.com/foo/api/v1"}`,
+		}, "\n"))
+	}
+
+	for _, tc := range []struct {
+		name   string
+		runner CommandRunner
+	}{
+		{
+			// The runner reports no directory: go runs in the workspace.
+			name:   "workspace",
+			runner: &fakeRunner{Results: map[string]*runner.Result{"go test": {ExitCode: 1, Stdout: testJSON("v1/handler.go")}}},
+		},
+		{
+			// As for the CLI started in a subdirectory: go runs at the
+			// repository root, not in the workspace.
+			name: "runner directory",
+			runner: &dirRunner{
+				fakeRunner: fakeRunner{Results: map[string]*runner.Result{"go test": {ExitCode: 1, Stdout: testJSON("api/v1/handler.go")}}},
+				dir:        root,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &Engine{
+				Config:    &config.Config{Check: config.CheckConfig{Steps: []string{"test"}}},
+				Runner:    tc.runner,
+				Workspace: filepath.Join(root, "api"),
+				RepoRoot:  root,
+			}
+
+			result, err := e.Check(context.Background(), []string{"./v1"}, false)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			errs := result.RunResult.BuildErrors
+			if len(errs) != 1 {
+				t.Fatalf("BuildErrors = %+v, want 1", errs)
+			}
+			if be := errs[0]; be.File != "example.com/foo/api/v1/handler.go" || be.Line != 12 || be.Col != 2 {
+				t.Errorf("build error at %s:%d:%d, want example.com/foo/api/v1/handler.go:12:2", be.File, be.Line, be.Col)
+			}
+		})
+	}
+}
@@ -10,9 +10,9 @@ import (
 )
 
 func (e *Engine) runComplexity(ctx context.Context, packages []string) ([]report.ComplexityEntry, error) {
-	argv := ResolveTool("gocognit")
-	if argv == nil {
-		return nil, NewErrToolUnavailable("gocognit")
+	argv, err := e.requireTool(ctx, "gocognit")
+	if err != nil {
+		return nil, err
 	}
 
 	pkgs := e.ResolvePackages(packages)
@@ -20,7 +20,7 @@ func (e *Engine) runComplexity(ctx context.Context, packages []string) ([]report
 	argv = append(argv, e.Config.Audit.Complexity.Args...)
 	argv = append(argv, pkgs...)
 
-	result, err := e.Runner.Run(ctx, argv, "")
+	result, err := e.run(ctx, argv, "")
 	if err != nil {
 		return nil, fmt.Errorf("executing gocognit: %w", err)
 	}
@@ -6,19 +6,20 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/deixis/governor/internal/report"
 )
 
-func (e *Engine) runCoverage(ctx context.Context, packages []string) ([]report.CoverageEntry, error) {
+func (e *Engine) runCoverage(ctx context.Context, packages []string) ([]report.CoverageEntry, []report.CoverageFile, error) {
 	pkgs := e.ResolvePackages(packages)
 
 	// Create a temp file for the cover profile.
 	f, err := os.CreateTemp("", "governor-cover-*.out")
 	if err != nil {
-		return nil, fmt.Errorf("creating cover profile: %w", err)
+		return nil, nil, fmt.Errorf("creating cover profile: %w", err)
 	}
 	coverFile := f.Name()
 	_ = f.Close()
@@ -29,22 +30,27 @@ func (e *Engine) runCoverage(ctx context.Context, packages []string) ([]report.C
 	argv = append(argv, e.Config.Audit.Coverage.Args...)
 	argv = append(argv, pkgs...)
 
-	result, err := e.Runner.Run(ctx, argv, "")
+	result, err := e.run(ctx, argv, "")
 	if err != nil {
-		return nil, fmt.Errorf("executing go test -coverprofile: %w", err)
+		return nil, nil, fmt.Errorf("executing go test -coverprofile: %w", err)
 	}
 	if result.ExitCode != 0 {
-		return nil, fmt.Errorf("go test -coverprofile failed (exit %d): %s", result.ExitCode, string(result.Stderr))
+		return nil, nil, fmt.Errorf("go test -coverprofile failed (exit %d): %s", result.ExitCode, string(result.Stderr))
+	}
+
+	profile, err := os.ReadFile(coverFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading cover profile: %w", err)
 	}
 
 	// Run go tool cover -func to get per-function coverage.
 	coverArgv := []string{"go", "tool", "cover", "-func", coverFile}
-	coverResult, err := e.Runner.Run(ctx, coverArgv, "")
+	coverResult, err := e.run(ctx, coverArgv, "")
 	if err != nil {
-		return nil, fmt.Errorf("executing go tool cover -func: %w", err)
+		return nil, nil, fmt.Errorf("executing go tool cover -func: %w", err)
 	}
 
-	return parseCoverFunc(coverResult.Stdout), nil
+	return parseCoverFunc(coverResult.Stdout), parseCoverProfile(profile), nil
 }
 
 // coverFuncLine matches lines from `go tool cover -func`:
@@ -96,6 +102,101 @@ func parseCoverFunc(data []byte) []report.CoverageEntry {
 	return entries
 }
 
+// parseCoverProfile aggregates a cover profile into per-file statement
+// coverage. Profile lines have the form
+//
+//	github.com/foo/bar/baz.go:12.34,15.2 3 1
+//
+// giving a block's start and end positions, its number of statements and
+// how many times it ran. A block may appear more than once when several
+// test binaries cover it; it counts as covered if any of them ran it.
+func parseCoverProfile(data []byte) []report.CoverageFile {
+	type block struct {
+		file       string
+		start, end int
+		pos        string
+	}
+	stmts := make(map[block]int)
+	counts := make(map[block]int)
+	var order []block
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		file, pos, ok := strings.Cut(fields[0], ":")
+		if !ok {
+			continue
+		}
+		startPos, endPos, ok := strings.Cut(pos, ",")
+		if !ok {
+			continue
+		}
+		startLine, _, _ := strings.Cut(startPos, ".")
+		endLine, _, _ := strings.Cut(endPos, ".")
+		start, err1 := strconv.Atoi(startLine)
+		end, err2 := strconv.Atoi(endLine)
+		n, err3 := strconv.Atoi(fields[1])
+		count, err4 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			continue
+		}
+
+		b := block{file: file, start: start, end: end, pos: pos}
+		if _, ok := stmts[b]; !ok {
+			order = append(order, b)
+		}
+		stmts[b] = n
+		counts[b] = max(counts[b], count)
+	}
+
+	var files []report.CoverageFile
+	index := make(map[string]int)
+	for _, b := range order {
+		i, ok := index[b.file]
+		if !ok {
+			i = len(files)
+			index[b.file] = i
+			files = append(files, report.CoverageFile{File: b.file})
+		}
+		f := &files[i]
+		f.Statements += stmts[b]
+		if counts[b] > 0 {
+			f.Covered += stmts[b]
+		} else if stmts[b] > 0 {
+			f.Uncovered = append(f.Uncovered, report.LineRange{Start: b.start, End: b.end})
+		}
+	}
+	for i := range files {
+		files[i].Uncovered = mergeRanges(files[i].Uncovered)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].File < files[j].File })
+	return files
+}
+
+// mergeRanges sorts ranges and merges those that overlap or touch.
+func mergeRanges(ranges []report.LineRange) []report.LineRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End+1 {
+			last.End = max(last.End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
 // CoverageSummary holds aggregated stats for output formatting.
 type CoverageSummary struct {
 	Packages  int
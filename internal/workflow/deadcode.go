@@ -11,9 +11,9 @@ import (
 )
 
 func (e *Engine) runDeadcode(ctx context.Context, packages []string) ([]report.DeadFunc, error) {
-	argv := ResolveTool("deadcode")
-	if argv == nil {
-		return nil, NewErrToolUnavailable("deadcode")
+	argv, err := e.requireTool(ctx, "deadcode")
+	if err != nil {
+		return nil, err
 	}
 
 	pkgs := e.ResolvePackages(packages)
@@ -22,7 +22,7 @@ func (e *Engine) runDeadcode(ctx context.Context, packages []string) ([]report.D
 	argv = append(argv, e.Config.Audit.Deadcode.Args...)
 	argv = append(argv, pkgs...)
 
-	result, err := e.Runner.Run(ctx, argv, "")
+	result, err := e.run(ctx, argv, "")
 	if err != nil {
 		return nil, fmt.Errorf("executing deadcode: %w", err)
 	}
@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+// maxDiffFindings is the maximum number of new or resolved findings listed
+// per source in a formatted diff.
+const maxDiffFindings = 20
+
+// FormatDiff formats a run diff: totals, then new and resolved findings per
+// source, then metric deltas. Unchanged findings are only counted.
+func FormatDiff(d *report.Diff) string {
+	var b strings.Builder
+
+	added, resolved, unchanged := d.Totals()
+	fmt.Fprintf(&b, "Diff: %s -> %s (%s)\n", d.Old, d.New, d.Kind)
+	fmt.Fprintf(&b, "Findings: %d new, %d resolved, %d unchanged\n", added, resolved, unchanged)
+	fmt.Fprintln(&b)
+
+	for _, s := range d.Sources {
+		if len(s.Unmeasured) > 0 {
+			fmt.Fprintf(&b, "%s: %d unmeasured (the step did not complete in both runs)\n\n", s.Source, len(s.Unmeasured))
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %d new, %d resolved, %d unchanged\n", s.Source, len(s.New), len(s.Resolved), len(s.Unchanged))
+		writeDiffFindings(&b, "+", s.New)
+		writeDiffFindings(&b, "-", s.Resolved)
+		fmt.Fprintln(&b)
+	}
+
+	if len(d.Metrics) > 0 {
+		fmt.Fprintln(&b, "Metrics:")
+		for _, m := range d.Metrics {
+			fmt.Fprintf(&b, "  %-15s %s -> %s", m.Name, formatMetric(m.Name, m.Old), formatMetric(m.Name, m.New))
+			if delta := m.Delta(); delta != 0 {
+				fmt.Fprintf(&b, " (%+g)", roundMetric(delta))
+			}
+			fmt.Fprintln(&b)
+		}
+	}
+	if len(d.Unmeasured) > 0 {
+		fmt.Fprintf(&b, "Not measured by both runs: %s\n", strings.Join(d.Unmeasured, ", "))
+	}
+
+	return b.String()
+}
+
+func writeDiffFindings(b *strings.Builder, marker string, diags []report.Diagnostic) {
+	for i, d := range diags {
+		if i >= maxDiffFindings {
+			fmt.Fprintf(b, "  %s ... and %d more\n", marker, len(diags)-maxDiffFindings)
+			return
+		}
+		fmt.Fprintf(b, "  %s %s\n", marker, formatDiagnosticLine(d))
+	}
+}
+
+// formatDiagnosticLine formats a diagnostic on a single line, led by its
+// position when known.
+func formatDiagnosticLine(d report.Diagnostic) string {
+	var b strings.Builder
+	switch {
+	case d.File != "" && d.Line > 0 && d.Col > 0:
+		fmt.Fprintf(&b, "%s:%d:%d: ", d.File, d.Line, d.Col)
+	case d.File != "" && d.Line > 0:
+		fmt.Fprintf(&b, "%s:%d: ", d.File, d.Line)
+	case d.File != "":
+		fmt.Fprintf(&b, "%s: ", d.File)
+	case d.Package != "":
+		fmt.Fprintf(&b, "%s: ", d.Package)
+	}
+	tag := d.Source
+	if d.Detail != "" {
+		tag = d.Source + "/" + d.Detail
+	}
+	if d.Symbol != "" {
+		fmt.Fprintf(&b, "[%s] %s: %s", tag, d.Symbol, d.Message)
+	} else {
+		fmt.Fprintf(&b, "[%s] %s", tag, d.Message)
+	}
+	return b.String()
+}
+
+// formatMetric formats a metric value for display.
+func formatMetric(name string, v float64) string {
+	if name == report.MetricCoverage {
+		return fmt.Sprintf("%.1f%%", v)
+	}
+	return fmt.Sprintf("%g", roundMetric(v))
+}
+
+// roundMetric rounds v to one decimal place.
+func roundMetric(v float64) float64 {
+	return math.Round(v*10) / 10
+}
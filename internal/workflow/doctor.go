@@ -0,0 +1,302 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/deixis/governor/internal/config"
+)
+
+// Tool statuses reported by Doctor.
+const (
+	ToolOK       = "ok"
+	ToolMissing  = "missing"
+	ToolOutdated = "outdated"
+	ToolMismatch = "mismatch" // does not match the version pinned in .governor
+)
+
+// Diagnosis is the outcome of Doctor: whether the tools the configured
+// steps need are installed, and what is wrong with the configuration.
+type Diagnosis struct {
+	GoVersion      string       `json:"go_version,omitempty"` // toolchain that runs the steps
+	Module         string       `json:"module,omitempty"`
+	GoDirective    string       `json:"go_directive,omitempty"` // go line of go.mod
+	Toolchain      string       `json:"toolchain,omitempty"`    // toolchain line of go.mod
+	Tools          []ToolStatus `json:"tools"`
+	ConfigProblems []string     `json:"config_problems,omitempty"`
+}
+
+// ToolStatus describes one tool in a Diagnosis.
+type ToolStatus struct {
+	Name       string   `json:"name"`
+	Steps      []string `json:"steps,omitempty"`  // configured steps that run it
+	Required   bool     `json:"required"`         // a configured step cannot run without it
+	Status     string   `json:"status"`           // ok, missing, outdated or mismatch
+	Source     string   `json:"source,omitempty"` // "go.mod" for a tool directive, or "PATH"
+	Path       string   `json:"path,omitempty"`
+	Version    string   `json:"version,omitempty"`
+	MinVersion string   `json:"min_version,omitempty"`
+	Pinned     string   `json:"pinned,omitempty"`  // version constraint in .governor
+	Detail     string   `json:"detail,omitempty"`  // why the version was refused
+	Install    string   `json:"install,omitempty"` // how to install it, when not ok
+}
+
+// OK reports whether every required tool is usable and the configuration
+// has no problems.
+func (d *Diagnosis) OK() bool {
+	return len(d.Failures()) == 0 && len(d.ConfigProblems) == 0
+}
+
+// Failures returns the required tools that are missing, outdated or do not
+// match their pinned version.
+func (d *Diagnosis) Failures() []ToolStatus {
+	var failed []ToolStatus
+	for _, t := range d.Tools {
+		if t.Required && t.Status != ToolOK {
+			failed = append(failed, t)
+		}
+	}
+	return failed
+}
+
+// stepTools maps each step to the tools it runs. Steps that only run the
+// go command are absent.
+var stepTools = map[string][]string{
+	"lint":        {"golangci-lint"},
+	"staticcheck": {"staticcheck"},
+	"complexity":  {"gocognit"},
+	"deadcode":    {"deadcode"},
+	"dupl":        {"dupl"},
+	"vulncheck":   {"govulncheck"},
+}
+
+// Doctor resolves every tool used by the configured check and audit steps
+// and reports where each came from, its version and whether it is recent
+// enough, along with the Go toolchain and configuration problems.
+// gofumpt, which the format and fix phase skip when it is missing, and
+// gopls, which only the MCP server uses, are reported as optional.
+func (e *Engine) Doctor(ctx context.Context) *Diagnosis {
+	d := &Diagnosis{GoVersion: e.goEnv(ctx, "GOVERSION")}
+	if m, err := readGoMod(filepath.Join(e.repoRoot(), "go.mod")); err == nil {
+		d.Module, d.GoDirective, d.Toolchain = m.Module, m.Go, m.Toolchain
+	}
+
+	var names []string
+	steps := make(map[string][]string)
+	required := make(map[string]bool)
+	use := func(step, tool string, req bool) {
+		if !slices.Contains(names, tool) {
+			names = append(names, tool)
+		}
+		if step != "" && !slices.Contains(steps[tool], step) {
+			steps[tool] = append(steps[tool], step)
+		}
+		required[tool] = required[tool] || req
+	}
+	for _, cfg := range e.Config.Variants() {
+		for _, step := range append(slices.Clone(cfg.CheckSteps()), cfg.AuditSteps()...) {
+			for _, tool := range stepTools[step] {
+				use(step, tool, true)
+			}
+		}
+	}
+	use("format", "gofumpt", false)
+	use("fix", "gofumpt", false)
+	use("fix", "golangci-lint", false)
+	use("", "gopls", false)
+
+	for _, name := range names {
+		d.Tools = append(d.Tools, e.diagnoseTool(ctx, name, steps[name], required[name]))
+	}
+
+	for _, p := range e.ConfigProblems() {
+		d.ConfigProblems = append(d.ConfigProblems, p.String())
+	}
+	return d
+}
+
+// ConfigProblems returns the Config's Problems, along with the settings
+// that only the engine can check, at the top level and in each profile:
+// tool pins, which must name a tool Governor runs and a valid constraint,
+// and lint configuration files, which must exist.
+func (e *Engine) ConfigProblems() []config.Problem {
+	problems := e.Config.Problems()
+	problems = append(problems, e.settingProblems("", e.Config)...)
+	for _, name := range e.Config.ProfileNames() {
+		problems = append(problems, e.settingProblems("profiles."+name+".", e.Config.Profiles[name].Config())...)
+	}
+	return problems
+}
+
+// settingProblems returns the problems of the tool pins and lint
+// configuration files of cfg, with their keys prefixed by prefix.
+func (e *Engine) settingProblems(prefix string, cfg *config.Config) []config.Problem {
+	var problems []config.Problem
+	for _, name := range slices.Sorted(maps.Keys(cfg.Tools)) {
+		key := prefix + "tools." + name
+		if _, ok := knownTools[name]; !ok {
+			problems = append(problems, config.Problem{Key: key, Message: "unknown tool"})
+		} else if err := validConstraint(cfg.Tools[name]); err != nil {
+			problems = append(problems, config.Problem{Key: key, Message: err.Error()})
+		}
+	}
+	lintConfigs := []string{"lint.config", cfg.Lint.Config}
+	for i, o := range cfg.Overrides {
+		lintConfigs = append(lintConfigs, fmt.Sprintf("overrides[%d].lint.config", i), o.Lint.Config)
+	}
+	for i := 0; i < len(lintConfigs); i += 2 {
+		key, c := prefix+lintConfigs[i], lintConfigs[i+1]
+		if c == "" {
+			continue
+		}
+		path := c
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(e.repoRoot(), c)
+		}
+		if _, err := os.Stat(path); err != nil {
+			problems = append(problems, config.Problem{Key: key, Message: c + " not found"})
+		}
+	}
+	return problems
+}
+
+func (e *Engine) diagnoseTool(ctx context.Context, name string, steps []string, required bool) ToolStatus {
+	t := ToolStatus{Name: name, Steps: steps, Required: required, Status: ToolOK, Pinned: e.Config.Tools[name]}
+	info, known := knownTools[name]
+	if known {
+		t.MinVersion = info.MinVersion
+	}
+
+	argv := e.resolveTool(ctx, name)
+	if argv == nil {
+		t.Status = ToolMissing
+		t.Install = installHint(name)
+		return t
+	}
+
+	rec := e.describeTool(ctx, name, argv)
+	t.Path, t.Version = rec.Path, rec.Version
+	t.Source = "PATH"
+	if len(argv) > 1 && argv[1] == "tool" {
+		t.Source = "go.mod"
+	}
+	if t.MinVersion != "" && t.Version != "" {
+		if cmp, ok := compareVersions(t.Version, t.MinVersion); ok && cmp < 0 {
+			t.Status = ToolOutdated
+			t.Detail = fmt.Sprintf("%s is older than %s, the oldest version Governor supports", t.Version, t.MinVersion)
+			t.Install = installHint(name)
+		}
+	}
+	var unavail ErrToolUnavailable
+	if err := checkPin(rec); errors.As(err, &unavail) {
+		t.Status = ToolMismatch
+		t.Detail = unavail.Mismatch
+		t.Install = installHint(name)
+	}
+	return t
+}
+
+// installHint returns how to install or upgrade a known tool.
+func installHint(name string) string {
+	info, ok := knownTools[name]
+	switch {
+	case !ok:
+		return ""
+	case info.NoGoInstall:
+		return info.AltInstall
+	default:
+		return "go get -tool " + strings.TrimSuffix(info.ImportPath, "@latest")
+	}
+}
+
+// repoRoot returns the module root, or the workspace if it is unknown.
+func (e *Engine) repoRoot() string {
+	if e.RepoRoot != "" {
+		return e.RepoRoot
+	}
+	return e.Workspace
+}
+
+// FormatDiagnosis renders a Diagnosis as text.
+func FormatDiagnosis(d *Diagnosis) string {
+	var b strings.Builder
+
+	b.WriteString("Go:\n")
+	if d.GoVersion != "" {
+		fmt.Fprintf(&b, "  version:   %s\n", d.GoVersion)
+	}
+	if d.Module != "" {
+		fmt.Fprintf(&b, "  module:    %s\n", d.Module)
+	}
+	if d.GoDirective != "" {
+		line := "go " + d.GoDirective
+		if d.Toolchain != "" {
+			line += ", toolchain " + d.Toolchain
+		}
+		fmt.Fprintf(&b, "  go.mod:    %s\n", line)
+	}
+
+	b.WriteString("\nTools:\n")
+	for _, t := range d.Tools {
+		status := t.Status
+		if !t.Required && t.Status != ToolOK {
+			status += " (optional)"
+		}
+		version := t.Version
+		if t.Status != ToolMissing && version == "" {
+			version = "unknown"
+		}
+		fmt.Fprintf(&b, "  %-14s %-19s %-10s %-7s %s\n", t.Name, status, version, t.Source, toolUse(t))
+		switch {
+		case t.Detail != "":
+			fmt.Fprintf(&b, "      %s\n", t.Detail)
+		case t.Status == ToolOK && t.Pinned != "":
+			fmt.Fprintf(&b, "      %s, pinned %s\n", t.Path, t.Pinned)
+		case t.Status == ToolOK:
+			fmt.Fprintf(&b, "      %s\n", t.Path)
+		}
+		if t.Install != "" {
+			fmt.Fprintf(&b, "      Install: %s\n", t.Install)
+		}
+	}
+
+	if len(d.ConfigProblems) > 0 {
+		b.WriteString("\nConfig:\n")
+		for _, p := range d.ConfigProblems {
+			fmt.Fprintf(&b, "  %s\n", p)
+		}
+	}
+
+	b.WriteString("\n")
+	if d.OK() {
+		b.WriteString("Status: OK\n")
+		return b.String()
+	}
+	var reasons []string
+	if failed := d.Failures(); len(failed) > 0 {
+		names := make([]string, len(failed))
+		for i, t := range failed {
+			names[i] = t.Name
+		}
+		reasons = append(reasons, "required tools missing or unusable: "+strings.Join(names, ", "))
+	}
+	if n := len(d.ConfigProblems); n > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d config problem(s)", n))
+	}
+	fmt.Fprintf(&b, "Status: FAIL, %s\n", strings.Join(reasons, "; "))
+	return b.String()
+}
+
+// toolUse describes what a tool is used for.
+func toolUse(t ToolStatus) string {
+	if t.Name == "gopls" && len(t.Steps) == 0 {
+		return "MCP code intelligence"
+	}
+	return strings.Join(t.Steps, ", ")
+}
@@ -0,0 +1,129 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/runner"
+)
+
+// resolvingRunner is a fakeRunner that also decides which tools are
+// installed.
+type resolvingRunner struct {
+	fakeRunner
+	Tools map[string][]string
+}
+
+func (r *resolvingRunner) ResolveTool(name string) []string {
+	return r.Tools[name]
+}
+
+func TestDoctor(t *testing.T) {
+	dir := t.TempDir()
+	gomod := "module example.com/foo\n\ngo 1.25.1\n\ntoolchain go1.25.4\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fr := &resolvingRunner{
+		fakeRunner: fakeRunner{Results: map[string]*runner.Result{
+			"go env":     {Stdout: []byte("go1.25.4\n")},
+			"go version": {Stdout: []byte("/usr/bin/golangci-lint: go1.24.0\n\tmod\tgithub.com/golangci/golangci-lint\tv1.50.1\th1:abc=\n")},
+		}},
+		Tools: map[string][]string{
+			"golangci-lint": {"/usr/bin/golangci-lint"},
+			"staticcheck":   {"go", "tool", "staticcheck"},
+		},
+	}
+	eng := &Engine{
+		Config: &config.Config{
+			Check: config.CheckConfig{Steps: []string{"test", "lint", "staticcheck"}},
+			Audit: config.AuditConfig{Steps: []string{"coverage", "vulncheck"}},
+			Lint:  config.LintConfig{Config: ".golangci.yml"},
+		},
+		Runner:   fr,
+		RepoRoot: dir,
+	}
+
+	d := eng.Doctor(context.Background())
+	if d.Module != "example.com/foo" || d.GoDirective != "1.25.1" || d.Toolchain != "go1.25.4" || d.GoVersion != "go1.25.4" {
+		t.Errorf("go info = %q %q %q %q", d.Module, d.GoDirective, d.Toolchain, d.GoVersion)
+	}
+
+	tools := make(map[string]ToolStatus)
+	var names []string
+	for _, tool := range d.Tools {
+		tools[tool.Name] = tool
+		names = append(names, tool.Name)
+	}
+	if got, want := strings.Join(names, " "), "golangci-lint staticcheck govulncheck gofumpt gopls"; got != want {
+		t.Errorf("tools = %s, want %s", got, want)
+	}
+
+	lint := tools["golangci-lint"]
+	if lint.Status != ToolOutdated || lint.Version != "v1.50.1" || lint.Source != "PATH" || !lint.Required {
+		t.Errorf("golangci-lint = %+v, want required and outdated", lint)
+	}
+	if got := strings.Join(lint.Steps, ","); got != "lint,fix" {
+		t.Errorf("golangci-lint steps = %s", got)
+	}
+	if sc := tools["staticcheck"]; sc.Source != "go.mod" || sc.Path != "go tool staticcheck" {
+		t.Errorf("staticcheck = %+v, want go.mod tool", sc)
+	}
+	if vc := tools["govulncheck"]; vc.Status != ToolMissing || !vc.Required || vc.Install != "go get -tool golang.org/x/vuln/cmd/govulncheck" {
+		t.Errorf("govulncheck = %+v, want required and missing", vc)
+	}
+	if g := tools["gofumpt"]; g.Status != ToolMissing || g.Required {
+		t.Errorf("gofumpt = %+v, want optional and missing", g)
+	}
+
+	var failed []string
+	for _, tool := range d.Failures() {
+		failed = append(failed, tool.Name)
+	}
+	if strings.Join(failed, " ") != "golangci-lint govulncheck" {
+		t.Errorf("failures = %q", failed)
+	}
+	if len(d.ConfigProblems) != 1 || !strings.Contains(d.ConfigProblems[0], ".golangci.yml not found") {
+		t.Errorf("config problems = %q", d.ConfigProblems)
+	}
+	if d.OK() {
+		t.Error("OK() = true")
+	}
+
+	out := FormatDiagnosis(d)
+	for _, want := range []string{
+		"go.mod:    go 1.25.1, toolchain go1.25.4",
+		"v1.50.1 is older than v1.52.0",
+		"gofumpt        missing (optional)",
+		"MCP code intelligence",
+		"Status: FAIL, required tools missing or unusable: golangci-lint, govulncheck; 1 config problem(s)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDoctor_OK(t *testing.T) {
+	fr := &resolvingRunner{Tools: map[string][]string{"staticcheck": {"/bin/staticcheck"}}}
+	eng := &Engine{
+		Config: &config.Config{
+			Check: config.CheckConfig{Steps: []string{"test", "staticcheck"}},
+			Audit: config.AuditConfig{Steps: []string{"coverage"}},
+		},
+		Runner:    fr,
+		Workspace: t.TempDir(),
+	}
+	d := eng.Doctor(context.Background())
+	if !d.OK() {
+		t.Errorf("OK() = false:\n%s", FormatDiagnosis(d))
+	}
+	if out := FormatDiagnosis(d); !strings.Contains(out, "staticcheck    ok                  unknown") || !strings.Contains(out, "Status: OK") {
+		t.Errorf("output:\n%s", out)
+	}
+}
@@ -11,9 +11,9 @@ import (
 )
 
 func (e *Engine) runDupl(ctx context.Context, packages []string) ([]report.Duplicate, error) {
-	argv := ResolveTool("dupl")
-	if argv == nil {
-		return nil, NewErrToolUnavailable("dupl")
+	argv, err := e.requireTool(ctx, "dupl")
+	if err != nil {
+		return nil, err
 	}
 
 	threshold := e.Config.DuplThreshold()
@@ -24,7 +24,7 @@ func (e *Engine) runDupl(ctx context.Context, packages []string) ([]report.Dupli
 	// Pass "." to scan the workspace.
 	argv = append(argv, ".")
 
-	result, err := e.Runner.Run(ctx, argv, "")
+	result, err := e.run(ctx, argv, "")
 	if err != nil {
 		return nil, fmt.Errorf("executing dupl: %w", err)
 	}
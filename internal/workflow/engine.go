@@ -6,7 +6,7 @@ package workflow
 import (
 	"context"
 	"fmt"
-	"os/exec"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -17,7 +17,21 @@ import (
 // CommandRunner executes commands within a workspace.
 // Implemented by runner.Runner.
 type CommandRunner interface {
-	Run(ctx context.Context, argv []string, cwd string) (*runner.Result, error)
+	Run(ctx context.Context, argv []string, cwd string, opts ...runner.Option) (*runner.Result, error)
+}
+
+// ToolResolver decides which tools are available. A CommandRunner that
+// implements it, such as a replay of recorded commands, is asked instead of
+// the engine's ToolCache.
+type ToolResolver interface {
+	ResolveTool(name string) []string
+}
+
+// DirReporter is implemented by a CommandRunner that runs the commands it
+// is given no cwd for in a directory of its own, such as runner.Runner, so
+// that relative paths in their output can be resolved.
+type DirReporter interface {
+	Dir() string
 }
 
 // Engine holds shared dependencies for all workflow operations.
@@ -26,6 +40,33 @@ type Engine struct {
 	Runner    CommandRunner
 	Workspace string // cwd — commands run from here, ./... scopes to here
 	RepoRoot  string // module root — used for absolute-path resolution
+
+	// LogDir, if set, receives the complete output of the commands run by
+	// each step, in report.LogPath(LogDir, runID, step).
+	LogDir string
+
+	// Tools, if set, caches tool resolution across steps and runs.
+	Tools *ToolCache
+
+	// Profile names the configuration profile applied to Config, if any,
+	// for recording in runs.
+	Profile string
+}
+
+// WithProfile returns a copy of e running with the profile name of its
+// configuration applied, or e itself if name is empty.
+func (e *Engine) WithProfile(name string) (*Engine, error) {
+	if name == "" {
+		return e, nil
+	}
+	cfg, err := e.Config.WithProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	pe := *e
+	pe.Config = cfg
+	pe.Profile = name
+	return &pe, nil
 }
 
 // ResolvePackages normalises package arguments so that tools work
@@ -76,33 +117,17 @@ func (e *Engine) ResolvePackages(packages []string) []string {
 	return resolved
 }
 
-// ResolveTool returns the argv prefix for invoking a named tool.
-// It checks "go tool <name>" first (Go 1.24+ tool directive in go.mod),
-// then falls back to exec.LookPath on the system PATH.
+// ResolveTool returns the argv prefix for invoking a named tool in the
+// module containing the current directory. It returns "go tool <name>"
+// when a tool directive of go.mod (Go 1.24+) declares the tool, and
+// otherwise falls back to exec.LookPath on the system PATH.
 // Returns nil if the tool is not available.
 func ResolveTool(name string) []string {
-	// Check if the tool is available via "go tool".
-	goPath, err := exec.LookPath("go")
-	if err == nil {
-		// Probe with -h; exit code 0 or 2 (flag help) both indicate the tool exists.
-		cmd := exec.Command(goPath, "tool", name, "-h")
-		if err := cmd.Run(); err == nil {
-			return []string{goPath, "tool", name}
-		}
-		// Some tools exit non-zero for -h but still exist. Check the specific
-		// exit code — exec.ExitError with a code means the binary ran.
-		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() != 0 {
-			return []string{goPath, "tool", name}
-		}
-	}
-
-	// Fallback to PATH lookup.
-	toolPath, err := exec.LookPath(name)
-	if err == nil {
-		return []string{toolPath}
+	root := ""
+	if wd, err := os.Getwd(); err == nil {
+		root, _ = config.FindRepoRoot(wd)
 	}
-
-	return nil
+	return resolveIn(root, name)
 }
 
 // toolInfo holds install metadata for a known tool.
@@ -113,25 +138,37 @@ type toolInfo struct {
 	AltInstall string
 	// NoGoInstall is true if go get -tool / go install is not recommended.
 	NoGoInstall bool
+	// MinVersion is the oldest module version whose output Governor
+	// understands, if it matters.
+	MinVersion string
+	// VersionArgs print the version of a binary that was not built with
+	// module information, such as a release download.
+	VersionArgs []string
 }
 
 // knownTools maps tool binary names to their install metadata.
 var knownTools = map[string]toolInfo{
-	"gofumpt":       {ImportPath: "mvdan.cc/gofumpt@latest"},
-	"staticcheck":   {ImportPath: "honnef.co/go/tools/cmd/staticcheck@latest"},
+	"gofumpt":       {ImportPath: "mvdan.cc/gofumpt@latest", VersionArgs: []string{"--version"}},
+	"staticcheck":   {ImportPath: "honnef.co/go/tools/cmd/staticcheck@latest", MinVersion: "v0.4.0"},
 	"gocognit":      {ImportPath: "github.com/uudashr/gocognit/cmd/gocognit@latest"},
-	"deadcode":      {ImportPath: "golang.org/x/tools/cmd/deadcode@latest"},
+	"deadcode":      {ImportPath: "golang.org/x/tools/cmd/deadcode@latest", MinVersion: "v0.16.0"},
 	"dupl":          {ImportPath: "github.com/mibk/dupl@latest"},
-	"govulncheck":   {ImportPath: "golang.org/x/vuln/cmd/govulncheck@latest"},
-	"golangci-lint": {AltInstall: "https://golangci-lint.run/welcome/install/", NoGoInstall: true},
+	"govulncheck":   {ImportPath: "golang.org/x/vuln/cmd/govulncheck@latest", MinVersion: "v1.0.0"},
+	"golangci-lint": {AltInstall: "https://golangci-lint.run/welcome/install/", NoGoInstall: true, MinVersion: "v1.52.0", VersionArgs: []string{"--version"}},
 	"gopls":         {ImportPath: "golang.org/x/tools/gopls@latest"},
 }
 
-// ErrToolUnavailable is returned when a required tool is not installed.
+// ErrToolUnavailable is returned when a required tool is not installed,
+// or its version does not match the one pinned in the configuration.
 // It includes actionable install instructions when the tool is known.
 type ErrToolUnavailable struct {
 	Name string
 	Info *toolInfo
+
+	// Mismatch, if set, explains why the installed version was refused.
+	Mismatch string
+	// Pinned is the version constraint of the tool in the configuration.
+	Pinned string
 }
 
 func NewErrToolUnavailable(name string) ErrToolUnavailable {
@@ -144,7 +181,11 @@ func NewErrToolUnavailable(name string) ErrToolUnavailable {
 
 func (e ErrToolUnavailable) Error() string {
 	var b strings.Builder
-	fmt.Fprintf(&b, "%s is required but not installed.", e.Name)
+	if e.Mismatch != "" {
+		fmt.Fprintf(&b, "%s.", e.Mismatch)
+	} else {
+		fmt.Fprintf(&b, "%s is required but not installed.", e.Name)
+	}
 
 	if e.Info == nil {
 		return b.String()
@@ -152,6 +193,15 @@ func (e ErrToolUnavailable) Error() string {
 
 	fmt.Fprintln(&b)
 
+	// An exact pin says which version to install.
+	get, version := "", "@latest"
+	if op, v := splitConstraint(e.Pinned); op == "=" && !strings.Contains(e.Pinned, ",") {
+		if _, ok := parseVersion(v); ok {
+			get = "@v" + strings.TrimPrefix(v, "v")
+			version = get
+		}
+	}
+
 	if e.Info.NoGoInstall {
 		if e.Info.AltInstall != "" {
 			fmt.Fprintf(&b, "\nInstall: %s", e.Info.AltInstall)
@@ -160,13 +210,68 @@ func (e ErrToolUnavailable) Error() string {
 	} else if e.Info.ImportPath != "" {
 		importPath := strings.TrimSuffix(e.Info.ImportPath, "@latest")
 		fmt.Fprintf(&b, "\nInstall:")
-		fmt.Fprintf(&b, "\n  go get -tool %s   # adds to go.mod (recommended)", importPath)
-		fmt.Fprintf(&b, "\n  go install %s     # installs globally", e.Info.ImportPath)
+		fmt.Fprintf(&b, "\n  go get -tool %s%s   # adds to go.mod (recommended)", importPath, get)
+		fmt.Fprintf(&b, "\n  go install %s%s     # installs globally", importPath, version)
 	}
 
 	return b.String()
 }
 
+// ErrInterrupted is returned when a command was stopped before it
+// finished, because it timed out or the run was cancelled.
+type ErrInterrupted struct {
+	Command string
+	Status  runner.Status // runner.TimedOut or runner.Cancelled
+}
+
+func (e ErrInterrupted) Error() string {
+	if e.Status == runner.Cancelled {
+		return e.Command + " was cancelled"
+	}
+	return e.Command + " timed out (raise timeout in .governor, or use -timeout)"
+}
+
+// StepStatus returns the status of a step stopped by e.
+func (e ErrInterrupted) StepStatus() string {
+	if e.Status == runner.Cancelled {
+		return "cancelled"
+	}
+	return "timeout"
+}
+
+// run runs argv with the engine's runner, with the environment and limits
+// configured for the step running in ctx. A command that timed out or was
+// cancelled returns its partial result along with an ErrInterrupted.
+func (e *Engine) run(ctx context.Context, argv []string, cwd string, opts ...runner.Option) (*runner.Result, error) {
+	execOpts, err := e.execOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if prog := progressFrom(ctx); prog != nil {
+		execOpts = append(execOpts, runner.WithWait(prog.waiting))
+	}
+	opts, closeLog := e.withStepLog(ctx, argv, append(execOpts, opts...))
+	res, err := e.Runner.Run(ctx, argv, cwd, opts...)
+	closeLog()
+	if err != nil {
+		return nil, err
+	}
+	recordUsage(ctx, res.Usage)
+	if res.Status == runner.TimedOut || res.Status == runner.Cancelled {
+		return res, ErrInterrupted{Command: commandName(argv), Status: res.Status}
+	}
+	return res, nil
+}
+
+// commandName names the command run by argv in messages: the go
+// subcommand for go, the binary otherwise.
+func commandName(argv []string) string {
+	if len(argv) >= 2 && argv[0] == "go" {
+		return "go " + argv[1]
+	}
+	return argv[0]
+}
+
 // derivePackageFromFile extracts a package-like path from a file path.
 // This is best-effort; the caller may refine it with module info.
 func derivePackageFromFile(file string) string {
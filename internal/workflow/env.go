@@ -0,0 +1,109 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/runner"
+)
+
+type stepKey struct{}
+
+// withStep returns a context for the commands run by step, so that they
+// get the step's environment and limits.
+func withStep(ctx context.Context, step string) context.Context {
+	return context.WithValue(ctx, stepKey{}, step)
+}
+
+// stepFrom returns the step set by withStep, or "".
+func stepFrom(ctx context.Context) string {
+	step, _ := ctx.Value(stepKey{}).(string)
+	return step
+}
+
+// execOptions returns the runner options applying the configured
+// environment and limits of the step running in ctx.
+func (e *Engine) execOptions(ctx context.Context) ([]runner.Option, error) {
+	x := e.Config.StepExec(stepFrom(ctx))
+	var opts []runner.Option
+	if !x.Env.IsZero() {
+		env, err := environ(os.Environ(), x.Env, e.RepoRoot)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, runner.WithEnv(env))
+	}
+	if l := x.Limits; !l.IsZero() {
+		opts = append(opts, runner.WithLimits(runner.Limits{
+			CPU:       l.CPU(),
+			Memory:    l.Memory,
+			OpenFiles: l.OpenFiles,
+		}))
+	}
+	return opts, nil
+}
+
+// environ returns base, in the form "key=value", modified as described by
+// c. A relative GOTMPDIR is resolved against repoRoot and created.
+func environ(base []string, c config.EnvConfig, repoRoot string) ([]string, error) {
+	env := make([]string, 0, len(base)+len(c.Set)+3)
+	for _, kv := range base {
+		name, _, _ := strings.Cut(kv, "=")
+		if len(c.Allow) > 0 && !slices.ContainsFunc(c.Allow, func(p string) bool { return matchVar(p, name) }) {
+			continue
+		}
+		if slices.Contains(c.Unset, name) {
+			continue
+		}
+		env = append(env, kv)
+	}
+
+	set := func(name, value string) {
+		env = slices.DeleteFunc(env, func(kv string) bool { return strings.HasPrefix(kv, name+"=") })
+		env = append(env, name+"="+value)
+	}
+	names := make([]string, 0, len(c.Set))
+	for name := range c.Set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		set(name, c.Set[name])
+	}
+	if c.GOFLAGS != "" {
+		set("GOFLAGS", c.GOFLAGS)
+	}
+	if c.CgoEnabled != nil {
+		cgo := "0"
+		if *c.CgoEnabled {
+			cgo = "1"
+		}
+		set("CGO_ENABLED", cgo)
+	}
+	if c.GOTMPDIR != "" {
+		dir := c.GOTMPDIR
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(repoRoot, dir)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("creating GOTMPDIR: %w", err)
+		}
+		set("GOTMPDIR", dir)
+	}
+	return env, nil
+}
+
+// matchVar reports whether the variable name matches pattern, which is a
+// name or a prefix followed by "*".
+func matchVar(pattern, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(name, prefix)
+	}
+	return pattern == name
+}
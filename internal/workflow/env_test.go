@@ -0,0 +1,68 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/runner"
+)
+
+func TestEnviron(t *testing.T) {
+	root := t.TempDir()
+	cgo := false
+	base := []string{"PATH=/bin", "HOME=/home/me", "GOPATH=/go", "GOFLAGS=-v", "SECRET=x", "GOPROXY=off"}
+	env, err := environ(base, config.EnvConfig{
+		Allow:      []string{"PATH", "HOME", "GO*"},
+		Unset:      []string{"GOPROXY"},
+		Set:        map[string]string{"B": "2", "A": "1"},
+		GOFLAGS:    "-mod=mod",
+		CgoEnabled: &cgo,
+		GOTMPDIR:   ".cache/tmp",
+	}, root)
+	if err != nil {
+		t.Fatalf("environ: %v", err)
+	}
+	tmp := filepath.Join(root, ".cache", "tmp")
+	want := []string{"PATH=/bin", "HOME=/home/me", "GOPATH=/go", "A=1", "B=2", "GOFLAGS=-mod=mod", "CGO_ENABLED=0", "GOTMPDIR=" + tmp}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("environ =\n %q\nwant\n %q", env, want)
+	}
+	if fi, err := os.Stat(tmp); err != nil || !fi.IsDir() {
+		t.Errorf("GOTMPDIR not created: %v", err)
+	}
+}
+
+func TestCheck_StepExec(t *testing.T) {
+	fr := &fakeRunner{
+		Results: map[string]*runner.Result{
+			"go test": {ExitCode: 0, Stdout: passingTestJSON()},
+		},
+	}
+	cfg := &config.Config{Check: config.CheckConfig{Steps: []string{"test"}}}
+	cfg.Exec.Limits = config.LimitsConfig{RawCPU: "1m", OpenFiles: 256}
+	cfg.Test.Exec.Env.Set = map[string]string{"GOVERNOR_STEP": "test"}
+	cfg.Test.Exec.Limits.OpenFiles = 1024
+	e := &Engine{
+		Config:    cfg,
+		Runner:    fr,
+		Workspace: "/project",
+		RepoRoot:  "/project",
+	}
+
+	if _, err := e.Check(context.Background(), nil, false); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	o := fr.Options["go test"]
+	if !slices.Contains(o.Env, "GOVERNOR_STEP=test") {
+		t.Errorf("go test environment lacks the step's variables: %q", o.Env)
+	}
+	if want := (runner.Limits{CPU: time.Minute, OpenFiles: 1024}); o.Limits != want {
+		t.Errorf("go test limits = %+v, want %+v", o.Limits, want)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/deixis/governor/internal/report"
+	"github.com/deixis/governor/internal/runner"
 )
 
 // FixResult holds the outcome of the fix phase.
@@ -33,24 +34,24 @@ func (e *Engine) RunFixPhase(ctx context.Context, fix bool) (*FixResult, error)
 
 // runGofumptFix runs gofumpt -w . and returns the number of files modified.
 func (e *Engine) runGofumptFix(ctx context.Context) int {
-	argv := ResolveTool("gofumpt")
-	if argv == nil {
-		return 0 // gofumpt not available — skip silently in fix phase
+	argv, err := e.requireTool(ctx, "gofumpt")
+	if err != nil {
+		return 0 // gofumpt not available or not the pinned version — skip silently in fix phase
 	}
 	argv = append(argv, "-w", ".")
 
-	res, err := e.Runner.Run(ctx, argv, "")
+	res, err := e.run(ctx, argv, "", runner.WithExclusive())
 	if err != nil || res.ExitCode != 0 {
 		return 0
 	}
 
 	// gofumpt -w doesn't report what it changed. Count by running -l after.
-	lArgv := ResolveTool("gofumpt")
-	if lArgv == nil {
+	lArgv, err := e.requireTool(ctx, "gofumpt")
+	if err != nil {
 		return 0
 	}
 	lArgv = append(lArgv, "-l", ".")
-	lRes, err := e.Runner.Run(ctx, lArgv, "")
+	lRes, err := e.run(ctx, lArgv, "")
 	if err != nil {
 		return 0
 	}
@@ -61,13 +62,13 @@ func (e *Engine) runGofumptFix(ctx context.Context) int {
 
 // runGofumptCheck runs gofumpt -l . and returns unformatted files as FormatIssues.
 func (e *Engine) runGofumptCheck(ctx context.Context) []report.FormatIssue {
-	argv := ResolveTool("gofumpt")
-	if argv == nil {
+	argv, err := e.requireTool(ctx, "gofumpt")
+	if err != nil {
 		return nil
 	}
 	argv = append(argv, "-l", ".")
 
-	res, err := e.Runner.Run(ctx, argv, "")
+	res, err := e.run(ctx, argv, "")
 	if err != nil {
 		return nil
 	}
@@ -88,9 +89,9 @@ func (e *Engine) runGofumptCheck(ctx context.Context) []report.FormatIssue {
 
 // runLintFix runs golangci-lint run --fix and returns the count of fixes applied.
 func (e *Engine) runLintFix(ctx context.Context) int {
-	argv := ResolveTool("golangci-lint")
-	if argv == nil {
-		return 0 // not available — skip silently in fix phase
+	argv, err := e.requireTool(ctx, "golangci-lint")
+	if err != nil {
+		return 0 // not available or not the pinned version — skip silently in fix phase
 	}
 	argv = append(argv, "run", "--fix")
 	if e.Config.Lint.Config != "" {
@@ -99,7 +100,7 @@ func (e *Engine) runLintFix(ctx context.Context) int {
 	argv = append(argv, e.Config.Lint.Args...)
 	argv = append(argv, "./...")
 
-	res, err := e.Runner.Run(ctx, argv, "")
+	res, err := e.run(ctx, argv, "", runner.WithExclusive())
 	if err != nil || res == nil {
 		return 0
 	}
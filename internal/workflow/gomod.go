@@ -0,0 +1,92 @@
+package workflow
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// goMod holds the directives of a go.mod file that Governor uses.
+type goMod struct {
+	Module    string
+	Go        string
+	Toolchain string
+	Tools     []string // import paths of tool directives
+}
+
+// readGoMod reads the go.mod file at path. It only understands the
+// directives of goMod, and ignores the rest.
+func readGoMod(path string) (*goMod, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &goMod{}
+	block := "" // directive of the block being read, as in "tool ("
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+			} else if block == "tool" {
+				m.Tools = append(m.Tools, unquote(fields[0]))
+			}
+			continue
+		}
+		if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			m.Module = unquote(fields[1])
+		case "go":
+			m.Go = fields[1]
+		case "toolchain":
+			m.Toolchain = fields[1]
+		case "tool":
+			m.Tools = append(m.Tools, unquote(fields[1]))
+		}
+	}
+	return m, sc.Err()
+}
+
+// HasTool reports whether a tool directive declares the tool that
+// "go tool <name>" runs.
+func (m *goMod) HasTool(name string) bool {
+	for _, p := range m.Tools {
+		if toolName(p) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// majorSuffix matches the major version element of an import path.
+var majorSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// toolName returns the name "go tool" knows the tool with import path p
+// by: its last element, skipping a major version suffix.
+func toolName(p string) string {
+	name := path.Base(p)
+	if majorSuffix.MatchString(name) && path.Dir(p) != "." {
+		name = path.Base(path.Dir(p))
+	}
+	return name
+}
+
+func unquote(s string) string {
+	return strings.Trim(s, "\"`")
+}
@@ -0,0 +1,50 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadGoMod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go.mod")
+	gomod := `module "example.com/foo" // the module
+
+go 1.25.1
+
+toolchain go1.25.4
+
+require (
+	golang.org/x/tools v0.30.0
+	honnef.co/go/tools v0.6.1 // indirect
+)
+
+tool honnef.co/go/tools/cmd/staticcheck
+
+tool (
+	// Formatters.
+	mvdan.cc/gofumpt
+	example.com/lint/v2
+)
+`
+	if err := os.WriteFile(path, []byte(gomod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := readGoMod(path)
+	if err != nil {
+		t.Fatalf("readGoMod: %v", err)
+	}
+	if m.Module != "example.com/foo" || m.Go != "1.25.1" || m.Toolchain != "go1.25.4" {
+		t.Errorf("module = %q, go = %q, toolchain = %q", m.Module, m.Go, m.Toolchain)
+	}
+	want := []string{"honnef.co/go/tools/cmd/staticcheck", "mvdan.cc/gofumpt", "example.com/lint/v2"}
+	if !reflect.DeepEqual(m.Tools, want) {
+		t.Errorf("tools = %q, want %q", m.Tools, want)
+	}
+	for name, has := range map[string]bool{"staticcheck": true, "gofumpt": true, "lint": true, "v2": false, "tools": false} {
+		if m.HasTool(name) != has {
+			t.Errorf("HasTool(%q) = %v, want %v", name, !has, has)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/deixis/governor/internal/report"
+)
+
+// FormatInspect formats the diagnostics for symbol in a stored run,
+// grouped by file, followed by the full output of any failed tests.
+func FormatInspect(rr *report.RunResult, symbol string, diagnostics []report.Diagnostic) string {
+	var b strings.Builder
+
+	writeRunHeader(&b, rr)
+	fmt.Fprintln(&b)
+	writeUsage(&b, rr)
+
+	// Symbol header.
+	if len(diagnostics) == 1 && diagnostics[0].Source == "test" {
+		fmt.Fprintf(&b, "%s — FAIL\n", symbol)
+	} else {
+		// Group by source for the header.
+		sources := make(map[string]int)
+		for _, d := range diagnostics {
+			sources[d.Source]++
+		}
+		var parts []string
+		for source, count := range sources {
+			parts = append(parts, fmt.Sprintf("%d %s", count, source))
+		}
+		fmt.Fprintf(&b, "%s — %s:\n", symbol, strings.Join(parts, ", "))
+	}
+	fmt.Fprintln(&b)
+
+	// Group by file.
+	type fileGroup struct {
+		file        string
+		diagnostics []report.Diagnostic
+	}
+	var groups []fileGroup
+	seen := make(map[string]int)
+	for _, d := range diagnostics {
+		file := d.File
+		if file == "" {
+			file = "(unknown)"
+		}
+		if idx, ok := seen[file]; ok {
+			groups[idx].diagnostics = append(groups[idx].diagnostics, d)
+		} else {
+			seen[file] = len(groups)
+			groups = append(groups, fileGroup{file: file, diagnostics: []report.Diagnostic{d}})
+		}
+	}
+
+	for _, g := range groups {
+		for _, d := range g.diagnostics {
+			if d.Line > 0 {
+				if d.Col > 0 {
+					fmt.Fprintf(&b, "%s:%d:%d: ", d.File, d.Line, d.Col)
+				} else {
+					fmt.Fprintf(&b, "%s:%d: ", d.File, d.Line)
+				}
+			} else if d.File != "" && d.File != "(unknown)" {
+				fmt.Fprintf(&b, "%s: ", d.File)
+			}
+
+			// Source/detail tag.
+			tag := d.Source
+			if d.Detail != "" {
+				tag = d.Source + "/" + d.Detail
+			}
+			fmt.Fprintf(&b, "[%s] %s\n", tag, d.Message)
+		}
+	}
+
+	// For test failures, include full output.
+	for _, d := range diagnostics {
+		if d.Source == "test" && d.Output != "" {
+			fmt.Fprintln(&b)
+			fmt.Fprintln(&b, "Output:")
+			for _, line := range strings.Split(strings.TrimRight(d.Output, "\n"), "\n") {
+				fmt.Fprintf(&b, "    %s\n", line)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// FormatRun formats a stored run: its metadata followed by a summary of
+// its findings, in the same shape as the live check and audit output.
+func FormatRun(rr *report.RunResult) string {
+	var b strings.Builder
+
+	writeRunHeader(&b, rr)
+	if len(rr.Packages) > 0 {
+		fmt.Fprintf(&b, "Packages: %s\n", strings.Join(rr.Packages, " "))
+	}
+	if n := len(rr.ResolvedPackages); n > 0 {
+		fmt.Fprintf(&b, "Resolved: %d packages\n", n)
+	}
+	for _, t := range rr.Tools {
+		fmt.Fprintf(&b, "Tool: %s %s\n", t.Name, t.Path)
+	}
+	fmt.Fprintln(&b)
+
+	if len(rr.Steps) > 0 {
+		fmt.Fprintln(&b, "Steps:")
+		for _, st := range rr.Steps {
+			fmt.Fprintf(&b, "  %-15s %-12s %s\n", st.Name, st.Status, formatElapsed(st.Elapsed))
+		}
+		fmt.Fprintln(&b)
+	}
+	writeUsage(&b, rr)
+
+	switch rr.Kind {
+	case report.Check:
+		if rr.AutoFixes > 0 {
+			fmt.Fprintf(&b, "Auto-fixed: %d issues\n\n", rr.AutoFixes)
+		}
+		if len(rr.FormatIssues) > 0 {
+			fmt.Fprintf(&b, "Formatting issues (%d files):\n", len(rr.FormatIssues))
+			for _, f := range rr.FormatIssues {
+				fmt.Fprintf(&b, "  %s\n", f.File)
+			}
+			fmt.Fprintln(&b)
+		}
+		failures := FormatFailureSymbols(rr)
+		if len(failures) > 0 {
+			fmt.Fprintln(&b, "Failures:")
+			for _, f := range failures {
+				fmt.Fprintf(&b, "  %s\n", f)
+			}
+			fmt.Fprintln(&b)
+		} else if len(rr.FormatIssues) == 0 {
+			fmt.Fprintln(&b, "No failures recorded.")
+		}
+
+	case report.Audit:
+		sections := []struct {
+			name    string
+			present bool
+			summary func() string
+		}{
+			{"coverage", len(rr.Coverage) > 0, func() string { return FormatCoverageSummary(rr.Coverage) }},
+			{"complexity", len(rr.Complexity) > 0, func() string { return FormatComplexitySummary(rr.Complexity) }},
+			{"deadcode", len(rr.DeadFuncs) > 0, func() string { return FormatDeadcodeSummary(rr.DeadFuncs) }},
+			{"dupl", len(rr.Duplicates) > 0, func() string { return FormatDuplSummary(rr.Duplicates) }},
+			{"vulncheck", len(rr.Vulns) > 0, func() string { return FormatVulncheckSummary(rr.Vulns) }},
+		}
+		printed := false
+		for _, sec := range sections {
+			if !sec.present {
+				continue
+			}
+			fmt.Fprintf(&b, "%s:\n%s\n", sec.name, sec.summary())
+			printed = true
+		}
+		if !printed {
+			fmt.Fprintln(&b, "No findings recorded.")
+		}
+	}
+
+	return b.String()
+}
+
+// writeRunHeader writes the identifying metadata of a run: ID and kind,
+// timing, status, commit, Go and tool versions, and config hash.
+func writeRunHeader(b *strings.Builder, rr *report.RunResult) {
+	fmt.Fprintf(b, "Run: %s (%s)\n", rr.ID, rr.Kind)
+	if !rr.Started.IsZero() {
+		fmt.Fprintf(b, "Started: %s", rr.Started.Local().Format("2006-01-02 15:04:05"))
+		if d := rr.Duration(); d > 0 {
+			fmt.Fprintf(b, " (took %s)", formatElapsed(d.Seconds()))
+		}
+		fmt.Fprintln(b)
+	}
+	if rr.Status != "" {
+		fmt.Fprintf(b, "Status: %s\n", rr.Status)
+	}
+	if rr.Commit != "" {
+		fmt.Fprintf(b, "Commit: %s", rr.Commit)
+		if rr.Dirty {
+			fmt.Fprint(b, " (dirty)")
+		}
+		fmt.Fprintln(b)
+	}
+	if rr.GoVersion != "" {
+		fmt.Fprintf(b, "Go: %s\n", rr.GoVersion)
+	}
+	if len(rr.Tools) > 0 {
+		tools := make([]string, len(rr.Tools))
+		for i, t := range rr.Tools {
+			tools[i] = t.Name
+			if t.Version != "" {
+				tools[i] += " " + t.Version
+			}
+			if t.Constraint != "" && t.Constraint != t.Version {
+				tools[i] += " (pinned " + t.Constraint + ")"
+			}
+		}
+		fmt.Fprintf(b, "Tools: %s\n", strings.Join(tools, ", "))
+	}
+	if rr.ConfigHash != "" {
+		fmt.Fprintf(b, "Config: %s", rr.ConfigHash)
+		if rr.Profile != "" {
+			fmt.Fprintf(b, " (profile %s)", rr.Profile)
+		}
+		fmt.Fprintln(b)
+	}
+}
+
+// writeUsage writes the resources used by the steps of rr, if recorded.
+func writeUsage(b *strings.Builder, rr *report.RunResult) {
+	if u := FormatUsage(rr); u != "" {
+		fmt.Fprintf(b, "Resources:\n%s\n", u)
+	}
+}
+
+// formatElapsed formats a duration in seconds for display.
+func formatElapsed(seconds float64) string {
+	d := time.Duration(seconds * float64(time.Second))
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
@@ -39,9 +39,9 @@ func (s *LintSummary) String() string {
 }
 
 func (e *Engine) runLint(ctx context.Context, packages []string) (*LintSummary, error) {
-	argv := ResolveTool("golangci-lint")
-	if argv == nil {
-		return nil, NewErrToolUnavailable("golangci-lint")
+	argv, err := e.requireTool(ctx, "golangci-lint")
+	if err != nil {
+		return nil, err
 	}
 
 	pkgs := e.ResolvePackages(packages)
@@ -53,7 +53,7 @@ func (e *Engine) runLint(ctx context.Context, packages []string) (*LintSummary,
 	argv = append(argv, e.Config.Lint.Args...)
 	argv = append(argv, pkgs...)
 
-	result, err := e.Runner.Run(ctx, argv, "")
+	result, err := e.run(ctx, argv, "")
 	if err != nil {
 		return nil, fmt.Errorf("executing golangci-lint: %w", err)
 	}
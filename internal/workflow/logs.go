@@ -0,0 +1,82 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deixis/governor/internal/report"
+	"github.com/deixis/governor/internal/runner"
+)
+
+// openStepLog opens the log of the step running in ctx for appending and
+// writes a header naming the command about to run. It returns nil when
+// there is no log to write: the engine has no LogDir, or ctx is not in a
+// step of a run. Failing to open the log does not fail the step.
+func (e *Engine) openStepLog(ctx context.Context, argv []string) *os.File {
+	rs, step := stateFrom(ctx), stepFrom(ctx)
+	if e.LogDir == "" || rs == nil || rs.id == "" || step == "" {
+		return nil
+	}
+	path := report.LogPath(e.LogDir, rs.id, step)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Printf("warning: creating log directory: %v", err)
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		log.Printf("warning: opening step log: %v", err)
+		return nil
+	}
+	fmt.Fprintf(f, "$ %s\n", strings.Join(argv, " "))
+
+	rs.mu.Lock()
+	rs.logs[step] = path
+	rs.mu.Unlock()
+	return f
+}
+
+// withStepLog returns opts with the log of the step running in ctx added,
+// and a function closing it once the command has run.
+func (e *Engine) withStepLog(ctx context.Context, argv []string, opts []runner.Option) ([]runner.Option, func()) {
+	f := e.openStepLog(ctx, argv)
+	if f == nil {
+		return opts, func() {}
+	}
+	return append(opts, runner.WithLog(f)), func() { _ = f.Close() }
+}
+
+// stepLog returns the path of the log written by step in the run carried
+// by ctx, or "" if it has none.
+func stepLog(ctx context.Context, step string) string {
+	rs := stateFrom(ctx)
+	if rs == nil {
+		return ""
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.logs[step]
+}
+
+// DefaultLogLines is how many lines of a step log are shown at once unless
+// asked otherwise.
+const DefaultLogLines = 200
+
+// FormatLogPage formats a page of the log of step, one numbered line per
+// log line, after a header giving the range shown.
+func FormatLogPage(step string, p *report.LogPage) string {
+	var b strings.Builder
+	if len(p.Lines) == 0 {
+		fmt.Fprintf(&b, "Log: %s, no lines from %d (%d lines)\n", step, p.From, p.Total)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Log: %s, lines %d-%d of %d\n", step, p.From, p.To(), p.Total)
+	width := len(fmt.Sprint(p.To()))
+	for i, line := range p.Lines {
+		fmt.Fprintf(&b, "%*d  %s\n", width, p.From+i, line)
+	}
+	return b.String()
+}
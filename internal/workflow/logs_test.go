@@ -0,0 +1,76 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/report"
+	"github.com/deixis/governor/internal/runner"
+)
+
+func TestCheck_StepLogs(t *testing.T) {
+	fr := &fakeRunner{
+		Results: map[string]*runner.Result{
+			"go test": {ExitCode: 1, Stdout: failingTestJSON()},
+		},
+	}
+	logDir := t.TempDir()
+	e := &Engine{
+		Config:    &config.Config{Check: config.CheckConfig{Steps: []string{"test"}}},
+		Runner:    fr,
+		Workspace: "/project",
+		RepoRoot:  "/project",
+		LogDir:    logDir,
+	}
+
+	result, err := e.Check(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	rr := result.RunResult
+
+	path, err := rr.StepLog("test")
+	if err != nil {
+		t.Fatalf("StepLog: %v", err)
+	}
+	if want := report.LogPath(logDir, rr.ID, "test"); path != want {
+		t.Errorf("test log = %s, want %s", path, want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	if !strings.HasPrefix(log, "$ go test -json") || !strings.Contains(log, string(failingTestJSON())) {
+		t.Errorf("test log = %q, want the command and its complete output", log)
+	}
+}
+
+func TestCheck_NoLogDir(t *testing.T) {
+	e := &Engine{
+		Config:    &config.Config{Check: config.CheckConfig{Steps: []string{"test"}}},
+		Runner:    &fakeRunner{},
+		Workspace: "/project",
+		RepoRoot:  "/project",
+	}
+	result, err := e.Check(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	for _, s := range result.RunResult.Steps {
+		if s.Log != "" {
+			t.Errorf("step %s has log %s without a log directory", s.Name, s.Log)
+		}
+	}
+}
+
+func TestFormatLogPage(t *testing.T) {
+	got := FormatLogPage("test", &report.LogPage{Lines: []string{"a", "b"}, From: 9, Total: 12})
+	want := "Log: test, lines 9-10 of 12\n 9  a\n10  b\n"
+	if got != want {
+		t.Errorf("FormatLogPage = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,320 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deixis/governor/internal/report"
+	"github.com/deixis/governor/internal/runner"
+	"github.com/google/uuid"
+)
+
+// runState accumulates metadata while a run is in progress. It travels in
+// the context so that steps can record what they used without threading
+// extra parameters through every step function.
+type runState struct {
+	mu       sync.Mutex
+	id       string
+	tools    []report.ToolRecord
+	seen     map[string]bool
+	logs     map[string]string        // step → log path
+	usage    map[string]*report.Usage // step → resources used by its commands
+	progress *progress                // nil unless the caller observes progress
+}
+
+type runStateKey struct{}
+
+func stateFrom(ctx context.Context) *runState {
+	rs, _ := ctx.Value(runStateKey{}).(*runState)
+	return rs
+}
+
+// newRun creates a RunResult populated with the metadata known before any
+// step runs, and returns a context carrying the state for the run.
+func (e *Engine) newRun(ctx context.Context, kind report.Kind, pkgs []string) (*report.RunResult, context.Context) {
+	rs := &runState{
+		seen:  make(map[string]bool),
+		logs:  make(map[string]string),
+		usage: make(map[string]*report.Usage),
+	}
+	ctx = context.WithValue(ctx, runStateKey{}, rs)
+
+	rr := &report.RunResult{
+		ID:         uuid.New().String(),
+		Kind:       kind,
+		Started:    time.Now(),
+		Root:       e.RepoRoot,
+		Module:     modulePath(e.RepoRoot),
+		Packages:   pkgs,
+		ConfigHash: e.Config.Hash(),
+		Profile:    e.Profile,
+	}
+	rs.id = rr.ID
+	rs.progress = newProgress(ctx, rr.ID, rr.Started)
+	rr.Commit, rr.Dirty = e.gitState(ctx)
+	rr.GoVersion = e.goEnv(ctx, "GOVERSION")
+	rr.ResolvedPackages = e.listPackages(ctx, pkgs)
+	rs.progress.resolved(len(rr.ResolvedPackages))
+	return rr, ctx
+}
+
+// runMeta runs a command gathering run metadata. Unlike run, it applies
+// no step settings and keeps no log, but waits are still reported.
+func (e *Engine) runMeta(ctx context.Context, argv []string) (*runner.Result, error) {
+	var opts []runner.Option
+	if prog := progressFrom(ctx); prog != nil {
+		opts = append(opts, runner.WithWait(prog.waiting))
+	}
+	return e.Runner.Run(ctx, argv, "", opts...)
+}
+
+// finishRun records the end of the run and the tools it used.
+func (e *Engine) finishRun(ctx context.Context, rr *report.RunResult) {
+	rr.Finished = time.Now()
+	if rs := stateFrom(ctx); rs != nil {
+		rs.mu.Lock()
+		rr.Tools = append([]report.ToolRecord(nil), rs.tools...)
+		rs.mu.Unlock()
+	}
+}
+
+// resolveTool resolves a tool like ResolveTool and records the binary, its
+// version and the version pinned in the configuration in the run metadata.
+func (e *Engine) resolveTool(ctx context.Context, name string) []string {
+	var argv []string
+	if r, ok := e.Runner.(ToolResolver); ok {
+		argv = r.ResolveTool(name)
+	} else if e.Tools != nil {
+		argv = e.Tools.Resolve(e.repoRoot(), name)
+	} else {
+		argv = resolveIn(e.repoRoot(), name)
+	}
+	if argv == nil {
+		return nil
+	}
+
+	rs := stateFrom(ctx)
+	if rs == nil {
+		return argv
+	}
+	rs.mu.Lock()
+	seen := rs.seen[name]
+	rs.seen[name] = true
+	rs.mu.Unlock()
+	if seen {
+		return argv
+	}
+
+	rec := e.describeTool(ctx, name, argv)
+	rs.mu.Lock()
+	rs.tools = append(rs.tools, rec)
+	rs.mu.Unlock()
+	return argv
+}
+
+// requireTool resolves a tool a step cannot run without. It returns an
+// ErrToolUnavailable if the tool is not installed or its version does not
+// match the one pinned in the configuration.
+func (e *Engine) requireTool(ctx context.Context, name string) ([]string, error) {
+	argv := e.resolveTool(ctx, name)
+	if argv == nil {
+		return nil, NewErrToolUnavailable(name)
+	}
+	if e.Config.Tools[name] == "" {
+		return argv, nil
+	}
+	if err := checkPin(e.toolRecord(ctx, name, argv)); err != nil {
+		return nil, err
+	}
+	return argv, nil
+}
+
+// toolRecord returns the description of a resolved tool, from the run
+// metadata when the run already recorded it.
+func (e *Engine) toolRecord(ctx context.Context, name string, argv []string) report.ToolRecord {
+	if rs := stateFrom(ctx); rs != nil {
+		rs.mu.Lock()
+		defer rs.mu.Unlock()
+		for _, rec := range rs.tools {
+			if rec.Name == name {
+				return rec
+			}
+		}
+	}
+	return e.describeTool(ctx, name, argv)
+}
+
+// checkPin returns an ErrToolUnavailable if the version of rec does not
+// satisfy its constraint.
+func checkPin(rec report.ToolRecord) error {
+	if rec.Constraint == "" {
+		return nil
+	}
+	err := NewErrToolUnavailable(rec.Name)
+	err.Pinned = rec.Constraint
+	if rec.Version == "" {
+		err.Mismatch = fmt.Sprintf("the version of %s (%s) cannot be determined to check it against %q, pinned in .governor", rec.Name, rec.Path, rec.Constraint)
+		return err
+	}
+	ok, perr := matchVersion(rec.Version, rec.Constraint)
+	switch {
+	case perr != nil:
+		err.Mismatch = fmt.Sprintf("tools.%s in .governor: %v", rec.Name, perr)
+	case !ok:
+		err.Mismatch = fmt.Sprintf("%s %s (%s) does not match %q, pinned in .governor", rec.Name, rec.Version, rec.Path, rec.Constraint)
+	default:
+		return nil
+	}
+	return err
+}
+
+// toolVersions caches the version of each tool binary, keyed by
+// toolBinary, so that it is detected once per process.
+var toolVersions sync.Map
+
+// toolBinary identifies a binary file, so that a tool that is reinstalled
+// is detected again.
+type toolBinary struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// describeTool determines the binary path and module version of a resolved
+// tool. Tools run through "go tool" are located with "go tool -n".
+func (e *Engine) describeTool(ctx context.Context, name string, argv []string) report.ToolRecord {
+	rec := report.ToolRecord{Name: name, Path: argv[0], Constraint: e.Config.Tools[name]}
+
+	binary := argv[0]
+	if len(argv) > 1 && argv[1] == "tool" {
+		rec.Path = "go tool " + name
+		res, err := e.runMeta(ctx, []string{argv[0], "tool", "-n", name})
+		if err != nil || res.ExitCode != 0 {
+			return rec
+		}
+		binary = strings.TrimSpace(string(res.Stdout))
+	}
+	if binary == "" || !filepath.IsAbs(binary) {
+		return rec
+	}
+
+	var key toolBinary
+	if fi, err := os.Stat(binary); err == nil {
+		key = toolBinary{path: binary, size: fi.Size(), modTime: fi.ModTime()}
+		if v, ok := toolVersions.Load(key); ok {
+			rec.Version = v.(string)
+			return rec
+		}
+	}
+	rec.Version = e.detectVersion(ctx, name, binary)
+	if key.path != "" && rec.Version != "" {
+		toolVersions.Store(key, rec.Version)
+	}
+	return rec
+}
+
+// detectVersion returns the module version of a tool binary from its build
+// information, or else from the output of its version flag.
+func (e *Engine) detectVersion(ctx context.Context, name, binary string) string {
+	res, err := e.runMeta(ctx, []string{"go", "version", "-m", binary})
+	if err == nil && res.ExitCode == 0 {
+		if v := parseModVersion(res.Stdout); v != "" && v != "(devel)" {
+			return v
+		}
+	}
+	args := knownTools[name].VersionArgs
+	if len(args) == 0 {
+		return ""
+	}
+	res, err = e.runMeta(ctx, append([]string{binary}, args...))
+	if err != nil || res.ExitCode != 0 {
+		return ""
+	}
+	if m := versionOutput.FindStringSubmatch(string(res.Stdout)); m != nil {
+		return "v" + m[1]
+	}
+	return ""
+}
+
+// versionOutput matches the version printed by a tool's version flag, as in
+// "golangci-lint has version 1.64.8 built with go1.24.1".
+var versionOutput = regexp.MustCompile(`\bv?(\d+\.\d+\.\d+)\b`)
+
+// parseModVersion extracts the main module version from `go version -m`
+// output, whose relevant line looks like:
+//
+//	mod	honnef.co/go/tools	v0.6.1	h1:...
+func parseModVersion(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "mod" {
+			return fields[2]
+		}
+	}
+	return ""
+}
+
+// gitState returns the commit hash of HEAD and whether the worktree has
+// uncommitted changes. Both are empty when the workspace is not a git
+// checkout or git is unavailable.
+func (e *Engine) gitState(ctx context.Context) (string, bool) {
+	res, err := e.runMeta(ctx, []string{"git", "rev-parse", "HEAD"})
+	if err != nil || res.ExitCode != 0 {
+		return "", false
+	}
+	head := strings.TrimSpace(string(res.Stdout))
+
+	res, err = e.runMeta(ctx, []string{"git", "status", "--porcelain"})
+	if err != nil || res.ExitCode != 0 {
+		return head, false
+	}
+	return head, strings.TrimSpace(string(res.Stdout)) != ""
+}
+
+// modulePath returns the module path declared in the go.mod file at root,
+// or "" if there is none.
+func modulePath(root string) string {
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// goEnv returns the value of a go env variable, or "" on failure.
+func (e *Engine) goEnv(ctx context.Context, name string) string {
+	res, err := e.runMeta(ctx, []string{"go", "env", name})
+	if err != nil || res.ExitCode != 0 {
+		return ""
+	}
+	return strings.TrimSpace(string(res.Stdout))
+}
+
+// listPackages expands package patterns to import paths with `go list`.
+// It returns nil if the patterns cannot be listed.
+func (e *Engine) listPackages(ctx context.Context, pkgs []string) []string {
+	argv := append([]string{"go", "list", "-e"}, pkgs...)
+	res, err := e.runMeta(ctx, argv)
+	if err != nil || res.ExitCode != 0 {
+		return nil
+	}
+	var out []string
+	for _, line := range strings.Split(string(res.Stdout), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
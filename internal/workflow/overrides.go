@@ -0,0 +1,237 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/report"
+)
+
+// packageGroup is a set of packages that run with the same settings.
+type packageGroup struct {
+	config   *config.Config
+	packages []string        // arguments naming the packages
+	dirs     map[string]bool // directories relative to the module root; nil for every package of the run
+	imports  map[string]bool // import paths; nil for every package of the run
+}
+
+// packageGroups partitions the packages of rr by the overrides that apply
+// to them, in the order of their first package. Without overrides, or when
+// the packages could not be listed, there is one group with the top-level
+// settings, naming the packages by pkgs.
+func (e *Engine) packageGroups(rr *report.RunResult, pkgs []string) []packageGroup {
+	all := []packageGroup{{config: e.Config, packages: pkgs}}
+	if len(e.Config.Overrides) == 0 || len(rr.ResolvedPackages) == 0 {
+		return all
+	}
+
+	var groups []packageGroup
+	index := make(map[string]int) // by the overrides applied
+	for _, imp := range rr.ResolvedPackages {
+		cfg, arg, dir := e.Config, imp, ""
+		var matched []int
+		if rel, ok := moduleDir(rr.Module, imp); ok {
+			cfg, matched = e.Config.ForPackage(rel)
+			dir, arg = rel, "./"+rel
+			if rel == "." {
+				arg = "."
+			}
+		}
+		key := fmt.Sprint(matched)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, packageGroup{config: cfg, dirs: make(map[string]bool), imports: make(map[string]bool)})
+		}
+		g := &groups[i]
+		g.packages = append(g.packages, arg)
+		g.imports[imp] = true
+		if dir != "" {
+			g.dirs[dir] = true
+		}
+	}
+	if len(groups) == 1 {
+		all[0].config = groups[0].config
+		return all
+	}
+	return groups
+}
+
+// moduleDir returns the directory of the package with import path imp
+// relative to the root of module, or false if it is not in the module.
+func moduleDir(module, imp string) (string, bool) {
+	if module == "" {
+		return "", false
+	}
+	if imp == module {
+		return ".", true
+	}
+	return strings.CutPrefix(imp, module+"/")
+}
+
+// filePackage returns the import path of the package of module holding
+// file, relative to the module root at root or absolute, or "" if module
+// is unknown or file is outside root.
+func filePackage(module, root, file string) string {
+	if module == "" {
+		return ""
+	}
+	if filepath.IsAbs(file) {
+		rel, err := filepath.Rel(root, file)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return ""
+		}
+		file = rel
+	}
+	dir := path.Dir(strings.TrimPrefix(filepath.ToSlash(file), "./"))
+	if dir == "." {
+		return module
+	}
+	return module + "/" + dir
+}
+
+// hasFile reports whether file, relative to the module root or absolute,
+// belongs to a package of g.
+func (g packageGroup) hasFile(root, file string) bool {
+	if g.dirs == nil {
+		return true
+	}
+	if filepath.IsAbs(file) {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return false
+		}
+		file = rel
+	}
+	return g.dirs[path.Dir(strings.TrimPrefix(filepath.ToSlash(file), "./"))]
+}
+
+// hasPackage reports whether the package with import path imp is in g.
+func (g packageGroup) hasPackage(imp string) bool {
+	return g.imports == nil || g.imports[imp]
+}
+
+// groupSteps returns the steps that the settings of the groups list by
+// steps: those of the first group, then those of the others in the order
+// they list them.
+func groupSteps(groups []packageGroup, steps func(*config.Config) []string) []string {
+	var all []string
+	for _, g := range groups {
+		for _, step := range steps(g.config) {
+			if !slices.Contains(all, step) {
+				all = append(all, step)
+			}
+		}
+	}
+	return all
+}
+
+// runsStep reports whether step is among the check or audit steps of cfg.
+func runsStep(cfg *config.Config, step string) bool {
+	if slices.Contains(config.DefaultAuditSteps, step) {
+		return slices.Contains(cfg.AuditSteps(), step)
+	}
+	return slices.Contains(cfg.CheckSteps(), step)
+}
+
+// forGroups runs step for each group whose settings include it, with an
+// engine using the group's settings, and returns the results. It stops at
+// the first error, returning it with the results so far, including that of
+// the failing group.
+func forGroups[T any](ctx context.Context, e *Engine, groups []packageGroup, step string, run func(*Engine, context.Context, packageGroup) (T, error)) ([]T, error) {
+	var results []T
+	for _, g := range groups {
+		if !runsStep(g.config, step) {
+			continue
+		}
+		ge := *e
+		ge.Config = g.config
+		res, err := run(&ge, ctx, g)
+		results = append(results, res)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// mergeTestSummaries combines the summaries of go test runs on distinct
+// packages.
+func mergeTestSummaries(summaries []*TestSummary) *TestSummary {
+	summaries = slices.DeleteFunc(summaries, func(s *TestSummary) bool { return s == nil })
+	switch len(summaries) {
+	case 0:
+		return nil
+	case 1:
+		return summaries[0]
+	}
+	m := &TestSummary{Status: "PASS"}
+	for _, s := range summaries {
+		if s.Status != "PASS" {
+			m.Status = s.Status
+		}
+		m.Total += s.Total
+		m.Passed += s.Passed
+		m.Failed += s.Failed
+		m.Skipped += s.Skipped
+		m.BuildErrors = append(m.BuildErrors, s.BuildErrors...)
+		m.Errors = append(m.Errors, s.Errors...)
+		m.Packages = append(m.Packages, s.Packages...)
+	}
+	return m
+}
+
+// mergeLintSummaries combines the summaries of golangci-lint runs.
+func mergeLintSummaries(summaries []*LintSummary) *LintSummary {
+	m := &LintSummary{}
+	for _, s := range summaries {
+		if s != nil {
+			m.Issues = append(m.Issues, s.Issues...)
+		}
+	}
+	return m
+}
+
+// mergeStaticcheckResults combines the results of staticcheck runs.
+func mergeStaticcheckResults(results []*StaticcheckResult) *StaticcheckResult {
+	m := &StaticcheckResult{}
+	for _, r := range results {
+		if r != nil {
+			m.Issues = append(m.Issues, r.Issues...)
+		}
+	}
+	return m
+}
+
+// concatGroups concatenates the findings of groups.
+func concatGroups[T any](lists [][]T) []T {
+	if len(lists) == 1 {
+		return lists[0]
+	}
+	return slices.Concat(lists...)
+}
+
+// mergeUnique concatenates the findings of groups, leaving out those
+// already found by an earlier group.
+func mergeUnique[T any](lists [][]T, key func(T) string) []T {
+	if len(lists) == 1 {
+		return lists[0]
+	}
+	var merged []T
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, v := range list {
+			if k := key(v); !seen[k] {
+				seen[k] = true
+				merged = append(merged, v)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,174 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/runner"
+)
+
+// loggingRunner is a resolvingRunner that records every command it runs.
+type loggingRunner struct {
+	resolvingRunner
+	Calls [][]string
+}
+
+func (r *loggingRunner) Run(ctx context.Context, argv []string, cwd string, opts ...runner.Option) (*runner.Result, error) {
+	r.Calls = append(r.Calls, argv)
+	return r.resolvingRunner.Run(ctx, argv, cwd, opts...)
+}
+
+// commands returns the recorded commands of a tool, joined by spaces.
+func (r *loggingRunner) commands(key string) []string {
+	var out []string
+	for _, argv := range r.Calls {
+		if fakeRunnerKey(argv) == key {
+			out = append(out, strings.Join(argv, " "))
+		}
+	}
+	return out
+}
+
+// overridesEngine returns an engine for the module example.com/m with
+// packages at its root, in internal/pb/a and in old.
+func overridesEngine(t *testing.T, cfg *config.Config, results map[string]*runner.Result) (*Engine, *loggingRunner) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/m\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	results["go list"] = &runner.Result{Stdout: []byte("example.com/m\nexample.com/m/internal/pb/a\nexample.com/m/old\n")}
+	fr := &loggingRunner{resolvingRunner: resolvingRunner{
+		fakeRunner: fakeRunner{Results: results},
+		Tools: map[string][]string{
+			"staticcheck": {"staticcheck"},
+			"gocognit":    {"gocognit"},
+			"dupl":        {"dupl"},
+		},
+	}}
+	return &Engine{Config: cfg, Runner: fr, Workspace: dir, RepoRoot: dir}, fr
+}
+
+func TestCheck_Overrides(t *testing.T) {
+	cfg := &config.Config{
+		Check:       config.CheckConfig{Steps: []string{"test", "staticcheck"}},
+		Staticcheck: config.StaticcheckConfig{Checks: []string{"all"}},
+		Overrides: []config.Override{
+			{Packages: []string{"internal/pb/..."}, Check: config.CheckConfig{Steps: []string{"test"}}},
+			{Packages: []string{"./old/..."}, Test: config.TestConfig{Args: []string{"-short"}}, Staticcheck: config.StaticcheckConfig{Checks: []string{"all", "-SA1019"}}},
+		},
+	}
+	e, fr := overridesEngine(t, cfg, map[string]*runner.Result{
+		"go test": {Stdout: passingTestJSON()},
+	})
+
+	res, err := e.Check(context.Background(), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantTests := []string{"go test -json .", "go test -json ./internal/pb/a", "go test -json ./old -short"}
+	if got := fr.commands("go test"); !slices.Equal(got, wantTests) {
+		t.Errorf("go test commands = %q, want %q", got, wantTests)
+	}
+	wantStaticcheck := []string{"staticcheck -f json -checks all .", "staticcheck -f json -checks all,-SA1019 ./old"}
+	if got := fr.commands("staticcheck"); !slices.Equal(got, wantStaticcheck) {
+		t.Errorf("staticcheck commands = %q, want %q", got, wantStaticcheck)
+	}
+	if res.FailedIdx != -1 || len(res.Steps) != 2 {
+		t.Errorf("steps = %+v, want test and staticcheck passing", res.Steps)
+	}
+	if got := len(res.RunResult.Tests); got != 3 {
+		t.Errorf("test packages = %d, want the results of 3 runs", got)
+	}
+}
+
+func TestCheck_OverrideAddsStep(t *testing.T) {
+	cfg := &config.Config{
+		Check: config.CheckConfig{Steps: []string{"test"}},
+		Overrides: []config.Override{
+			{Packages: []string{"old"}, Check: config.CheckConfig{Steps: []string{"test", "staticcheck"}}},
+		},
+	}
+	e, fr := overridesEngine(t, cfg, map[string]*runner.Result{})
+
+	res, err := e.Check(context.Background(), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var steps []string
+	for _, s := range res.Steps {
+		steps = append(steps, s.Name)
+	}
+	if !slices.Equal(steps, []string{"test", "staticcheck"}) {
+		t.Errorf("steps = %q, want test, staticcheck", steps)
+	}
+	if got := fr.commands("staticcheck"); !slices.Equal(got, []string{"staticcheck -f json ./old"}) {
+		t.Errorf("staticcheck commands = %q, want only ./old", got)
+	}
+}
+
+func TestCheck_NoOverrideMatches(t *testing.T) {
+	cfg := &config.Config{
+		Check:     config.CheckConfig{Steps: []string{"test"}},
+		Overrides: []config.Override{{Packages: []string{"vendor/..."}, Test: config.TestConfig{Args: []string{"-short"}}}},
+	}
+	e, fr := overridesEngine(t, cfg, map[string]*runner.Result{})
+	if _, err := e.Check(context.Background(), []string{"./..."}, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := fr.commands("go test"); !slices.Equal(got, []string{"go test -json ./..."}) {
+		t.Errorf("go test commands = %q, want the packages as given", got)
+	}
+}
+
+func TestAudit_Overrides(t *testing.T) {
+	cfg := &config.Config{
+		Audit: config.AuditConfig{Steps: []string{"complexity", "dupl"}},
+		Overrides: []config.Override{{
+			Packages: []string{"internal/pb/*"},
+			Audit: config.AuditConfig{
+				Complexity: config.ComplexityConfig{Threshold: 40},
+				Dupl:       config.DuplConfig{Threshold: 200},
+			},
+		}},
+	}
+	gocognit := "20 m Main main.go:5:1\n30 a Gen internal/pb/a/a.pb.go:3:1\n18 old Legacy old/old.go:9:1\n"
+	dupl := "internal/pb/a/a.pb.go:10-40\ninternal/pb/a/b.pb.go:10-40\n\nold/old.go:1-20\nold/x.go:1-20\n\n"
+	e, fr := overridesEngine(t, cfg, map[string]*runner.Result{
+		"gocognit": {Stdout: []byte(gocognit)},
+		"dupl":     {Stdout: []byte(dupl)},
+	})
+
+	res, err := e.Audit(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := res.RunResult
+
+	if len(rr.Complexity) != 3 {
+		t.Fatalf("complexity = %+v, want each function once", rr.Complexity)
+	}
+	for _, c := range rr.Complexity {
+		wantThreshold, wantOver := 0, true
+		if c.Function == "Gen" {
+			wantThreshold, wantOver = 40, false
+		}
+		if c.Threshold != wantThreshold || c.Over(15) != wantOver {
+			t.Errorf("%s: threshold %d, over %v; want %d, %v", c.Function, c.Threshold, c.Over(15), wantThreshold, wantOver)
+		}
+	}
+
+	want := []string{"dupl -plumbing -t 50 .", "dupl -plumbing -t 200 ."}
+	if got := fr.commands("dupl"); !slices.Equal(got, want) {
+		t.Errorf("dupl commands = %q, want %q", got, want)
+	}
+	if len(rr.Duplicates) != 2 {
+		t.Errorf("duplicates = %+v, want one per group", rr.Duplicates)
+	}
+}
@@ -1,8 +1,11 @@
 package workflow
 
 import (
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/deixis/governor/internal/report"
 )
 
 // --- parseTestOutput ---
@@ -338,3 +341,134 @@ func TestTruncateLines(t *testing.T) {
 func lines(ss ...string) string {
 	return strings.Join(ss, "\n")
 }
+
+func TestParseTestOutput_Packages(t *testing.T) {
+	input := lines(
+		`{"Action":"start","Package":"pkg/a"}`,
+		`{"Action":"run","Package":"pkg/a","Test":"TestA"}`,
+		`{"Action":"output","Package":"pkg/a","Test":"TestA","Output":"=== RUN   TestA\n"}`,
+		`{"Action":"output","Package":"pkg/a","Test":"TestA","Output":"    a_test.go:9: boom\n"}`,
+		`{"Action":"fail","Package":"pkg/a","Test":"TestA","Elapsed":0.25}`,
+		`{"Action":"run","Package":"pkg/a","Test":"TestB"}`,
+		`{"Action":"output","Package":"pkg/a","Test":"TestB","Output":"    a_test.go:14: needs network\n"}`,
+		`{"Action":"skip","Package":"pkg/a","Test":"TestB"}`,
+		`{"Action":"output","Package":"pkg/a","Output":"FAIL\n"}`,
+		`{"Action":"fail","Package":"pkg/a","Elapsed":0.5}`,
+		`{"ImportPath":"pkg/b [pkg/b.test]","Action":"build-output","Output":"./b.go:5: syntax error\n"}`,
+		`{"ImportPath":"pkg/b [pkg/b.test]","Action":"build-fail"}`,
+		`{"Action":"start","Package":"pkg/b"}`,
+		`{"Action":"fail","Package":"pkg/b","Elapsed":0,"FailedBuild":"pkg/b [pkg/b.test]"}`,
+		`{"ImportPath":"pkg/c","Action":"build-output","Output":"# pkg/c\n"}`,
+		`{"ImportPath":"pkg/c","Action":"build-fail"}`,
+	)
+	s := parseTestOutput([]byte(input))
+	if len(s.Packages) != 3 {
+		t.Fatalf("len(Packages) = %d, want 3", len(s.Packages))
+	}
+
+	a := s.Packages[0]
+	if a.Package != "pkg/a" || a.Status != "fail" || a.Elapsed != 0.5 {
+		t.Errorf("pkg/a = %+v", a)
+	}
+	if len(a.Cases) != 2 {
+		t.Fatalf("pkg/a cases = %d, want 2", len(a.Cases))
+	}
+	if c := a.Cases[0]; c.Name != "TestA" || c.Status != "fail" || c.Elapsed != 0.25 || !strings.Contains(c.Output, "boom") {
+		t.Errorf("TestA = %+v", c)
+	}
+	if c := a.Cases[1]; c.Status != "skip" || !strings.Contains(c.Output, "needs network") {
+		t.Errorf("TestB = %+v", c)
+	}
+
+	b := s.Packages[1]
+	if b.Status != "error" || !strings.Contains(b.Output, "syntax error") {
+		t.Errorf("pkg/b = %+v, want build error with output", b)
+	}
+
+	c := s.Packages[2]
+	if c.Package != "pkg/c" || c.Status != "error" {
+		t.Errorf("pkg/c = %+v, want build error reported as a package", c)
+	}
+}
+
+func TestSourceLocation(t *testing.T) {
+	tests := []struct {
+		output    string
+		file      string
+		line, col int
+	}{
+		{"=== RUN   TestA\n    a_test.go:9: expected 4, got 5\n", "a_test.go", 9, 0},
+		{"# example.com/pkg\n./main.go:10:2: undefined: foo\n", "./main.go", 10, 2},
+		{"panic: boom\n", "", 0, 0},
+	}
+	for _, tt := range tests {
+		file, line, col := sourceLocation(tt.output)
+		if file != tt.file || line != tt.line || col != tt.col {
+			t.Errorf("sourceLocation(%q) = %s:%d:%d, want %s:%d:%d", tt.output, file, line, col, tt.file, tt.line, tt.col)
+		}
+	}
+}
+
+// --- parseCoverProfile ---
+
+func TestParseCoverProfile(t *testing.T) {
+	input := lines(
+		"mode: set",
+		"example.com/m/b.go:3.10,5.2 2 1",
+		"example.com/m/a.go:3.10,5.2 2 1",
+		"example.com/m/a.go:7.10,9.2 1 0",
+		"example.com/m/a.go:9.2,12.3 3 0",
+		"example.com/m/a.go:20.1,21.2 1 0",
+		// The same block reported by a second test binary that ran it.
+		"example.com/m/a.go:20.1,21.2 1 1",
+		"garbage",
+	)
+	files := parseCoverProfile([]byte(input))
+	if len(files) != 2 {
+		t.Fatalf("files = %d, want 2", len(files))
+	}
+
+	a := files[0]
+	if a.File != "example.com/m/a.go" {
+		t.Fatalf("files[0].File = %q, want example.com/m/a.go", a.File)
+	}
+	if a.Statements != 7 || a.Covered != 3 {
+		t.Errorf("a.go statements = %d/%d, want 3/7", a.Covered, a.Statements)
+	}
+	want := []report.LineRange{{Start: 7, End: 12}}
+	if !reflect.DeepEqual(a.Uncovered, want) {
+		t.Errorf("a.go uncovered = %v, want %v", a.Uncovered, want)
+	}
+
+	b := files[1]
+	if b.Statements != 2 || b.Covered != 2 || len(b.Uncovered) != 0 {
+		t.Errorf("b.go = %+v, want fully covered", b)
+	}
+}
+
+// --- parseGovulncheckOutput ---
+
+func TestParseGovulncheckOutput_Traces(t *testing.T) {
+	input := lines(
+		`{"osv":{"id":"GO-2024-0001","summary":"Bad parse"}}`,
+		`{"finding":{"osv":"GO-2024-0001","fixed_version":"v1.2.3","trace":[{"module":"example.com/dep"}]}}`,
+		`{"finding":{"osv":"GO-2024-0001","fixed_version":"v1.2.3","trace":[`+
+			`{"module":"example.com/dep","package":"example.com/dep/parse","function":"Read","receiver":"*Reader","position":{"filename":"parse.go","line":40}},`+
+			`{"module":"example.com/m","package":"example.com/m/cmd","function":"main","position":{"filename":"cmd/main.go","line":12}}]}}`,
+	)
+	vulns := parseGovulncheckOutput([]byte(input))
+	if len(vulns) != 1 {
+		t.Fatalf("vulns = %d, want 1", len(vulns))
+	}
+	v := vulns[0]
+	if len(v.Traces) != 1 {
+		t.Fatalf("traces = %d, want 1 (module-level finding has no calls)", len(v.Traces))
+	}
+	want := []report.TraceFrame{
+		{Module: "example.com/dep", Package: "example.com/dep/parse", Function: "(*Reader).Read", File: "parse.go", Line: 40},
+		{Module: "example.com/m", Package: "example.com/m/cmd", Function: "main", File: "cmd/main.go", Line: 12},
+	}
+	if !reflect.DeepEqual(v.Traces[0], want) {
+		t.Errorf("trace = %+v, want %+v", v.Traces[0], want)
+	}
+}
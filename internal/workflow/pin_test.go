@@ -0,0 +1,173 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/runner"
+)
+
+func pinnedEngine(pin string) (*Engine, *resolvingRunner) {
+	fr := &resolvingRunner{
+		fakeRunner: fakeRunner{Results: map[string]*runner.Result{
+			"go version": {Stdout: []byte("/opt/bin/staticcheck: go1.24.0\n\tmod\thonnef.co/go/tools\tv0.5.1\th1:abc=\n")},
+		}},
+		Tools: map[string][]string{"staticcheck": {"/opt/bin/staticcheck"}},
+	}
+	eng := &Engine{
+		Config: &config.Config{
+			Check: config.CheckConfig{Steps: []string{"staticcheck"}},
+			Tools: map[string]string{"staticcheck": pin},
+		},
+		Runner: fr,
+	}
+	return eng, fr
+}
+
+func TestCheck_PinnedVersionMismatch(t *testing.T) {
+	eng, _ := pinnedEngine("v0.6.1")
+	result, err := eng.Check(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	sr := result.Steps[0]
+	if sr.Status != "unavailable" {
+		t.Fatalf("staticcheck status = %s, want unavailable", sr.Status)
+	}
+	for _, want := range []string{
+		`staticcheck v0.5.1 (/opt/bin/staticcheck) does not match "v0.6.1", pinned in .governor.`,
+		"go get -tool honnef.co/go/tools/cmd/staticcheck@v0.6.1",
+	} {
+		if !strings.Contains(sr.Detail, want) {
+			t.Errorf("detail missing %q:\n%s", want, sr.Detail)
+		}
+	}
+
+	tools := result.RunResult.Tools
+	if len(tools) != 1 || tools[0].Version != "v0.5.1" || tools[0].Constraint != "v0.6.1" {
+		t.Errorf("tools = %+v, want actual version and constraint", tools)
+	}
+}
+
+func TestCheck_PinnedVersionMatch(t *testing.T) {
+	eng, fr := pinnedEngine(">=v0.5.0, <v0.7.0")
+	result, err := eng.Check(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if sr := result.Steps[0]; sr.Status != "pass" {
+		t.Errorf("staticcheck = %+v, want pass", sr)
+	}
+	if _, ok := fr.Options["/opt/bin/staticcheck"]; !ok {
+		t.Error("staticcheck was not run")
+	}
+}
+
+func TestCheck_PinnedVersionUnknown(t *testing.T) {
+	eng, fr := pinnedEngine("v0.6.1")
+	fr.Results["go version"] = &runner.Result{ExitCode: 1}
+	result, err := eng.Check(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if sr := result.Steps[0]; sr.Status != "unavailable" || !strings.Contains(sr.Detail, "cannot be determined") {
+		t.Errorf("staticcheck = %+v, want unavailable with unknown version", sr)
+	}
+}
+
+// countingRunner counts the commands run, by fakeRunnerKey.
+type countingRunner struct {
+	resolvingRunner
+	Calls map[string]int
+}
+
+func (c *countingRunner) Run(ctx context.Context, argv []string, cwd string, opts ...runner.Option) (*runner.Result, error) {
+	if c.Calls == nil {
+		c.Calls = make(map[string]int)
+	}
+	c.Calls[fakeRunnerKey(argv)]++
+	return c.resolvingRunner.Run(ctx, argv, cwd, opts...)
+}
+
+func TestDescribeTool_VersionDetectedOnce(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "dupl")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cr := &countingRunner{resolvingRunner: resolvingRunner{
+		fakeRunner: fakeRunner{Results: map[string]*runner.Result{
+			"go version": {Stdout: []byte("\tmod\tgithub.com/mibk/dupl\tv1.0.0\th1:abc=\n")},
+		}},
+		Tools: map[string][]string{"dupl": {binary}},
+	}}
+	eng := &Engine{
+		Config: &config.Config{Audit: config.AuditConfig{Steps: []string{"dupl"}}},
+		Runner: cr,
+	}
+
+	for i := range 2 {
+		result, err := eng.Audit(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Audit: %v", err)
+		}
+		if tools := result.RunResult.Tools; len(tools) != 1 || tools[0].Version != "v1.0.0" {
+			t.Errorf("run %d tools = %+v", i, tools)
+		}
+	}
+	if n := cr.Calls["go version"]; n != 1 {
+		t.Errorf("go version -m ran %d times, want once", n)
+	}
+
+	// A reinstalled binary is detected again.
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\n# v2\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eng.Audit(context.Background(), nil); err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+	if n := cr.Calls["go version"]; n != 2 {
+		t.Errorf("go version -m ran %d times after reinstall, want twice", n)
+	}
+}
+
+func TestDetectVersion_VersionFlag(t *testing.T) {
+	fr := &fakeRunner{Results: map[string]*runner.Result{
+		"go version":             {Stdout: []byte("\tmod\tgithub.com/golangci/golangci-lint\t(devel)\t\n")},
+		"/opt/bin/golangci-lint": {Stdout: []byte("golangci-lint has version 1.64.8 built with go1.24.1 from 8b37f141 on 2025-03-17T20:41:53Z\n")},
+	}}
+	eng := &Engine{Config: &config.Config{}, Runner: fr}
+	if v := eng.detectVersion(context.Background(), "golangci-lint", "/opt/bin/golangci-lint"); v != "v1.64.8" {
+		t.Errorf("version = %q, want v1.64.8", v)
+	}
+	if v := eng.detectVersion(context.Background(), "dupl", "/opt/bin/dupl"); v != "" {
+		t.Errorf("dupl version = %q, want none", v)
+	}
+}
+
+func TestDoctor_Pinned(t *testing.T) {
+	eng, _ := pinnedEngine("v0.6.1")
+	eng.Config.Tools["gofumpt"] = "latest"
+	eng.Config.Tools["gofmt"] = "v1.0.0"
+	d := eng.Doctor(context.Background())
+
+	var sc ToolStatus
+	for _, tool := range d.Tools {
+		if tool.Name == "staticcheck" {
+			sc = tool
+		}
+	}
+	if sc.Status != ToolMismatch || sc.Pinned != "v0.6.1" || !strings.Contains(sc.Detail, "does not match") {
+		t.Errorf("staticcheck = %+v, want mismatch", sc)
+	}
+	want := []string{
+		"tools.gofmt: unknown tool",
+		`tools.gofumpt: invalid version constraint "latest": "latest" is not a version`,
+	}
+	if strings.Join(d.ConfigProblems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("config problems = %q, want %q", d.ConfigProblems, want)
+	}
+}
@@ -0,0 +1,224 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/deixis/governor/internal/runner"
+)
+
+// EventKind identifies a progress event.
+type EventKind string
+
+const (
+	// EventStepStarted is sent when a step starts.
+	EventStepStarted EventKind = "step_started"
+	// EventStepFinished is sent when a step finishes, with its status.
+	EventStepFinished EventKind = "step_finished"
+	// EventPackageDone is sent by the test step each time a package's
+	// tests complete, with the package status: pass, fail, skip or error.
+	EventPackageDone EventKind = "package_done"
+	// EventWaiting is sent when a command has to wait for others sharing
+	// the workspace before it starts, and with an empty Waiting once it
+	// starts. Step is empty while the run gathers its metadata.
+	EventWaiting EventKind = "waiting"
+)
+
+// Event reports the progress of a check or audit run.
+type Event struct {
+	Kind    EventKind
+	RunID   string
+	Step    string // step the event belongs to
+	Index   int    // position of Step in the run, from 0
+	Steps   int    // number of steps in the run
+	Status  string // step status, or package status for EventPackageDone
+	Package string // EventPackageDone only
+	Waiting string // EventWaiting only: what the command waits for; "" once it starts
+
+	Elapsed     time.Duration // since the run started
+	StepElapsed time.Duration // since the step started
+
+	// Running counts for the run so far. Steps that are unavailable,
+	// errored or interrupted count as failed.
+	StepsPassed   int
+	StepsFailed   int
+	PackagesDone  int
+	PackagesTotal int // packages the run resolved to; 0 if unknown
+	TestsPassed   int
+	TestsFailed   int
+	TestsSkipped  int
+}
+
+// Observer receives progress events. It is called synchronously from the
+// run, so it should return quickly.
+type Observer func(Event)
+
+type observerKey struct{}
+
+// WithObserver returns a context that reports the progress of check and
+// audit runs started with it to o.
+func WithObserver(ctx context.Context, o Observer) context.Context {
+	return context.WithValue(ctx, observerKey{}, o)
+}
+
+// progress tracks the running state of a run and emits events to an
+// Observer. A nil *progress discards everything.
+type progress struct {
+	mu        sync.Mutex
+	observe   Observer
+	ev        Event // running state; per-event fields are set on emission
+	start     time.Time
+	stepStart time.Time
+}
+
+// newProgress returns the progress tracker for a run, or nil if ctx has no
+// observer.
+func newProgress(ctx context.Context, runID string, start time.Time) *progress {
+	o, _ := ctx.Value(observerKey{}).(Observer)
+	if o == nil {
+		return nil
+	}
+	return &progress{
+		observe: o,
+		start:   start,
+		ev:      Event{RunID: runID},
+	}
+}
+
+// progressFrom returns the progress tracker of the run in ctx, if any.
+func progressFrom(ctx context.Context) *progress {
+	if rs := stateFrom(ctx); rs != nil {
+		return rs.progress
+	}
+	return nil
+}
+
+// resolved records the number of packages the run resolved to.
+func (p *progress) resolved(packages int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.ev.PackagesTotal = packages
+	p.mu.Unlock()
+}
+
+// plan records the number of steps in the run.
+func (p *progress) plan(steps int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.ev.Steps = steps
+	p.mu.Unlock()
+}
+
+func (p *progress) stepStarted(index int, step string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.stepStart = time.Now()
+	p.ev.Index = index
+	p.ev.Step = step
+	p.emit(EventStepStarted, "", "")
+}
+
+func (p *progress) stepFinished(status string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	switch status {
+	case "pass", "done":
+		p.ev.StepsPassed++
+	case "fail", "error", "unavailable", "timeout", "cancelled":
+		p.ev.StepsFailed++
+	}
+	p.emit(EventStepFinished, status, "")
+}
+
+// waiting reports that a command of the running step is queued, or has
+// started after being queued when w is zero.
+func (p *progress) waiting(w runner.Wait) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	if w != (runner.Wait{}) {
+		p.ev.Waiting = w.String()
+	}
+	p.emit(EventWaiting, "", "")
+}
+
+// testEvent records a line of go test -json output.
+func (p *progress) testEvent(line []byte) {
+	if p == nil {
+		return
+	}
+	var ev test2jsonEvent
+	if err := json.Unmarshal(line, &ev); err != nil {
+		return
+	}
+	p.mu.Lock()
+	switch {
+	case ev.Test != "":
+		switch ev.Action {
+		case "pass":
+			p.ev.TestsPassed++
+		case "fail":
+			p.ev.TestsFailed++
+		case "skip":
+			p.ev.TestsSkipped++
+		}
+	case ev.Package != "" && (ev.Action == "pass" || ev.Action == "fail" || ev.Action == "skip"):
+		p.ev.PackagesDone++
+		status := ev.Action
+		if ev.FailedBuild != "" {
+			status = "error"
+		}
+		p.emit(EventPackageDone, status, ev.Package)
+		return
+	}
+	p.mu.Unlock()
+}
+
+// emit sends the current state as an event of kind and unlocks p.mu,
+// which must be held. The observer is called without the lock held.
+func (p *progress) emit(kind EventKind, status, pkg string) {
+	ev := p.ev
+	p.ev.Waiting = "" // only sent once
+	ev.Kind = kind
+	ev.Status = status
+	ev.Package = pkg
+	now := time.Now()
+	ev.Elapsed = now.Sub(p.start)
+	ev.StepElapsed = now.Sub(p.stepStart)
+	p.mu.Unlock()
+	p.observe(ev)
+}
+
+// lineWriter calls fn with each complete line written to it, without the
+// trailing newline.
+type lineWriter struct {
+	buf []byte
+	fn  func([]byte)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	rest := w.buf
+	for {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			break
+		}
+		w.fn(rest[:i])
+		rest = rest[i+1:]
+	}
+	w.buf = append(w.buf[:0], rest...)
+	return len(p), nil
+}
@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/runner"
+)
+
+func TestCheck_Progress(t *testing.T) {
+	fr := &fakeRunner{
+		Results: map[string]*runner.Result{
+			"go test": {ExitCode: 1, Stdout: failingTestJSON()},
+		},
+	}
+	e := &Engine{
+		Config:    &config.Config{Check: config.CheckConfig{Steps: []string{"test", "lint"}}},
+		Runner:    fr,
+		Workspace: "/project",
+		RepoRoot:  "/project",
+	}
+
+	var events []Event
+	ctx := WithObserver(context.Background(), func(ev Event) { events = append(events, ev) })
+	if _, err := e.Check(ctx, nil, false); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	type summary struct {
+		Kind          EventKind
+		Step          string
+		Index, Steps  int
+		Status        string
+		Package       string
+		Passed, Fails int
+		Packages      int
+		TestsFailed   int
+	}
+	var got []summary
+	for _, ev := range events {
+		if ev.RunID == "" {
+			t.Errorf("%s event has no run ID", ev.Kind)
+		}
+		got = append(got, summary{ev.Kind, ev.Step, ev.Index, ev.Steps, ev.Status, ev.Package, ev.StepsPassed, ev.StepsFailed, ev.PackagesDone, ev.TestsFailed})
+	}
+	// Check fails fast, so lint never starts.
+	want := []summary{
+		{EventStepStarted, "format", 0, 3, "", "", 0, 0, 0, 0},
+		{EventStepFinished, "format", 0, 3, "pass", "", 1, 0, 0, 0},
+		{EventStepStarted, "test", 1, 3, "", "", 1, 0, 0, 0},
+		{EventPackageDone, "test", 1, 3, "fail", "example.com/foo", 1, 0, 1, 1},
+		{EventStepFinished, "test", 1, 3, "fail", "", 1, 1, 1, 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestCheck_ProgressWaiting(t *testing.T) {
+	fr := &fakeRunner{
+		Results: map[string]*runner.Result{
+			"go test": {Stdout: passingTestJSON()},
+		},
+		Waits: map[string][]runner.Wait{
+			"go test": {{Ahead: 2}, {OtherProcess: true}, {}},
+		},
+	}
+	e := &Engine{
+		Config:    &config.Config{Check: config.CheckConfig{Steps: []string{"test"}}},
+		Runner:    fr,
+		Workspace: "/project",
+		RepoRoot:  "/project",
+	}
+
+	var waiting []string
+	ctx := WithObserver(context.Background(), func(ev Event) {
+		if ev.Kind == EventWaiting {
+			waiting = append(waiting, ev.Step+": "+ev.Waiting)
+		} else if ev.Waiting != "" {
+			t.Errorf("%s event has Waiting %q", ev.Kind, ev.Waiting)
+		}
+	})
+	if _, err := e.Check(ctx, nil, false); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	want := []string{"test: 2 commands ahead", "test: another process is using the workspace", "test: "}
+	if !reflect.DeepEqual(waiting, want) {
+		t.Errorf("waiting events = %q, want %q", waiting, want)
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	w := &lineWriter{fn: func(b []byte) { lines = append(lines, string(b)) }}
+	for _, chunk := range []string{"a", "b\nc\n", "", "\nd"} {
+		if n, err := w.Write([]byte(chunk)); n != len(chunk) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+		}
+	}
+	want := []string{"ab", "c", ""}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+}
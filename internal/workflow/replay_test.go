@@ -0,0 +1,102 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/replay"
+	"github.com/deixis/governor/internal/runner"
+)
+
+// replayEngine returns an engine for a copy of the module in
+// testdata/replay/<module>, serving the commands recorded in
+// testdata/replay/<fixture>.json. Run the tests with GOVERNOR_RECORD=1 to
+// record the fixtures again with the tools installed.
+func replayEngine(t *testing.T, module, fixture string, cfg *config.Config) *Engine {
+	t.Helper()
+	dir := t.TempDir()
+	src := filepath.Join("testdata", "replay", module)
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		t.Fatalf("reading module %s: %v", module, err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(src, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, strings.TrimSuffix(e.Name(), ".txt")), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := &runner.Runner{Workspace: dir, Timeout: time.Minute, MaxOutput: config.DefaultMaxOutput}
+	return &Engine{
+		Config:    cfg,
+		Runner:    replay.ForTest(t, filepath.Join("testdata", "replay", fixture+".json"), dir, r, ResolveTool),
+		Workspace: dir,
+		RepoRoot:  dir,
+	}
+}
+
+func TestCheck_Replay(t *testing.T) {
+	cfg := &config.Config{Check: config.CheckConfig{Steps: []string{"test", "lint", "staticcheck"}}}
+	e := replayEngine(t, "calc", "check", cfg)
+
+	result, err := e.Check(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	rr := result.RunResult
+	if rr.Module != "example.com/calc" || len(rr.ResolvedPackages) != 1 {
+		t.Errorf("module = %q, packages = %q", rr.Module, rr.ResolvedPackages)
+	}
+	var got []string
+	for _, s := range rr.Steps {
+		got = append(got, s.Name+":"+s.Status)
+	}
+	if want := "format:pass test:pass lint:fail staticcheck:skipped"; strings.Join(got, " ") != want {
+		t.Errorf("steps = %s, want %s", strings.Join(got, " "), want)
+	}
+	if len(rr.Tests) != 1 || rr.Tests[0].Status != "pass" {
+		t.Errorf("tests = %+v, want one passing test", rr.Tests)
+	}
+	if len(rr.LintIssues) != 1 || rr.LintIssues[0].Linter != "errcheck" || rr.LintIssues[0].File != "calc.go" {
+		t.Errorf("lint issues = %+v, want the unchecked os.Remove", rr.LintIssues)
+	}
+	if len(rr.Tools) != 1 || rr.Tools[0].Name != "golangci-lint" {
+		t.Errorf("tools = %+v, want golangci-lint", rr.Tools)
+	}
+}
+
+func TestAudit_Replay(t *testing.T) {
+	cfg := &config.Config{Audit: config.AuditConfig{Steps: []string{"coverage", "complexity", "deadcode"}}}
+	e := replayEngine(t, "calc", "audit", cfg)
+
+	result, err := e.Audit(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+	rr := result.RunResult
+	if rr.Status != "done" {
+		t.Errorf("status = %s, steps = %+v", rr.Status, rr.Steps)
+	}
+	coverage := make(map[string]float64)
+	for _, c := range rr.Coverage {
+		coverage[c.Function] = c.Coverage
+	}
+	if coverage["Add"] != 100 || coverage["Sign"] != 0 {
+		t.Errorf("coverage = %v, want Add covered and Sign not", coverage)
+	}
+	if len(rr.Complexity) != 1 || rr.Complexity[0].Function != "Sign" {
+		t.Errorf("complexity = %+v, want Sign", rr.Complexity)
+	}
+	if len(rr.DeadFuncs) != 1 || rr.DeadFuncs[0].Function != "unused" {
+		t.Errorf("dead functions = %+v, want unused", rr.DeadFuncs)
+	}
+}
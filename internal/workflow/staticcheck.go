@@ -32,9 +32,9 @@ func (s *StaticcheckResult) String() string {
 }
 
 func (e *Engine) runStaticcheck(ctx context.Context, packages []string) (*StaticcheckResult, error) {
-	argv := ResolveTool("staticcheck")
-	if argv == nil {
-		return nil, NewErrToolUnavailable("staticcheck")
+	argv, err := e.requireTool(ctx, "staticcheck")
+	if err != nil {
+		return nil, err
 	}
 
 	argv = append(argv, "-f", "json")
@@ -45,7 +45,7 @@ func (e *Engine) runStaticcheck(ctx context.Context, packages []string) (*Static
 	argv = append(argv, e.Config.Staticcheck.Args...)
 	argv = append(argv, packages...)
 
-	result, err := e.Runner.Run(ctx, argv, "")
+	result, err := e.run(ctx, argv, "")
 	if err != nil {
 		return nil, fmt.Errorf("executing staticcheck: %w", err)
 	}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+
+	"github.com/deixis/governor/internal/report"
+	"github.com/deixis/governor/internal/runner"
 )
 
 // TestSummary holds parsed test results.
@@ -16,6 +20,7 @@ type TestSummary struct {
 	Skipped     int
 	BuildErrors []BuildError
 	Errors      []TestFailure
+	Packages    []report.TestPackage // per-package results, in the order packages started
 }
 
 // BuildError holds a build failure from go test -json.
@@ -96,8 +101,18 @@ func (e *Engine) runTest(ctx context.Context, packages []string) (*TestSummary,
 	argv = append(argv, pkgs...)
 	argv = append(argv, e.Config.Test.Args...)
 
-	result, err := e.Runner.Run(ctx, argv, "")
+	// Report packages as they complete when the run is observed.
+	var opts []runner.Option
+	if prog := progressFrom(ctx); prog != nil {
+		opts = append(opts, runner.WithStdout(&lineWriter{fn: prog.testEvent}))
+	}
+
+	result, err := e.run(ctx, argv, "", opts...)
 	if err != nil {
+		if result != nil {
+			// Interrupted: keep the packages that completed.
+			return parseTestOutput(result.Stdout), err
+		}
 		return nil, fmt.Errorf("executing go test: %w", err)
 	}
 
@@ -107,12 +122,13 @@ func (e *Engine) runTest(ctx context.Context, packages []string) (*TestSummary,
 
 // test2jsonEvent represents a single event from `go test -json`.
 type test2jsonEvent struct {
-	Action     string  `json:"Action"`
-	Package    string  `json:"Package"`
-	Test       string  `json:"Test"`
-	Output     string  `json:"Output"`
-	Elapsed    float64 `json:"Elapsed"`
-	ImportPath string  `json:"ImportPath"`
+	Action      string  `json:"Action"`
+	Package     string  `json:"Package"`
+	Test        string  `json:"Test"`
+	Output      string  `json:"Output"`
+	Elapsed     float64 `json:"Elapsed"`
+	ImportPath  string  `json:"ImportPath"`
+	FailedBuild string  `json:"FailedBuild"`
 }
 
 func parseTestOutput(data []byte) *TestSummary {
@@ -125,6 +141,8 @@ func parseTestOutput(data []byte) *TestSummary {
 	buildOutputs := make(map[string]*strings.Builder)
 	failedBuilds := make(map[string]bool)
 
+	var tr testRecorder
+
 	lines := strings.Split(string(data), "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -137,6 +155,7 @@ func parseTestOutput(data []byte) *TestSummary {
 		}
 
 		key := testKey{ev.Package, ev.Test}
+		tr.record(ev)
 
 		switch ev.Action {
 		case "output":
@@ -211,9 +230,114 @@ func parseTestOutput(data []byte) *TestSummary {
 		})
 	}
 
+	s.Packages = tr.packages(buildOutputs, failedBuilds)
 	return s
 }
 
+// testRecorder collects per-package and per-test results from a go test
+// -json stream, preserving the order in which they appear.
+type testRecorder struct {
+	pkgs  []report.TestPackage
+	index map[string]int            // package -> index in pkgs
+	cases map[string]map[string]int // package -> test -> index in Cases
+	built map[string]string         // package -> import path whose build failed
+}
+
+func (tr *testRecorder) pkg(name string) *report.TestPackage {
+	if tr.index == nil {
+		tr.index = make(map[string]int)
+		tr.cases = make(map[string]map[string]int)
+		tr.built = make(map[string]string)
+	}
+	i, ok := tr.index[name]
+	if !ok {
+		i = len(tr.pkgs)
+		tr.index[name] = i
+		tr.cases[name] = make(map[string]int)
+		tr.pkgs = append(tr.pkgs, report.TestPackage{Package: name})
+	}
+	return &tr.pkgs[i]
+}
+
+func (tr *testRecorder) testCase(pkg *report.TestPackage, name string) *report.TestCase {
+	idx := tr.cases[pkg.Package]
+	i, ok := idx[name]
+	if !ok {
+		i = len(pkg.Cases)
+		idx[name] = i
+		pkg.Cases = append(pkg.Cases, report.TestCase{Name: name})
+	}
+	return &pkg.Cases[i]
+}
+
+func (tr *testRecorder) record(ev test2jsonEvent) {
+	if ev.Package == "" {
+		return // build events carry ImportPath only
+	}
+	p := tr.pkg(ev.Package)
+
+	switch ev.Action {
+	case "run":
+		if ev.Test != "" {
+			tr.testCase(p, ev.Test)
+		}
+	case "output":
+		if ev.Test != "" {
+			tc := tr.testCase(p, ev.Test)
+			tc.Output += ev.Output
+		} else {
+			p.Output += ev.Output
+		}
+	case "pass", "fail", "skip":
+		if ev.Test != "" {
+			tc := tr.testCase(p, ev.Test)
+			tc.Status = ev.Action
+			tc.Elapsed = ev.Elapsed
+			return
+		}
+		p.Status = ev.Action
+		p.Elapsed = ev.Elapsed
+		if ev.FailedBuild != "" {
+			p.Status = "error"
+			tr.built[ev.Package] = ev.FailedBuild
+		}
+	}
+}
+
+// packages returns the recorded packages. Packages whose build failed get
+// the build output; failed builds that never reached the test phase are
+// reported as packages of their own.
+func (tr *testRecorder) packages(buildOutputs map[string]*strings.Builder, failedBuilds map[string]bool) []report.TestPackage {
+	attached := make(map[string]bool)
+	for i := range tr.pkgs {
+		p := &tr.pkgs[i]
+		ip, ok := tr.built[p.Package]
+		if !ok {
+			continue
+		}
+		attached[ip] = true
+		if b, ok := buildOutputs[ip]; ok {
+			p.Output = b.String() + p.Output
+		}
+	}
+
+	var orphans []string
+	for ip := range failedBuilds {
+		if !attached[ip] {
+			orphans = append(orphans, ip)
+		}
+	}
+	sort.Strings(orphans)
+	for _, ip := range orphans {
+		p := report.TestPackage{Package: ip, Status: "error"}
+		if b, ok := buildOutputs[ip]; ok {
+			p.Output = b.String()
+		}
+		tr.pkgs = append(tr.pkgs, p)
+	}
+	return tr.pkgs
+}
+
 func truncateLines(s string, maxLines int) string {
 	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
 	if len(lines) <= maxLines {
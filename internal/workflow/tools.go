@@ -0,0 +1,184 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+)
+
+// ToolCacheFile is the name of the tool resolution cache in the run history
+// directory.
+const ToolCacheFile = "tools.json"
+
+// ToolCache resolves tools like ResolveTool and remembers the results, so
+// that a workspace is probed once rather than on every step. Results are
+// kept per module root, keyed by the size and modification time of go.mod
+// and go.sum and by PATH, in a file shared by governor processes. Tools
+// that are not found are looked up again every time, so that a tool
+// installed later is picked up.
+type ToolCache struct {
+	file string // "" keeps the cache in memory only
+
+	mu      sync.Mutex
+	entries map[string]*toolCacheEntry // by module root; nil until loaded
+}
+
+type toolCacheEntry struct {
+	Key   toolCacheKey        `json:"key"`
+	Tools map[string][]string `json:"tools"` // argv prefix by tool name
+}
+
+// toolCacheKey captures what tool resolution depends on.
+type toolCacheKey struct {
+	GoMod string `json:"go_mod"` // size and modification time
+	GoSum string `json:"go_sum"`
+	Path  string `json:"path"`
+}
+
+// NewToolCache returns a ToolCache persisted to file, such as
+// ToolCacheFile in the run history directory. An empty file keeps it in
+// memory.
+func NewToolCache(file string) *ToolCache {
+	return &ToolCache{file: file}
+}
+
+// Resolve returns the argv prefix for invoking a named tool in the module
+// at root, or nil if it is not installed.
+func (c *ToolCache) Resolve(root, name string) []string {
+	key := newToolCacheKey(root)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = c.load()
+	}
+	entry := c.entries[root]
+	if entry == nil || entry.Key != key {
+		entry = &toolCacheEntry{Key: key, Tools: make(map[string][]string)}
+		c.entries[root] = entry
+	}
+	if argv, ok := entry.Tools[name]; ok && len(argv) > 0 {
+		// The binary may have been uninstalled since.
+		if _, err := os.Stat(argv[0]); err == nil {
+			return argv
+		}
+	}
+
+	argv := resolveIn(root, name)
+	if argv == nil {
+		delete(entry.Tools, name)
+		return nil
+	}
+	entry.Tools[name] = argv
+	// The cache only saves time: failing to write it is not an error.
+	_ = c.save()
+	return argv
+}
+
+// Refresh forgets every resolution, so that tools are looked up again.
+func (c *ToolCache) Refresh() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*toolCacheEntry)
+	if c.file == "" {
+		return nil
+	}
+	if err := os.Remove(c.file); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing tool cache: %w", err)
+	}
+	return nil
+}
+
+// load reads the cache file. A missing or unreadable file is empty.
+func (c *ToolCache) load() map[string]*toolCacheEntry {
+	entries := make(map[string]*toolCacheEntry)
+	if c.file == "" {
+		return entries
+	}
+	data, err := os.ReadFile(c.file)
+	if err != nil {
+		return entries
+	}
+	if err := json.Unmarshal(data, &entries); err != nil || entries == nil {
+		return make(map[string]*toolCacheEntry)
+	}
+	return entries
+}
+
+// save writes the cache file atomically, so that concurrent processes
+// never read a partial file.
+func (c *ToolCache) save() error {
+	if c.file == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.file), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.file), ToolCacheFile+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.file)
+}
+
+func newToolCacheKey(root string) toolCacheKey {
+	return toolCacheKey{
+		GoMod: fileStamp(filepath.Join(root, "go.mod")),
+		GoSum: fileStamp(filepath.Join(root, "go.sum")),
+		Path:  os.Getenv("PATH"),
+	}
+}
+
+// fileStamp identifies a version of the file at path by its size and
+// modification time, or is empty if it does not exist.
+func fileStamp(path string) string {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d@%d", fi.Size(), fi.ModTime().UnixNano())
+}
+
+// resolveIn returns the argv prefix for invoking a named tool in the module
+// at root: "go tool <name>" when a tool directive of its go.mod declares
+// it, or else the binary found on PATH. It returns nil if the tool is not
+// installed.
+func resolveIn(root, name string) []string {
+	if root != "" {
+		if m, err := readGoMod(filepath.Join(root, "go.mod")); err == nil && m.HasTool(name) {
+			if goPath, err := exec.LookPath("go"); err == nil {
+				return []string{goPath, "tool", name}
+			}
+		}
+	}
+	if toolPath, err := exec.LookPath(name); err == nil {
+		return []string{toolPath}
+	}
+	return nil
+}
+
+// RefreshTools forgets the tools resolved and the versions detected so
+// far, so that they are looked up again.
+func (e *Engine) RefreshTools() error {
+	toolVersions.Clear()
+	if e.Tools == nil {
+		return nil
+	}
+	return e.Tools.Refresh()
+}
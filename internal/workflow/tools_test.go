@@ -0,0 +1,119 @@
+package workflow
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// toolWorkspace returns a module declaring staticcheck as a tool and a
+// PATH containing the go command and a dupl binary.
+func toolWorkspace(t *testing.T) (root, bin string) {
+	t.Helper()
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not on PATH")
+	}
+	root, bin = t.TempDir(), t.TempDir()
+	gomod := "module example.com/foo\n\ngo 1.25.1\n\ntool honnef.co/go/tools/cmd/staticcheck\n"
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(gomod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bin, "dupl"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+filepath.Dir(goPath))
+	return root, bin
+}
+
+func TestToolCache_Resolve(t *testing.T) {
+	root, bin := toolWorkspace(t)
+	goPath, _ := exec.LookPath("go")
+	file := filepath.Join(t.TempDir(), "runs", ToolCacheFile)
+	c := NewToolCache(file)
+
+	if got, want := c.Resolve(root, "staticcheck"), []string{goPath, "tool", "staticcheck"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("staticcheck = %q, want %q", got, want)
+	}
+	dupl := filepath.Join(bin, "dupl")
+	if got := c.Resolve(root, "dupl"); !reflect.DeepEqual(got, []string{dupl}) {
+		t.Errorf("dupl = %q, want PATH binary", got)
+	}
+	if got := c.Resolve(root, "gocognit"); got != nil {
+		t.Errorf("gocognit = %q, want nil", got)
+	}
+
+	// Another process serves the results cached in the file without
+	// looking the tools up.
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("reading cache: %v", err)
+	}
+	other := filepath.Join(t.TempDir(), "dupl")
+	if err := os.WriteFile(other, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data = bytes.ReplaceAll(data, []byte(dupl), []byte(other))
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c2 := NewToolCache(file)
+	if got := c2.Resolve(root, "dupl"); !reflect.DeepEqual(got, []string{other}) {
+		t.Errorf("cached dupl = %q, want %q", got, other)
+	}
+
+	// Misses are not cached: a tool installed later is found.
+	if err := os.WriteFile(filepath.Join(bin, "gocognit"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := c2.Resolve(root, "gocognit"); !reflect.DeepEqual(got, []string{filepath.Join(bin, "gocognit")}) {
+		t.Errorf("gocognit after install = %q", got)
+	}
+
+	// Refresh looks tools up again.
+	if err := c2.Refresh(); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("cache file not removed: %v", err)
+	}
+	if got := c2.Resolve(root, "dupl"); !reflect.DeepEqual(got, []string{dupl}) {
+		t.Errorf("dupl after refresh = %q, want %q", got, dupl)
+	}
+}
+
+func TestToolCache_Invalidation(t *testing.T) {
+	root, bin := toolWorkspace(t)
+	c := NewToolCache("")
+	if got := c.Resolve(root, "staticcheck"); len(got) != 3 {
+		t.Fatalf("staticcheck = %q, want go tool", got)
+	}
+
+	// Removing the tool directive changes go.mod.
+	gomod := filepath.Join(root, "go.mod")
+	if err := os.WriteFile(gomod, []byte("module example.com/foo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(gomod, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Resolve(root, "staticcheck"); got != nil {
+		t.Errorf("staticcheck after go.mod change = %q, want nil", got)
+	}
+
+	// A cached binary that was removed is looked up again.
+	if got := c.Resolve(root, "dupl"); len(got) != 1 {
+		t.Fatalf("dupl = %q", got)
+	}
+	if err := os.Remove(filepath.Join(bin, "dupl")); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Resolve(root, "dupl"); got != nil {
+		t.Errorf("dupl after removal = %q, want nil", got)
+	}
+}
@@ -0,0 +1,87 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/deixis/governor/internal/report"
+	"github.com/deixis/governor/internal/runner"
+)
+
+// recordUsage adds the resources used by a command to the step running in
+// ctx.
+func recordUsage(ctx context.Context, u runner.Usage) {
+	rs, step := stateFrom(ctx), stepFrom(ctx)
+	if rs == nil || step == "" {
+		return
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	total := rs.usage[step]
+	if total == nil {
+		total = &report.Usage{}
+		rs.usage[step] = total
+	}
+	total.Add(report.Usage{
+		Commands: 1,
+		Wall:     u.Wall.Seconds(),
+		User:     u.User.Seconds(),
+		System:   u.System.Seconds(),
+		MaxRSS:   u.MaxRSS,
+	})
+}
+
+// stepUsage returns the resources used by step in the run carried by ctx,
+// or nil if it ran no command.
+func stepUsage(ctx context.Context, step string) *report.Usage {
+	rs := stateFrom(ctx)
+	if rs == nil {
+		return nil
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if u := rs.usage[step]; u != nil {
+		c := *u
+		return &c
+	}
+	return nil
+}
+
+// FormatUsage formats the resources used by each step of rr that ran
+// commands, followed by their total, or "" if none were recorded.
+func FormatUsage(rr *report.RunResult) string {
+	total := rr.Usage()
+	if total == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %-15s %8s %8s %8s %8s %9s\n", "step", "commands", "wall", "user", "system", "max rss")
+	row := func(name string, u *report.Usage) {
+		fmt.Fprintf(&b, "  %-15s %8d %8s %8s %8s %9s\n", name, u.Commands,
+			formatElapsed(u.Wall), formatElapsed(u.User), formatElapsed(u.System), formatBytes(u.MaxRSS))
+	}
+	for _, s := range rr.Steps {
+		if s.Usage != nil {
+			row(s.Name, s.Usage)
+		}
+	}
+	row("total", total)
+	return b.String()
+}
+
+// formatBytes formats a size in bytes with a binary unit, or "-" if it is
+// unknown.
+func formatBytes(n int64) string {
+	switch {
+	case n <= 0:
+		return "-"
+	case n < 1<<10:
+		return fmt.Sprintf("%d B", n)
+	case n < 1<<20:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	case n < 1<<30:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	}
+	return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+}
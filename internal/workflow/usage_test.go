@@ -0,0 +1,62 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deixis/governor/internal/config"
+	"github.com/deixis/governor/internal/report"
+	"github.com/deixis/governor/internal/runner"
+)
+
+func TestCheck_StepUsage(t *testing.T) {
+	fr := &fakeRunner{
+		Results: map[string]*runner.Result{
+			"go test": {Stdout: passingTestJSON(), Usage: runner.Usage{
+				Wall: 2 * time.Second, User: 3 * time.Second, System: time.Second, MaxRSS: 64 << 20,
+			}},
+		},
+	}
+	e := &Engine{
+		Config:    &config.Config{Check: config.CheckConfig{Steps: []string{"test"}}},
+		Runner:    fr,
+		Workspace: "/project",
+		RepoRoot:  "/project",
+	}
+
+	result, err := e.Check(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	var test *report.StepRecord
+	for i, s := range result.RunResult.Steps {
+		if s.Name == "test" {
+			test = &result.RunResult.Steps[i]
+		}
+	}
+	if test == nil || test.Usage == nil {
+		t.Fatalf("test step has no usage: %+v", result.RunResult.Steps)
+	}
+	want := report.Usage{Commands: 1, Wall: 2, User: 3, System: 1, MaxRSS: 64 << 20}
+	if *test.Usage != want {
+		t.Errorf("test usage = %+v, want %+v", *test.Usage, want)
+	}
+}
+
+func TestFormatUsage(t *testing.T) {
+	rr := &report.RunResult{Steps: []report.StepRecord{
+		{Name: "format"},
+		{Name: "test", Usage: &report.Usage{Commands: 1, Wall: 2, User: 3, System: 0.5, MaxRSS: 64 << 20}},
+	}}
+	want := "" +
+		"  step            commands     wall     user   system   max rss\n" +
+		"  test                   1       2s       3s    500ms  64.0 MiB\n" +
+		"  total                  1       2s       3s    500ms  64.0 MiB\n"
+	if got := FormatUsage(rr); got != want {
+		t.Errorf("FormatUsage =\n%s\nwant\n%s", got, want)
+	}
+	if got := FormatUsage(&report.RunResult{}); got != "" {
+		t.Errorf("FormatUsage without usage = %q, want empty", got)
+	}
+}
@@ -0,0 +1,128 @@
+package workflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// compareVersions compares two semantic versions such as "v1.2.3",
+// returning -1, 0 or +1. The "v" prefix is optional and missing minor or
+// patch numbers count as zero. A pre-release sorts before its release;
+// pre-releases and build metadata are otherwise ignored. ok is false if
+// either version cannot be parsed.
+func compareVersions(a, b string) (cmp int, ok bool) {
+	va, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+	vb, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+	for i := range va.nums {
+		switch {
+		case va.nums[i] < vb.nums[i]:
+			return -1, true
+		case va.nums[i] > vb.nums[i]:
+			return 1, true
+		}
+	}
+	switch {
+	case va.pre && !vb.pre:
+		return -1, true
+	case !va.pre && vb.pre:
+		return 1, true
+	}
+	return 0, true
+}
+
+type version struct {
+	nums [3]int
+	pre  bool // has a pre-release suffix, as in v1.2.3-rc.1
+}
+
+func parseVersion(s string) (version, bool) {
+	var v version
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		s, v.pre = s[:i], true
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.nums[i] = n
+	}
+	return v, true
+}
+
+// matchVersion reports whether version satisfies constraint: an exact
+// version such as "v0.6.1", or comma-separated comparisons using =, !=,
+// <, <=, > or >=, all of which must hold, as in ">=v1.60.0, <v2.0.0".
+func matchVersion(version, constraint string) (bool, error) {
+	if err := validConstraint(constraint); err != nil {
+		return false, err
+	}
+	for _, term := range strings.Split(constraint, ",") {
+		op, want := splitConstraint(term)
+		cmp, ok := compareVersions(version, want)
+		if !ok {
+			return false, nil
+		}
+		var holds bool
+		switch op {
+		case "=":
+			holds = cmp == 0
+		case "!=":
+			holds = cmp != 0
+		case "<":
+			holds = cmp < 0
+		case "<=":
+			holds = cmp <= 0
+		case ">":
+			holds = cmp > 0
+		case ">=":
+			holds = cmp >= 0
+		}
+		if !holds {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// validConstraint returns an error if constraint cannot be parsed by
+// matchVersion.
+func validConstraint(constraint string) error {
+	if strings.TrimSpace(constraint) == "" {
+		return fmt.Errorf("empty version constraint")
+	}
+	for _, term := range strings.Split(constraint, ",") {
+		_, want := splitConstraint(term)
+		if _, ok := parseVersion(want); !ok {
+			return fmt.Errorf("invalid version constraint %q: %q is not a version", constraint, strings.TrimSpace(term))
+		}
+	}
+	return nil
+}
+
+// splitConstraint splits a term of a constraint into its operator and
+// version. A version alone means "=".
+func splitConstraint(term string) (op, version string) {
+	term = strings.TrimSpace(term)
+	for _, o := range []string{">=", "<=", "!=", ">", "<", "="} {
+		if rest, ok := strings.CutPrefix(term, o); ok {
+			return o, strings.TrimSpace(rest)
+		}
+	}
+	return "=", term
+}
@@ -0,0 +1,56 @@
+package workflow
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+		ok   bool
+	}{
+		{"v1.2.3", "v1.2.3", 0, true},
+		{"v1.2.3", "v1.10.0", -1, true},
+		{"v2.0.0", "v1.99.99", 1, true},
+		{"1.2", "v1.2.0", 0, true},
+		{"v0.16.0-pre.1", "v0.16.0", -1, true},
+		{"v1.0.0+incompatible", "v1.0.0", 0, true},
+		{"(devel)", "v1.0.0", 0, false},
+		{"v1.2.3.4", "v1.0.0", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := compareVersions(tt.a, tt.b)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("compareVersions(%q, %q) = %d, %v; want %d, %v", tt.a, tt.b, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMatchVersion(t *testing.T) {
+	tests := []struct {
+		version, constraint string
+		want                bool
+	}{
+		{"v0.6.1", "v0.6.1", true},
+		{"v0.6.1", "0.6.1", true},
+		{"v0.6.2", "v0.6.1", false},
+		{"v0.6.1", "=v0.6.1", true},
+		{"v1.64.8", ">=v1.60.0, <v2.0.0", true},
+		{"v2.1.0", ">=v1.60.0, <v2.0.0", false},
+		{"v1.59.9", ">=v1.60.0, <v2.0.0", false},
+		{"v1.2.0", "!=v1.2.0", false},
+		{"v1.2.0", "> 1.1, <= 1.2", true},
+		{"(devel)", ">=v1.0.0", false},
+	}
+	for _, tt := range tests {
+		got, err := matchVersion(tt.version, tt.constraint)
+		if err != nil || got != tt.want {
+			t.Errorf("matchVersion(%q, %q) = %v, %v; want %v", tt.version, tt.constraint, got, err, tt.want)
+		}
+	}
+
+	for _, c := range []string{"", "latest", ">=v1.0.0, ~v2"} {
+		if _, err := matchVersion("v1.0.0", c); err == nil {
+			t.Errorf("matchVersion(v1.0.0, %q): expected error", c)
+		}
+	}
+}
@@ -10,16 +10,16 @@ import (
 )
 
 func (e *Engine) runVulncheck(ctx context.Context, packages []string) ([]report.Vuln, error) {
-	argv := ResolveTool("govulncheck")
-	if argv == nil {
-		return nil, NewErrToolUnavailable("govulncheck")
+	argv, err := e.requireTool(ctx, "govulncheck")
+	if err != nil {
+		return nil, err
 	}
 
 	argv = append(argv, "-json")
 	argv = append(argv, e.Config.Audit.Vulncheck.Args...)
 	argv = append(argv, e.ResolvePackages(packages)...)
 
-	result, err := e.Runner.Run(ctx, argv, "")
+	result, err := e.run(ctx, argv, "")
 	if err != nil {
 		return nil, fmt.Errorf("executing govulncheck: %w", err)
 	}
@@ -39,9 +39,16 @@ type govulncheckFinding struct {
 }
 
 type govulncheckTraceEntry struct {
-	Module   string `json:"module,omitempty"`
-	Package  string `json:"package,omitempty"`
-	Function string `json:"function,omitempty"`
+	Module   string               `json:"module,omitempty"`
+	Package  string               `json:"package,omitempty"`
+	Function string               `json:"function,omitempty"`
+	Receiver string               `json:"receiver,omitempty"`
+	Position *govulncheckPosition `json:"position,omitempty"`
+}
+
+type govulncheckPosition struct {
+	Filename string `json:"filename,omitempty"`
+	Line     int    `json:"line,omitempty"`
 }
 
 type govulncheckOSV struct {
@@ -93,6 +100,9 @@ func parseGovulncheckOutput(data []byte) []report.Vuln {
 				v.Symbols = append(v.Symbols, t.Function)
 			}
 		}
+		if trace := callTrace(f.Trace); trace != nil {
+			v.Traces = append(v.Traces, trace)
+		}
 
 		for i := range vulns {
 			if vulns[i].ID == f.OSV {
@@ -104,6 +114,31 @@ func parseGovulncheckOutput(data []byte) []report.Vuln {
 	return vulns
 }
 
+// callTrace converts a govulncheck trace to trace frames. It returns nil
+// for module- and package-level findings, whose traces hold no calls.
+func callTrace(entries []govulncheckTraceEntry) []report.TraceFrame {
+	if len(entries) == 0 || entries[0].Function == "" {
+		return nil
+	}
+	frames := make([]report.TraceFrame, 0, len(entries))
+	for _, t := range entries {
+		fn := t.Function
+		switch {
+		case strings.HasPrefix(t.Receiver, "*"):
+			fn = "(" + t.Receiver + ")." + fn
+		case t.Receiver != "":
+			fn = t.Receiver + "." + fn
+		}
+		frame := report.TraceFrame{Module: t.Module, Package: t.Package, Function: fn}
+		if t.Position != nil {
+			frame.File = t.Position.Filename
+			frame.Line = t.Position.Line
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
 // FormatVulncheckSummary formats vulnerability results for display.
 func FormatVulncheckSummary(vulns []report.Vuln) string {
 	var b strings.Builder